@@ -5,6 +5,11 @@ import (
 	"fmt"
 
 	"github.com/RathodViraj/go-microservice-graphql-grpc/account/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/health"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/middleware"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/resilience"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -14,8 +19,12 @@ type Client struct {
 	Service pb.AccountServiceClient
 }
 
-func NewClient(url string) (*Client, error) {
-	conn, err := grpc.NewClient(url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// NewClient connects to the service at url. opts are added to the default
+// dial options, e.g. to authenticate calls. Connections are plaintext
+// unless opts set transport credentials, such as tlsconfig's.
+func NewClient(url string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DialOption(), tracing.DialOption(), resilience.DialOption()}, opts...)
+	conn, err := grpc.NewClient(url, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create account client: %w", err)
 	}
@@ -28,46 +37,99 @@ func (c *Client) Close() {
 	c.Conn.Close()
 }
 
+// Ping reports whether the account service is serving.
+func (c *Client) Ping(ctx context.Context) error {
+	return health.Conn(c.Conn)(ctx)
+}
+
 func (c *Client) PostAccount(ctx context.Context, name string) (*Account, error) {
 	r, err := c.Service.PostAccount(ctx, &pb.PostAccountRequest{Name: name})
 	if err != nil {
 		return nil, err
 	}
 
-	return &Account{
-		ID:   r.Account.Id,
-		Name: r.Account.Name,
-	}, nil
+	return accountFromProto(r.Account), nil
 }
 
 func (c *Client) GetAccount(ctx context.Context, id string) (*Account, error) {
 	res, err := c.Service.GetAccount(
 		ctx,
 		&pb.GetAccountRequest{Id: id},
+		resilience.Idempotent(),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Account{
-		ID:   res.Account.Id,
-		Name: res.Account.Name,
-	}, nil
+	return accountFromProto(res.Account), nil
 }
 
 func (c *Client) GetAccounts(ctx context.Context, skip, take uint64) ([]Account, error) {
-	res, err := c.Service.GetAccounts(ctx, &pb.GetAccountsRequest{Skip: skip, Take: take})
+	res, err := c.Service.GetAccounts(ctx, &pb.GetAccountsRequest{Skip: skip, Take: take}, resilience.Idempotent())
 	if err != nil {
 		return nil, err
 	}
 
 	accounts := []Account{}
 	for _, a := range res.Accounts {
-		accounts = append(accounts, Account{
-			ID:   a.Id,
-			Name: a.Name,
-		})
+		accounts = append(accounts, *accountFromProto(a))
 	}
 
 	return accounts, nil
 }
+
+// Register creates an account that can log in and returns it with its first tokens.
+func (c *Client) Register(ctx context.Context, name, email, password string) (*Account, *auth.TokenPair, error) {
+	res, err := c.Service.Register(ctx, &pb.RegisterRequest{Name: name, Email: email, Password: password})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return accountFromProto(res.Account), tokensFromProto(res.Tokens), nil
+}
+
+func (c *Client) Login(ctx context.Context, email, password string) (*Account, *auth.TokenPair, error) {
+	res, err := c.Service.Login(ctx, &pb.LoginRequest{Email: email, Password: password})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return accountFromProto(res.Account), tokensFromProto(res.Tokens), nil
+}
+
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*Account, *auth.TokenPair, error) {
+	res, err := c.Service.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return accountFromProto(res.Account), tokensFromProto(res.Tokens), nil
+}
+
+// SetRole changes the role of the account with the given id and returns it.
+func (c *Client) SetRole(ctx context.Context, id string, role auth.Role) (*Account, error) {
+	res, err := c.Service.SetRole(ctx, &pb.SetRoleRequest{Id: id, Role: string(role)})
+	if err != nil {
+		return nil, err
+	}
+
+	return accountFromProto(res.Account), nil
+}
+
+func accountFromProto(a *pb.Account) *Account {
+	return &Account{
+		ID:    a.Id,
+		Name:  a.Name,
+		Email: a.Email,
+		Role:  auth.Role(a.Role),
+	}
+}
+
+func tokensFromProto(t *pb.Tokens) *auth.TokenPair {
+	res := &auth.TokenPair{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+	}
+	res.ExpiresAt.UnmarshalBinary(t.ExpiresAt)
+	return res
+}
@@ -3,6 +3,7 @@ package account
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/RathodViraj/go-microservice-graphql-grpc/account/pb"
 	"go.uber.org/mock/gomock"
@@ -51,7 +52,7 @@ func TestClient_GetAccount_Success(t *testing.T) {
 	mockPB := NewMockAccountServiceClient(ctrl)
 
 	mockPB.EXPECT().
-		GetAccount(gomock.Any(), &pb.GetAccountRequest{Id: "u1"}).
+		GetAccount(gomock.Any(), &pb.GetAccountRequest{Id: "u1"}, gomock.Any()).
 		Return(&pb.GetAccountResponse{
 			Account: &pb.Account{Id: "u1", Name: "Viraj"},
 		}, nil)
@@ -75,7 +76,7 @@ func TestClient_GetAccounts_Success(t *testing.T) {
 	mockPB := NewMockAccountServiceClient(ctrl)
 
 	mockPB.EXPECT().
-		GetAccounts(gomock.Any(), &pb.GetAccountsRequest{Skip: 0, Take: 2}).
+		GetAccounts(gomock.Any(), &pb.GetAccountsRequest{Skip: 0, Take: 2}, gomock.Any()).
 		Return(&pb.GetAccountsResponse{
 			Accounts: []*pb.Account{
 				{Id: "u1", Name: "Viraj"},
@@ -94,3 +95,34 @@ func TestClient_GetAccounts_Success(t *testing.T) {
 		t.Errorf("expected 2 accounts, got %d", len(res))
 	}
 }
+
+func TestClient_Register_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPB := NewMockAccountServiceClient(ctrl)
+	expiresAt := time.Now().Add(time.Hour).UTC()
+	expiresBytes, _ := expiresAt.MarshalBinary()
+
+	mockPB.EXPECT().
+		Register(
+			gomock.Any(),
+			&pb.RegisterRequest{Name: "viraj", Email: "viraj@example.com", Password: "correct horse"},
+		).
+		Return(&pb.RegisterResponse{
+			Account: &pb.Account{Id: "u1", Name: "viraj", Email: "viraj@example.com"},
+			Tokens:  &pb.Tokens{AccessToken: "a", RefreshToken: "r", ExpiresAt: expiresBytes},
+		}, nil)
+
+	c := &Client{Service: mockPB}
+	acc, tokens, err := c.Register(context.Background(), "viraj", "viraj@example.com", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc.ID != "u1" || acc.Email != "viraj@example.com" {
+		t.Errorf("unexpected account: %#v", acc)
+	}
+	if tokens.AccessToken != "a" || tokens.RefreshToken != "r" || !tokens.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("unexpected tokens: %#v", tokens)
+	}
+}
@@ -15,13 +15,13 @@ import (
 
 func StartE2EServer(t *testing.T) (string, func()) {
 	url := os.Getenv("DATABASE_URL_FOR_TEST")
-	if url == "" {
-		t.Fatal("DATABASE_URL_FOR_TEST not set")
-	}
 
-	repo, err := NewPostgresRepository(url)
-	if err != nil {
-		t.Fatalf("failed to connect repo: %v", err)
+	repo := NewMemoryRepository()
+	if url != "" && url != "memory://" {
+		var err error
+		if repo, err = NewPostgresRepository(url); err != nil {
+			t.Fatalf("failed to connect repo: %v", err)
+		}
 	}
 
 	svc := &accountService{repo: repo}
@@ -0,0 +1,124 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+)
+
+var errDuplicateAccount = errors.New("account already exists")
+
+type memoryAccount struct {
+	Account
+	passwordHash string
+}
+
+// memoryRepository keeps accounts in memory, for development and tests
+// without Postgres. Everything is lost when the process exits.
+type memoryRepository struct {
+	mu       sync.RWMutex
+	accounts map[string]*memoryAccount
+	byEmail  map[string]*memoryAccount
+}
+
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		accounts: map[string]*memoryAccount{},
+		byEmail:  map[string]*memoryAccount{},
+	}
+}
+
+func (r *memoryRepository) Close() {}
+
+func (r *memoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryRepository) PutAccount(ctx context.Context, a Account) error {
+	return r.put(Account{ID: a.ID, Name: a.Name, Role: a.Role}, "")
+}
+
+// PutAccountWithPassword stores an account that can log in with a.Email.
+// It fails with ErrEmailTaken if another account has the same email.
+func (r *memoryRepository) PutAccountWithPassword(ctx context.Context, a Account, passwordHash string) error {
+	return r.put(a, passwordHash)
+}
+
+func (r *memoryRepository) put(a Account, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.accounts[a.ID]; ok {
+		return errDuplicateAccount
+	}
+	if _, ok := r.byEmail[a.Email]; ok && a.Email != "" {
+		return ErrEmailTaken
+	}
+
+	stored := &memoryAccount{Account: a, passwordHash: passwordHash}
+	r.accounts[a.ID] = stored
+	if a.Email != "" {
+		r.byEmail[a.Email] = stored
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetAccountByID(ctx context.Context, id string) (*Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.accounts[id]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	return &Account{ID: stored.ID, Name: stored.Name, Role: stored.Role}, nil
+}
+
+// ListAccounts sorts accounts by ID, newest first, like the Postgres
+// repository.
+func (r *memoryRepository) ListAccounts(ctx context.Context, skip, take uint64) ([]Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make([]Account, 0, len(r.accounts))
+	for _, stored := range r.accounts {
+		accounts = append(accounts, Account{ID: stored.ID, Name: stored.Name, Role: stored.Role})
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID > accounts[j].ID })
+
+	if skip >= uint64(len(accounts)) {
+		return []Account{}, nil
+	}
+	accounts = accounts[skip:]
+	if take < uint64(len(accounts)) {
+		accounts = accounts[:take]
+	}
+	return accounts, nil
+}
+
+func (r *memoryRepository) GetPasswordHash(ctx context.Context, email string) (*Account, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.byEmail[email]
+	if !ok {
+		return nil, "", ErrAccountNotFound
+	}
+	a := stored.Account
+	return &a, stored.passwordHash, nil
+}
+
+func (r *memoryRepository) SetRole(ctx context.Context, id string, role auth.Role) (*Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.accounts[id]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	stored.Role = role
+	return &Account{ID: stored.ID, Name: stored.Name, Role: stored.Role}, nil
+}
@@ -0,0 +1,71 @@
+package account
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+)
+
+func TestMemoryRepository_ListAccounts(t *testing.T) {
+	r := NewMemoryRepository()
+	for _, id := range []string{"b", "c", "a"} {
+		if err := r.PutAccount(t.Context(), Account{ID: id, Name: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	accounts, err := r.ListAccounts(t.Context(), 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 || accounts[0].ID != "b" || accounts[1].ID != "a" {
+		t.Errorf("expected accounts by ID descending after the first, got %v", accounts)
+	}
+
+	accounts, err = r.ListAccounts(t.Context(), 3, 5)
+	if err != nil || len(accounts) != 0 {
+		t.Errorf("expected no accounts past the end, got %v: %v", accounts, err)
+	}
+}
+
+func TestMemoryRepository_Credentials(t *testing.T) {
+	r := NewMemoryRepository()
+	a := Account{ID: "a1", Name: "viraj", Email: "viraj@example.com", Role: auth.RoleCustomer}
+	if err := r.PutAccountWithPassword(t.Context(), a, "hash"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.PutAccountWithPassword(t.Context(), Account{ID: "a2", Email: a.Email}, "other"); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("expected ErrEmailTaken, got %v", err)
+	}
+
+	got, hash, err := r.GetPasswordHash(t.Context(), a.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != a || hash != "hash" {
+		t.Errorf("unexpected account %+v with hash %q", got, hash)
+	}
+
+	if _, _, err := r.GetPasswordHash(t.Context(), "nobody@example.com"); !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("expected ErrAccountNotFound, got %v", err)
+	}
+}
+
+func TestMemoryRepository_SetRole(t *testing.T) {
+	r := NewMemoryRepository()
+	if err := r.PutAccount(t.Context(), Account{ID: "a1", Name: "viraj", Role: auth.RoleCustomer}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := r.SetRole(t.Context(), "a1", auth.RoleAdmin)
+	if err != nil || a.Role != auth.RoleAdmin {
+		t.Fatalf("expected the role to change, got %+v: %v", a, err)
+	}
+	if a, _ := r.GetAccountByID(t.Context(), "a1"); a.Role != auth.RoleAdmin {
+		t.Errorf("expected the new role to be stored, got %+v", a)
+	}
+	if _, err := r.SetRole(t.Context(), "missing", auth.RoleAdmin); !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("expected ErrAccountNotFound, got %v", err)
+	}
+}
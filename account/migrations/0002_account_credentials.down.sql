@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS accounts_email_idx;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS password_hash;
+ALTER TABLE accounts DROP COLUMN IF EXISTS email;
@@ -0,0 +1,5 @@
+-- Accounts created before credentials existed have no email and cannot log in.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS email VARCHAR(254);
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS password_hash TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS accounts_email_idx ON accounts (email);
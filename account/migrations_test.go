@@ -0,0 +1,23 @@
+package account
+
+import "testing"
+
+func TestNewMigrator(t *testing.T) {
+	m, err := NewMigrator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrations := m.Migrations()
+	if len(migrations) == 0 {
+		t.Fatal("expected embedded migrations")
+	}
+	for i, mig := range migrations {
+		if mig.Version != int64(i+1) {
+			t.Errorf("expected version %d, got %s", i+1, mig)
+		}
+		if mig.Down == "" {
+			t.Errorf("expected %s to be reversible", mig)
+		}
+	}
+}
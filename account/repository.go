@@ -5,16 +5,24 @@ import (
 	"database/sql"
 	"errors"
 
-	_ "github.com/lib/pq"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/metrics"
+	"github.com/XSAM/otelsql"
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
 var ErrAccountNotFound = errors.New("account not found")
 
 type Repository interface {
 	Close()
+	Ping(ctx context.Context) error
 	PutAccount(ctx context.Context, a Account) error
 	GetAccountByID(ctx context.Context, id string) (*Account, error)
 	ListAccounts(ctx context.Context, skip, take uint64) ([]Account, error)
+	PutAccountWithPassword(ctx context.Context, a Account, passwordHash string) error
+	GetPasswordHash(ctx context.Context, email string) (*Account, string, error)
+	SetRole(ctx context.Context, id string, role auth.Role) (*Account, error)
 }
 
 type postgresRepository struct {
@@ -22,7 +30,7 @@ type postgresRepository struct {
 }
 
 func NewPostgresRepository(url string) (Repository, error) {
-	db, err := sql.Open("postgres", url)
+	db, err := otelsql.Open("postgres", url, otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL))
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,9 @@ func NewPostgresRepository(url string) (Repository, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := metrics.RegisterDB(db, "account"); err != nil {
+		return nil, err
+	}
 
 	return &postgresRepository{db}, nil
 }
@@ -39,22 +50,22 @@ func (r *postgresRepository) Close() {
 	r.db.Close()
 }
 
-func (r *postgresRepository) Ping() error {
-	return r.db.Ping()
+func (r *postgresRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
 }
 
 func (r *postgresRepository) PutAccount(ctx context.Context, a Account) error {
-	query := `INSERT INTO accounts(id, name) VALUES($1,$2)`
-	_, err := r.db.ExecContext(ctx, query, a.ID, a.Name)
+	query := `INSERT INTO accounts(id, name, role) VALUES($1,$2,$3)`
+	_, err := r.db.ExecContext(ctx, query, a.ID, a.Name, a.Role)
 	return err
 }
 
 func (r *postgresRepository) GetAccountByID(ctx context.Context, id string) (*Account, error) {
-	query := `SELECT id, name FROM accounts WHERE id = $1`
+	query := `SELECT id, name, role FROM accounts WHERE id = $1`
 	row := r.db.QueryRowContext(ctx, query, id)
 
 	a := &Account{}
-	if err := row.Scan(&a.ID, &a.Name); err != nil {
+	if err := row.Scan(&a.ID, &a.Name, &a.Role); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrAccountNotFound
 		}
@@ -65,7 +76,7 @@ func (r *postgresRepository) GetAccountByID(ctx context.Context, id string) (*Ac
 }
 
 func (r *postgresRepository) ListAccounts(ctx context.Context, skip, take uint64) ([]Account, error) {
-	query := `SELECT id, name FROM accounts ORDER BY id DESC OFFSET $1 LIMIT $2`
+	query := `SELECT id, name, role FROM accounts ORDER BY id DESC OFFSET $1 LIMIT $2`
 	rows, err := r.db.QueryContext(ctx, query, skip, take)
 	if err != nil {
 		return nil, err
@@ -75,7 +86,7 @@ func (r *postgresRepository) ListAccounts(ctx context.Context, skip, take uint64
 	accounts := []Account{}
 	for rows.Next() {
 		a := &Account{}
-		if err = rows.Scan(&a.ID, &a.Name); err == nil {
+		if err = rows.Scan(&a.ID, &a.Name, &a.Role); err == nil {
 			accounts = append(accounts, *a)
 		}
 	}
@@ -86,3 +97,48 @@ func (r *postgresRepository) ListAccounts(ctx context.Context, skip, take uint64
 
 	return accounts, nil
 }
+
+// PutAccountWithPassword stores an account that can log in with a.Email.
+// It fails with ErrEmailTaken if another account has the same email.
+func (r *postgresRepository) PutAccountWithPassword(ctx context.Context, a Account, passwordHash string) error {
+	query := `INSERT INTO accounts(id, name, email, password_hash, role) VALUES($1,$2,$3,$4,$5)`
+	_, err := r.db.ExecContext(ctx, query, a.ID, a.Name, a.Email, passwordHash, a.Role)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// GetPasswordHash returns the account registered with email and its
+// password hash.
+func (r *postgresRepository) GetPasswordHash(ctx context.Context, email string) (*Account, string, error) {
+	query := `SELECT id, name, email, password_hash, role FROM accounts WHERE email = $1`
+	row := r.db.QueryRowContext(ctx, query, email)
+
+	a := &Account{}
+	var hash sql.NullString
+	if err := row.Scan(&a.ID, &a.Name, &a.Email, &hash, &a.Role); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", ErrAccountNotFound
+		}
+		return nil, "", err
+	}
+
+	return a, hash.String, nil
+}
+
+func (r *postgresRepository) SetRole(ctx context.Context, id string, role auth.Role) (*Account, error) {
+	query := `UPDATE accounts SET role = $2 WHERE id = $1 RETURNING id, name, role`
+	row := r.db.QueryRowContext(ctx, query, id, role)
+
+	a := &Account{}
+	if err := row.Scan(&a.ID, &a.Name, &a.Role); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	return a, nil
+}
@@ -10,12 +10,17 @@ import (
 
 var testRepo Repository
 
+// TestMain runs the repository tests against the Postgres at
+// DATABASE_URL_FOR_TEST, or in memory if it is unset or "memory://".
 func TestMain(m *testing.M) {
 	url := os.Getenv("DATABASE_URL_FOR_TEST")
 
-	r, err := NewPostgresRepository(url)
-	if err != nil {
-		panic(err)
+	r := NewMemoryRepository()
+	if url != "" && url != "memory://" {
+		var err error
+		if r, err = NewPostgresRepository(url); err != nil {
+			panic(err)
+		}
 	}
 
 	testRepo = r
@@ -2,9 +2,12 @@ package account
 
 import (
 	"database/sql"
+	"errors"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/lib/pq"
 )
 
 func newMockRepo(t *testing.T) (*postgresRepository, sqlmock.Sqlmock, func()) {
@@ -26,9 +29,9 @@ func TestPutAccount(t *testing.T) {
 	repo, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
-	rows := sqlmock.NewRows([]string{"id", "name"}).AddRow("u11", "viraj")
+	rows := sqlmock.NewRows([]string{"id", "name", "role"}).AddRow("u11", "viraj", "customer")
 
-	mock.ExpectQuery(`SELECT id, name FROM accounts WHERE id = \$1`).
+	mock.ExpectQuery(`SELECT id, name, role FROM accounts WHERE id = \$1`).
 		WithArgs("u11").
 		WillReturnRows(rows)
 
@@ -46,7 +49,7 @@ func TestGetAccountById_NotFound(t *testing.T) {
 	repo, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
-	mock.ExpectQuery(`SELECT id, name FROM accounts WHERE id = \$1`).
+	mock.ExpectQuery(`SELECT id, name, role FROM accounts WHERE id = \$1`).
 		WithArgs("u21").
 		WillReturnError(sql.ErrNoRows)
 
@@ -60,11 +63,11 @@ func TestListAccounts(t *testing.T) {
 	repo, mock, cleanup := newMockRepo(t)
 	defer cleanup()
 
-	rows := sqlmock.NewRows([]string{"id", "name"}).
-		AddRow("u32", "Alice").
-		AddRow("u31", "Bob")
+	rows := sqlmock.NewRows([]string{"id", "name", "role"}).
+		AddRow("u32", "Alice", "customer").
+		AddRow("u31", "Bob", "customer")
 
-	mock.ExpectQuery(`SELECT id, name FROM accounts ORDER BY id DESC OFFSET \$1 LIMIT \$2`).
+	mock.ExpectQuery(`SELECT id, name, role FROM accounts ORDER BY id DESC OFFSET \$1 LIMIT \$2`).
 		WithArgs(uint64(0), uint64(2)).
 		WillReturnRows(rows)
 
@@ -77,3 +80,41 @@ func TestListAccounts(t *testing.T) {
 		t.Fatalf("expected 2 accounts, got %d", len(accs))
 	}
 }
+
+func TestPutAccountWithPassword_EmailTaken(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectExec(`INSERT INTO accounts\(id, name, email, password_hash, role\)`).
+		WithArgs("u1", "viraj", "viraj@example.com", "hash", "customer").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	err := repo.PutAccountWithPassword(t.Context(), Account{ID: "u1", Name: "viraj", Email: "viraj@example.com", Role: auth.RoleCustomer}, "hash")
+	if !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("expected ErrEmailTaken, got %v", err)
+	}
+}
+
+func TestSetRole(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`UPDATE accounts SET role = \$2 WHERE id = \$1 RETURNING id, name, role`).
+		WithArgs("u1", auth.RoleMerchant).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "role"}).AddRow("u1", "viraj", "merchant"))
+	mock.ExpectQuery(`UPDATE accounts SET role`).
+		WithArgs("u2", auth.RoleMerchant).
+		WillReturnError(sql.ErrNoRows)
+
+	acc, err := repo.SetRole(t.Context(), "u1", auth.RoleMerchant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc.Role != auth.RoleMerchant {
+		t.Errorf("expected merchant, got %#v", acc)
+	}
+
+	if _, err := repo.SetRole(t.Context(), "u2", auth.RoleMerchant); !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("expected ErrAccountNotFound, got %v", err)
+	}
+}
@@ -2,12 +2,19 @@ package account
 
 import (
 	"context"
-	"fmt"
-	"net"
 
 	"github.com/RathodViraj/go-microservice-graphql-grpc/account/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/errcode"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/health"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/metrics"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/middleware"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/serve"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type grpcServer struct {
@@ -15,15 +22,52 @@ type grpcServer struct {
 	service Service
 }
 
-func ListenGRPC(s Service, port int) error {
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+// methodRoles are the roles required by the methods that are not open to
+// everyone. GetAccount additionally checks the caller owns the account.
+var methodRoles = map[string]auth.Role{
+	pb.AccountService_GetAccount_FullMethodName:  auth.RoleCustomer,
+	pb.AccountService_GetAccounts_FullMethodName: auth.RoleAdmin,
+	pb.AccountService_SetRole_FullMethodName:     auth.RoleAdmin,
+}
+
+var errorCodes = errcode.Codes{
+	ErrAccountNotFound:    codes.NotFound,
+	ErrInvalidName:        codes.InvalidArgument,
+	ErrInvalidEmail:       codes.InvalidArgument,
+	ErrInvalidPassword:    codes.InvalidArgument,
+	ErrInvalidRole:        codes.InvalidArgument,
+	ErrEmailTaken:         codes.AlreadyExists,
+	ErrInvalidCredentials: codes.Unauthenticated,
+	auth.ErrInvalidToken:  codes.Unauthenticated,
+	ErrAuthNotConfigured:  codes.Unimplemented,
+}
+
+// DefaultPort is the port ListenGRPC listens on unless told otherwise.
+const DefaultPort = 8081
+
+// ListenGRPC serves s until ctx is done, then drains the calls in flight.
+// tokens verifies the access tokens callers authenticate with.
+func ListenGRPC(ctx context.Context, s Service, tokens *auth.Signer, opts ...serve.Option) error {
+	cfg := serve.NewConfig(DefaultPort, opts...)
+	lis, err := cfg.Listen()
 	if err != nil {
 		return err
 	}
-	srv := grpc.NewServer()
+	srv := grpc.NewServer(append([]grpc.ServerOption{
+		tracing.ServerOption(),
+		metrics.ServerOption(),
+		middleware.ServerOption(),
+		grpc.ChainUnaryInterceptor(
+			errcode.UnaryServerInterceptor(errorCodes),
+			auth.UnaryServerInterceptor(tokens, methodRoles),
+		),
+	}, cfg.ServerOptions...)...)
 	pb.RegisterAccountServiceServer(srv, &grpcServer{service: s})
+	health.Serve(ctx, srv, map[string]health.Check{
+		"postgres": s.Ping,
+	})
 	reflection.Register(srv)
-	return srv.Serve(lis)
+	return serve.GRPC(ctx, srv, lis, cfg.DrainTimeout)
 }
 
 func (s *grpcServer) PostAccount(ctx context.Context, r *pb.PostAccountRequest) (*pb.PostAccountResponse, error) {
@@ -31,22 +75,20 @@ func (s *grpcServer) PostAccount(ctx context.Context, r *pb.PostAccountRequest)
 	if err != nil {
 		return nil, err
 	}
-	return &pb.PostAccountResponse{Account: &pb.Account{
-		Id:   a.ID,
-		Name: a.Name,
-	}}, nil
+	return &pb.PostAccountResponse{Account: accountToProto(a)}, nil
 }
 
 func (s *grpcServer) GetAccount(ctx context.Context, r *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	if id, ok := auth.FromContext(ctx); ok && !id.Owns(r.Id) {
+		return nil, status.Error(codes.PermissionDenied, "not allowed to read this account")
+	}
+
 	a, err := s.service.GetAccount(ctx, r.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	return &pb.GetAccountResponse{Account: &pb.Account{
-		Id:   a.ID,
-		Name: a.Name,
-	}}, nil
+	return &pb.GetAccountResponse{Account: accountToProto(a)}, nil
 }
 
 func (s *grpcServer) GetAccounts(ctx context.Context, r *pb.GetAccountsRequest) (*pb.GetAccountsResponse, error) {
@@ -56,16 +98,65 @@ func (s *grpcServer) GetAccounts(ctx context.Context, r *pb.GetAccountsRequest)
 	}
 
 	accounts := []*pb.Account{}
-	for _, ac := range res {
-		a := &pb.Account{
-			Id:   ac.ID,
-			Name: ac.Name,
-		}
-		accounts = append(accounts, a)
-
+	for i := range res {
+		accounts = append(accounts, accountToProto(&res[i]))
 	}
 
 	return &pb.GetAccountsResponse{
 		Accounts: accounts,
 	}, nil
 }
+
+func (s *grpcServer) Register(ctx context.Context, r *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	a, tokens, err := s.service.Register(ctx, r.Name, r.Email, r.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RegisterResponse{Account: accountToProto(a), Tokens: tokensToProto(tokens)}, nil
+}
+
+func (s *grpcServer) Login(ctx context.Context, r *pb.LoginRequest) (*pb.LoginResponse, error) {
+	a, tokens, err := s.service.Login(ctx, r.Email, r.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.LoginResponse{Account: accountToProto(a), Tokens: tokensToProto(tokens)}, nil
+}
+
+func (s *grpcServer) RefreshToken(ctx context.Context, r *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	a, tokens, err := s.service.RefreshToken(ctx, r.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RefreshTokenResponse{Account: accountToProto(a), Tokens: tokensToProto(tokens)}, nil
+}
+
+func (s *grpcServer) SetRole(ctx context.Context, r *pb.SetRoleRequest) (*pb.SetRoleResponse, error) {
+	a, err := s.service.SetRole(ctx, r.Id, auth.Role(r.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SetRoleResponse{Account: accountToProto(a)}, nil
+}
+
+func accountToProto(a *Account) *pb.Account {
+	return &pb.Account{
+		Id:    a.ID,
+		Name:  a.Name,
+		Email: a.Email,
+		Role:  string(a.Role),
+	}
+}
+
+func tokensToProto(t *auth.TokenPair) *pb.Tokens {
+	res := &pb.Tokens{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+	}
+	res.ExpiresAt, _ = t.ExpiresAt.MarshalBinary()
+	return res
+}
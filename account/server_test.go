@@ -5,20 +5,26 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/RathodViraj/go-microservice-graphql-grpc/account/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 )
 
 const bufSize = 1024 * 1024
 
-func startTestServer(t *testing.T, svc Service) (*grpc.ClientConn, func()) {
+func startTestServer(t *testing.T, svc Service, opts ...grpc.ServerOption) (*grpc.ClientConn, func()) {
 	lis := bufconn.Listen(bufSize)
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(opts...)
 	pb.RegisterAccountServiceServer(s, &grpcServer{service: svc})
 
 	go func() {
@@ -134,3 +140,77 @@ func TestGetAccount_Fail(t *testing.T) {
 		log.Fatal("expected error; got nil.")
 	}
 }
+
+func TestMethodRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer, err := auth.NewSigner([]byte(strings.Repeat("s", auth.MinSecretLength)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer, _ := signer.Issue("u1", auth.RoleCustomer)
+	admin, _ := signer.Issue("u2", auth.RoleAdmin)
+
+	mockSvc := NewMockService(ctrl)
+	mockSvc.EXPECT().GetAccount(gomock.Any(), "u1").Return(&Account{ID: "u1", Name: "viraj", Role: auth.RoleCustomer}, nil)
+	mockSvc.EXPECT().PostAccount(gomock.Any(), "viraj").Return(&Account{ID: "u3", Name: "viraj", Role: auth.RoleCustomer}, nil)
+	mockSvc.EXPECT().SetRole(gomock.Any(), "u1", auth.RoleMerchant).Return(&Account{ID: "u1", Name: "viraj", Role: auth.RoleMerchant}, nil)
+
+	conn, cleanup := startTestServer(t, mockSvc, grpc.UnaryInterceptor(auth.UnaryServerInterceptor(signer, methodRoles)))
+	defer cleanup()
+	client := pb.NewAccountServiceClient(conn)
+
+	as := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	if _, err := client.PostAccount(context.Background(), &pb.PostAccountRequest{Name: "viraj"}); err != nil {
+		t.Errorf("anonymous PostAccount: expected it to stay open, got %v", err)
+	}
+	if _, err := client.GetAccounts(context.Background(), &pb.GetAccountsRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("anonymous GetAccounts: expected Unauthenticated, got %v", err)
+	}
+	if _, err := client.GetAccounts(as(customer.AccessToken), &pb.GetAccountsRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("customer GetAccounts: expected PermissionDenied, got %v", err)
+	}
+	if _, err := client.GetAccount(as(customer.AccessToken), &pb.GetAccountRequest{Id: "u3"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("customer GetAccount of another account: expected PermissionDenied, got %v", err)
+	}
+	if res, err := client.GetAccount(as(customer.AccessToken), &pb.GetAccountRequest{Id: "u1"}); err != nil || res.Account.Role != "customer" {
+		t.Errorf("customer GetAccount of own account: got %v, %v", res, err)
+	}
+	if _, err := client.SetRole(as(customer.AccessToken), &pb.SetRoleRequest{Id: "u1", Role: "admin"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("customer SetRole: expected PermissionDenied, got %v", err)
+	}
+	if res, err := client.SetRole(as(admin.AccessToken), &pb.SetRoleRequest{Id: "u1", Role: "merchant"}); err != nil || res.Account.Role != "merchant" {
+		t.Errorf("admin SetRole: got %v, %v", res, err)
+	}
+}
+
+func TestLogin_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockService(ctrl)
+	expiresAt := time.Now().Add(time.Hour).UTC()
+
+	mockSvc.EXPECT().
+		Login(gomock.Any(), "viraj@example.com", "correct horse").
+		Return(&Account{ID: "u1", Name: "viraj", Email: "viraj@example.com"}, &auth.TokenPair{AccessToken: "a", RefreshToken: "r", ExpiresAt: expiresAt}, nil)
+
+	conn, cleanup := startTestServer(t, mockSvc)
+	defer cleanup()
+
+	client := pb.NewAccountServiceClient(conn)
+	res, err := client.Login(context.Background(), &pb.LoginRequest{Email: "viraj@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got time.Time
+	got.UnmarshalBinary(res.Tokens.ExpiresAt)
+	if res.Account.Email != "viraj@example.com" || res.Tokens.AccessToken != "a" || !got.Equal(expiresAt) {
+		t.Errorf("unexpected response: %#v", res)
+	}
+}
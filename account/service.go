@@ -2,33 +2,62 @@ package account
 
 import (
 	"context"
+	"errors"
+	"strings"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
 	"github.com/segmentio/ksuid"
 )
 
 type Service interface {
+	// Ping reports whether the store behind the service can be reached.
+	Ping(ctx context.Context) error
 	PostAccount(ctx context.Context, name string) (*Account, error)
 	GetAccount(ctx context.Context, id string) (*Account, error)
 	GetAccounts(ctx context.Context, skip, take uint64) ([]Account, error)
+	Register(ctx context.Context, name, email, password string) (*Account, *auth.TokenPair, error)
+	Login(ctx context.Context, email, password string) (*Account, *auth.TokenPair, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*Account, *auth.TokenPair, error)
+	SetRole(ctx context.Context, id string, role auth.Role) (*Account, error)
 }
 
+var ErrInvalidRole = errors.New("invalid role")
+
 type Account struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	// Email is set for accounts that can log in.
+	Email string    `json:"email,omitempty"`
+	Role  auth.Role `json:"role,omitempty"`
+}
+
+type ServiceOption func(*accountService)
+
+// WithSigner enables Register, Login and RefreshToken, which issue tokens signed by s.
+func WithSigner(s *auth.Signer) ServiceOption {
+	return func(as *accountService) {
+		as.signer = s
+	}
 }
 
 type accountService struct {
-	repo Repository
+	repo   Repository
+	signer *auth.Signer
 }
 
-func NewService(r Repository) Service {
-	return &accountService{r}
+func NewService(r Repository, opts ...ServiceOption) Service {
+	s := &accountService{repo: r}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *accountService) PostAccount(ctx context.Context, name string) (*Account, error) {
 	a := &Account{
 		Name: name,
 		ID:   ksuid.New().String(),
+		Role: auth.RoleCustomer,
 	}
 	if err := s.repo.PutAccount(ctx, *a); err != nil {
 		return nil, err
@@ -46,3 +75,104 @@ func (s *accountService) GetAccounts(ctx context.Context, skip, take uint64) ([]
 	}
 	return s.repo.ListAccounts(ctx, skip, take)
 }
+
+// Register creates an account that logs in with email and password and
+// returns it with its first tokens.
+func (s *accountService) Register(ctx context.Context, name, email, password string) (*Account, *auth.TokenPair, error) {
+	if s.signer == nil {
+		return nil, nil, ErrAuthNotConfigured
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > MaxNameLength {
+		return nil, nil, ErrInvalidName
+	}
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, nil, err
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a := &Account{
+		ID:    ksuid.New().String(),
+		Name:  name,
+		Email: email,
+		Role:  auth.RoleCustomer,
+	}
+	if err := s.repo.PutAccountWithPassword(ctx, *a, hash); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.signer.Issue(a.ID, a.Role)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, tokens, nil
+}
+
+// Login returns new tokens for the account registered with email. Unknown
+// emails and wrong passwords both fail with ErrInvalidCredentials.
+func (s *accountService) Login(ctx context.Context, email, password string) (*Account, *auth.TokenPair, error) {
+	if s.signer == nil {
+		return nil, nil, ErrAuthNotConfigured
+	}
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	a, hash, err := s.repo.GetPasswordHash(ctx, email)
+	if err != nil && !errors.Is(err, ErrAccountNotFound) {
+		return nil, nil, err
+	}
+	if err := checkPassword(hash, password); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.signer.Issue(a.ID, a.Role)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, tokens, nil
+}
+
+// RefreshToken exchanges a refresh token for a new pair, as long as its
+// account still exists. The new tokens carry the account's current role.
+func (s *accountService) RefreshToken(ctx context.Context, refreshToken string) (*Account, *auth.TokenPair, error) {
+	if s.signer == nil {
+		return nil, nil, ErrAuthNotConfigured
+	}
+	claims, err := s.signer.Verify(refreshToken, auth.RefreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a, err := s.repo.GetAccountByID(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, ErrAccountNotFound) {
+			return nil, nil, auth.ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+
+	tokens, err := s.signer.Issue(a.ID, a.Role)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, tokens, nil
+}
+
+// SetRole changes the role of an account. Tokens already issued keep the
+// old role until they are refreshed.
+func (s *accountService) SetRole(ctx context.Context, id string, role auth.Role) (*Account, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	return s.repo.SetRole(ctx, id, role)
+}
+
+func (s *accountService) Ping(ctx context.Context) error {
+	return s.repo.Ping(ctx)
+}
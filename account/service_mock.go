@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	auth "github.com/RathodViraj/go-microservice-graphql-grpc/auth"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -70,6 +71,36 @@ func (mr *MockServiceMockRecorder) GetAccounts(ctx, skip, take any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockService)(nil).GetAccounts), ctx, skip, take)
 }
 
+// Login mocks base method.
+func (m *MockService) Login(ctx context.Context, email, password string) (*Account, *auth.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, email, password)
+	ret0, _ := ret[0].(*Account)
+	ret1, _ := ret[1].(*auth.TokenPair)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockServiceMockRecorder) Login(ctx, email, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockService)(nil).Login), ctx, email, password)
+}
+
+// Ping mocks base method.
+func (m *MockService) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockServiceMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockService)(nil).Ping), ctx)
+}
+
 // PostAccount mocks base method.
 func (m *MockService) PostAccount(ctx context.Context, name string) (*Account, error) {
 	m.ctrl.T.Helper()
@@ -84,3 +115,50 @@ func (mr *MockServiceMockRecorder) PostAccount(ctx, name any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostAccount", reflect.TypeOf((*MockService)(nil).PostAccount), ctx, name)
 }
+
+// RefreshToken mocks base method.
+func (m *MockService) RefreshToken(ctx context.Context, refreshToken string) (*Account, *auth.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(*Account)
+	ret1, _ := ret[1].(*auth.TokenPair)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockServiceMockRecorder) RefreshToken(ctx, refreshToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockService)(nil).RefreshToken), ctx, refreshToken)
+}
+
+// Register mocks base method.
+func (m *MockService) Register(ctx context.Context, name, email, password string) (*Account, *auth.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, name, email, password)
+	ret0, _ := ret[0].(*Account)
+	ret1, _ := ret[1].(*auth.TokenPair)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockServiceMockRecorder) Register(ctx, name, email, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockService)(nil).Register), ctx, name, email, password)
+}
+
+// SetRole mocks base method.
+func (m *MockService) SetRole(ctx context.Context, id string, role auth.Role) (*Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRole", ctx, id, role)
+	ret0, _ := ret[0].(*Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRole indicates an expected call of SetRole.
+func (mr *MockServiceMockRecorder) SetRole(ctx, id, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRole", reflect.TypeOf((*MockService)(nil).SetRole), ctx, id, role)
+}
@@ -1,9 +1,13 @@
 package account
 
 import (
+	"database/sql"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
 )
 
 func newMockService(repo Repository) *accountService {
@@ -15,9 +19,9 @@ func Test_service_PutAccount(t *testing.T) {
 	defer cleanup()
 	service := newMockService(repo)
 
-	rows := sqlmock.NewRows([]string{"id", "name"}).AddRow("u11", "viraj")
+	rows := sqlmock.NewRows([]string{"id", "name", "role"}).AddRow("u11", "viraj", "customer")
 
-	mock.ExpectQuery(`SELECT id, name FROM accounts WHERE id = \$1`).
+	mock.ExpectQuery(`SELECT id, name, role FROM accounts WHERE id = \$1`).
 		WithArgs("u11").
 		WillReturnRows(rows)
 
@@ -35,11 +39,11 @@ func Test_service_ListAccount(t *testing.T) {
 	defer cleanup()
 	service := newMockService(repo)
 
-	rows := sqlmock.NewRows([]string{"id", "name"}).
-		AddRow("u22", "Alice").
-		AddRow("u21", "Bob")
+	rows := sqlmock.NewRows([]string{"id", "name", "role"}).
+		AddRow("u22", "Alice", "customer").
+		AddRow("u21", "Bob", "customer")
 
-	mock.ExpectQuery(`SELECT id, name FROM accounts ORDER BY id DESC OFFSET \$1 LIMIT \$2`).
+	mock.ExpectQuery(`SELECT id, name, role FROM accounts ORDER BY id DESC OFFSET \$1 LIMIT \$2`).
 		WithArgs(uint64(0), uint64(2)).
 		WillReturnRows(rows)
 
@@ -58,10 +62,10 @@ func Test_service_ListAccountWithSkip(t *testing.T) {
 	defer cleanup()
 	service := newMockService(repo)
 
-	rows := sqlmock.NewRows([]string{"id", "name"}).
-		AddRow("u22", "Alice")
+	rows := sqlmock.NewRows([]string{"id", "name", "role"}).
+		AddRow("u22", "Alice", "customer")
 
-	mock.ExpectQuery(`SELECT id, name FROM accounts ORDER BY id DESC OFFSET \$1 LIMIT \$2`).
+	mock.ExpectQuery(`SELECT id, name, role FROM accounts ORDER BY id DESC OFFSET \$1 LIMIT \$2`).
 		WithArgs(uint64(1), uint64(100)).
 		WillReturnRows(rows)
 
@@ -80,11 +84,11 @@ func Test_service_ListAccount_InvalidSkipTake(t *testing.T) {
 	defer cleanup()
 	service := newMockService(repo)
 
-	rows := sqlmock.NewRows([]string{"id", "name"}).
-		AddRow("u32", "Alice").
-		AddRow("u31", "Bob")
+	rows := sqlmock.NewRows([]string{"id", "name", "role"}).
+		AddRow("u32", "Alice", "customer").
+		AddRow("u31", "Bob", "customer")
 
-	mock.ExpectQuery(`SELECT id, name FROM accounts ORDER BY id DESC OFFSET \$1 LIMIT \$2`).
+	mock.ExpectQuery(`SELECT id, name, role FROM accounts ORDER BY id DESC OFFSET \$1 LIMIT \$2`).
 		WithArgs(uint64(0), uint64(100)).
 		WillReturnRows(rows)
 
@@ -97,3 +101,155 @@ func Test_service_ListAccount_InvalidSkipTake(t *testing.T) {
 		t.Fatalf("expected 2 accounts, got %d", len(accs))
 	}
 }
+
+func newAuthService(t *testing.T, repo Repository) (Service, *auth.Signer) {
+	t.Helper()
+	signer, err := auth.NewSigner([]byte(strings.Repeat("s", auth.MinSecretLength)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewService(repo, WithSigner(signer)), signer
+}
+
+func Test_service_Register(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+	service, signer := newAuthService(t, repo)
+
+	mock.ExpectExec(`INSERT INTO accounts\(id, name, email, password_hash, role\)`).
+		WithArgs(sqlmock.AnyArg(), "viraj", "viraj@example.com", sqlmock.AnyArg(), "customer").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	acc, tokens, err := service.Register(t.Context(), " viraj ", "Viraj@Example.com", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc.Name != "viraj" || acc.Email != "viraj@example.com" {
+		t.Errorf("unexpected account: %#v", acc)
+	}
+	claims, err := signer.Verify(tokens.AccessToken, auth.AccessToken)
+	if err != nil || claims.Subject != acc.ID {
+		t.Errorf("expected access token for %s, got %+v, %v", acc.ID, claims, err)
+	}
+}
+
+func Test_service_Register_InvalidInput(t *testing.T) {
+	testCases := []struct {
+		name     string
+		accName  string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{name: "empty name", accName: " ", email: "a@example.com", password: "long enough", wantErr: ErrInvalidName},
+		{name: "long name", accName: strings.Repeat("n", MaxNameLength+1), email: "a@example.com", password: "long enough", wantErr: ErrInvalidName},
+		{name: "bad email", accName: "viraj", email: "not-an-email", password: "long enough", wantErr: ErrInvalidEmail},
+		{name: "short password", accName: "viraj", email: "a@example.com", password: "short", wantErr: ErrInvalidPassword},
+		{name: "long password", accName: "viraj", email: "a@example.com", password: strings.Repeat("p", MaxPasswordLength+1), wantErr: ErrInvalidPassword},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, _, cleanup := newMockRepo(t)
+			defer cleanup()
+			service, _ := newAuthService(t, repo)
+
+			if _, _, err := service.Register(t.Context(), tc.accName, tc.email, tc.password); !errors.Is(err, tc.wantErr) {
+				t.Errorf("expected %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_service_Login(t *testing.T) {
+	hash, err := hashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		password string
+		found    bool
+		wantErr  error
+	}{
+		{name: "success", password: "correct horse", found: true},
+		{name: "wrong password", password: "wrong horse", found: true, wantErr: ErrInvalidCredentials},
+		{name: "unknown email", password: "correct horse", wantErr: ErrInvalidCredentials},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, mock, cleanup := newMockRepo(t)
+			defer cleanup()
+			service, _ := newAuthService(t, repo)
+
+			q := mock.ExpectQuery(`SELECT id, name, email, password_hash, role FROM accounts WHERE email = \$1`).
+				WithArgs("viraj@example.com")
+			if tc.found {
+				q.WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "password_hash", "role"}).AddRow("u1", "viraj", "viraj@example.com", hash, "merchant"))
+			} else {
+				q.WillReturnError(sql.ErrNoRows)
+			}
+
+			acc, tokens, err := service.Login(t.Context(), "viraj@example.com", tc.password)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr == nil && (acc.ID != "u1" || acc.Role != auth.RoleMerchant || tokens.AccessToken == "") {
+				t.Errorf("unexpected result: %#v, %#v", acc, tokens)
+			}
+		})
+	}
+}
+
+func Test_service_RefreshToken(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+	service, signer := newAuthService(t, repo)
+
+	pair, err := signer.Issue("u1", auth.RoleCustomer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(`SELECT id, name, role FROM accounts WHERE id = \$1`).
+		WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "role"}).AddRow("u1", "viraj", "admin"))
+
+	acc, tokens, err := service.RefreshToken(t.Context(), pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc.ID != "u1" || tokens.RefreshToken == "" {
+		t.Errorf("unexpected result: %#v, %#v", acc, tokens)
+	}
+	// A role changed since the last refresh is picked up.
+	if claims, err := signer.Verify(tokens.AccessToken, auth.AccessToken); err != nil || claims.Role != auth.RoleAdmin {
+		t.Errorf("expected admin access token, got %+v, %v", claims, err)
+	}
+
+	if _, _, err := service.RefreshToken(t.Context(), pair.AccessToken); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("expected access token to be rejected, got %v", err)
+	}
+}
+
+func Test_service_Login_NotConfigured(t *testing.T) {
+	repo, _, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	if _, _, err := NewService(repo).Login(t.Context(), "viraj@example.com", "correct horse"); !errors.Is(err, ErrAuthNotConfigured) {
+		t.Errorf("expected ErrAuthNotConfigured, got %v", err)
+	}
+}
+
+func Test_service_SetRole_Invalid(t *testing.T) {
+	repo, _, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	for _, role := range []auth.Role{"", "owner", auth.RoleService} {
+		if _, err := NewService(repo).SetRole(t.Context(), "u1", role); !errors.Is(err, ErrInvalidRole) {
+			t.Errorf("role %q: expected ErrInvalidRole, got %v", role, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package allinone runs every service and the GraphQL gateway in a single
+// process, for local development. The services are served by their own
+// ListenGRPC and called through their own clients, like when deployed, but
+// over in-memory listeners instead of TCP ports.
+package allinone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/account"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/graphql"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/migrate"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/order"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/serve"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// MemoryURL selects the in-memory repository of a service instead of a
+// database. Nothing stored survives a restart.
+const MemoryURL = "memory://"
+
+// listenerSize is the buffer of each in-memory listener.
+const listenerSize = 1024 * 1024
+
+// Config is where each service keeps its data and how the process runs.
+type Config struct {
+	// The DatabaseURLs are those of the service binaries, or MemoryURL.
+	// Postgres databases are migrated on start.
+	AccountDatabaseURL   string
+	CatalogDatabaseURL   string
+	InventoryDatabaseURL string
+	OrderDatabaseURL     string
+	// Tokens signs and verifies the access tokens of every service.
+	Tokens *auth.Signer
+	// Seed adds demo accounts and products unless already there.
+	Seed bool
+	// CancellableUntil is the furthest order status that can still be
+	// cancelled.
+	CancellableUntil order.OrderStatus
+	DrainTimeout     time.Duration
+}
+
+// Run serves the gateway on lis until ctx is done. The services are
+// stopped only once the gateway has drained its requests.
+func Run(ctx context.Context, cfg Config, lis net.Listener) (err error) {
+	repos, err := openRepositories(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer repos.close()
+
+	accounts := account.NewService(repos.account, account.WithSigner(cfg.Tokens))
+	products := catalog.NewSerivce(repos.catalog)
+	stock := inventory.NewService(repos.inventory)
+	orders := order.NewOrderService(repos.order, order.WithCancellableUntil(cfg.CancellableUntil))
+	if cfg.Seed {
+		if err := seed(ctx, accounts, products, stock); err != nil {
+			return fmt.Errorf("seeding: %w", err)
+		}
+	}
+
+	listeners := map[string]*bufconn.Listener{}
+	for _, name := range []string{"account", "catalog", "inventory", "order"} {
+		listeners[name] = bufconn.Listen(listenerSize)
+	}
+	dial := grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		lis, ok := listeners[addr]
+		if !ok {
+			return nil, fmt.Errorf("no service called %q", addr)
+		}
+		return lis.DialContext(ctx)
+	})
+	target := func(name string) string { return "passthrough:///" + name }
+	opts := func(name string) []serve.Option {
+		return []serve.Option{
+			serve.WithListener(listeners[name]),
+			serve.WithDialOptions(dial),
+			serve.WithDrainTimeout(cfg.DrainTimeout),
+		}
+	}
+
+	gateway, err := graphql.NewGraphQLServer(target("account"), target("order"), target("catalog"), target("inventory"), cfg.Tokens, dial)
+	if err != nil {
+		return err
+	}
+	defer gateway.Close()
+
+	// A service failing stops the gateway, and with it the other services.
+	gatewayCtx, stopGateway := context.WithCancel(ctx)
+	defer stopGateway()
+	servicesCtx, stopServices := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopServices()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	run := func(name string, listen func() error) {
+		wg.Go(func() {
+			if err := listen(); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				mu.Unlock()
+				stopGateway()
+			}
+		})
+	}
+	run("account", func() error {
+		return account.ListenGRPC(servicesCtx, accounts, cfg.Tokens, opts("account")...)
+	})
+	run("catalog", func() error {
+		return catalog.ListenGRPC(servicesCtx, products, cfg.Tokens, target("inventory"), opts("catalog")...)
+	})
+	run("inventory", func() error {
+		return inventory.ListenGRPC(servicesCtx, stock, cfg.Tokens, opts("inventory")...)
+	})
+	run("order", func() error {
+		return order.ListenGRPC(servicesCtx, orders, cfg.Tokens, repos.order, target("account"), target("catalog"), target("inventory"), opts("order")...)
+	})
+
+	err = serve.HTTP(gatewayCtx, &http.Server{Handler: gateway.Handler()}, lis, cfg.DrainTimeout)
+	stopServices()
+	wg.Wait()
+	return errors.Join(append(errs, err)...)
+}
+
+type repositories struct {
+	account   account.Repository
+	catalog   catalog.Repository
+	inventory inventory.Repository
+	order     order.Repository
+}
+
+func (r *repositories) close() {
+	for _, repo := range []interface{ Close() }{r.account, r.catalog, r.inventory, r.order} {
+		if repo != nil {
+			repo.Close()
+		}
+	}
+}
+
+// openRepositories connects to the store of every service, closing those
+// already open if one fails.
+func openRepositories(ctx context.Context, cfg Config) (repos *repositories, err error) {
+	repos = &repositories{}
+	defer func() {
+		if err != nil {
+			repos.close()
+		}
+	}()
+
+	if cfg.AccountDatabaseURL == MemoryURL {
+		repos.account = account.NewMemoryRepository()
+	} else {
+		if err := migrate.Run(ctx, cfg.AccountDatabaseURL, account.NewMigrator, []string{"up"}, log.Writer()); err != nil {
+			return nil, fmt.Errorf("account: %w", err)
+		}
+		if repos.account, err = account.NewPostgresRepository(cfg.AccountDatabaseURL); err != nil {
+			return nil, fmt.Errorf("account: %w", err)
+		}
+	}
+
+	if cfg.CatalogDatabaseURL == MemoryURL {
+		repos.catalog = catalog.NewMemoryRepository()
+	} else if repos.catalog, err = catalog.NewElasticRepository(cfg.CatalogDatabaseURL); err != nil {
+		return nil, fmt.Errorf("catalog: %w", err)
+	}
+
+	if cfg.InventoryDatabaseURL == MemoryURL {
+		repos.inventory = inventory.NewMemoryRepository()
+	} else if repos.inventory, err = inventory.NewRepository(cfg.InventoryDatabaseURL); err != nil {
+		return nil, fmt.Errorf("inventory: %w", err)
+	}
+
+	if cfg.OrderDatabaseURL == MemoryURL {
+		repos.order = order.NewMemoryRepository()
+	} else {
+		if err := migrate.Run(ctx, cfg.OrderDatabaseURL, order.NewMigrator, []string{"up"}, log.Writer()); err != nil {
+			return nil, fmt.Errorf("order: %w", err)
+		}
+		if repos.order, err = order.NewPostgresRepository(cfg.OrderDatabaseURL); err != nil {
+			return nil, fmt.Errorf("order: %w", err)
+		}
+	}
+	return repos, nil
+}
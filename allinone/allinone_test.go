@@ -0,0 +1,194 @@
+package allinone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/order"
+)
+
+// start runs every service in memory, with the demo data, until the test
+// ends, and returns the URL of the GraphQL endpoint.
+func start(t *testing.T) string {
+	t.Helper()
+	tokens, err := auth.NewSigner(bytes.Repeat([]byte("k"), auth.MinSecretLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, Config{
+			AccountDatabaseURL:   MemoryURL,
+			CatalogDatabaseURL:   MemoryURL,
+			InventoryDatabaseURL: MemoryURL,
+			OrderDatabaseURL:     MemoryURL,
+			Tokens:               tokens,
+			Seed:                 true,
+			CancellableUntil:     order.StatusPaid,
+			DrainTimeout:         time.Second,
+		}, lis)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run: %v", err)
+		}
+	})
+	return "http://" + lis.Addr().String() + "/graphql"
+}
+
+// query runs a GraphQL query as the holder of token, decoding its data into
+// out.
+func query(t *testing.T, url, token, q string, vars map[string]any, out any) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"query": q, "variables": vars})
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var payload struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Errors) != 0 {
+		t.Fatalf("query failed: %v", payload.Errors)
+	}
+	if err := json.Unmarshal(payload.Data, out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun_PlacesOrdersAcrossServices(t *testing.T) {
+	url := start(t)
+
+	var login struct {
+		Login struct {
+			AccessToken string `json:"accessToken"`
+		} `json:"login"`
+	}
+	query(t, url, "", `mutation($email: String!, $password: String!) { login(email: $email, password: $password) { accessToken } }`,
+		map[string]any{"email": "customer@example.com", "password": SeedPassword}, &login)
+	token := login.Login.AccessToken
+
+	var products struct {
+		Products []struct {
+			Product struct {
+				ID string `json:"id"`
+			} `json:"product"`
+			Quantity int `json:"quantity"`
+		} `json:"products"`
+	}
+	query(t, url, token, `{ products(pagination: {skip: 0, take: 10}) { product { id } quantity } }`, nil, &products)
+	if len(products.Products) != len(seedProducts) || products.Products[0].Quantity != seedStock {
+		t.Fatalf("expected the seeded products in stock, got %+v", products.Products)
+	}
+	pid := products.Products[0].Product.ID
+
+	var created struct {
+		CreateOrder struct {
+			Status string `json:"status"`
+		} `json:"createOrder"`
+	}
+	query(t, url, token, `mutation($pid: String!) { createOrder(order: {products: [{id: $pid, quantity: 2}]}) { status } }`,
+		map[string]any{"pid": pid}, &created)
+	if created.CreateOrder.Status != "PENDING" {
+		t.Errorf("expected a pending order, got %q", created.CreateOrder.Status)
+	}
+
+	var stock struct {
+		CheckStock []int `json:"checkStock"`
+	}
+	query(t, url, token, `query($pid: String!) { checkStock(pids: {ids: [$pid]}) }`, map[string]any{"pid": pid}, &stock)
+	if len(stock.CheckStock) != 1 || stock.CheckStock[0] != seedStock-2 {
+		t.Errorf("expected the order to take its stock, got %v", stock.CheckStock)
+	}
+}
+
+func TestRun_BrowsesProductsByCategory(t *testing.T) {
+	url := start(t)
+
+	var login struct {
+		Login struct {
+			AccessToken string `json:"accessToken"`
+		} `json:"login"`
+	}
+	query(t, url, "", `mutation($email: String!, $password: String!) { login(email: $email, password: $password) { accessToken } }`,
+		map[string]any{"email": "merchant@example.com", "password": SeedPassword}, &login)
+	token := login.Login.AccessToken
+
+	type category struct {
+		ID   string `json:"id"`
+		Path string `json:"path"`
+	}
+	var outdoors, shoes struct {
+		CreateCategory category `json:"createCategory"`
+	}
+	const createCategory = `mutation($name: String!, $parent: String) { createCategory(category: {name: $name, parentId: $parent}) { id path } }`
+	query(t, url, token, createCategory, map[string]any{"name": "Outdoors"}, &outdoors)
+	query(t, url, token, createCategory, map[string]any{"name": "Trail Shoes", "parent": outdoors.CreateCategory.ID}, &shoes)
+	if shoes.CreateCategory.Path != "outdoors/trail-shoes" {
+		t.Fatalf("unexpected path %q", shoes.CreateCategory.Path)
+	}
+
+	var products struct {
+		Products []struct {
+			Product struct {
+				ID string `json:"id"`
+			} `json:"product"`
+		} `json:"products"`
+	}
+	query(t, url, token, `{ products(pagination: {skip: 0, take: 1}) { product { id } } }`, nil, &products)
+	pid := products.Products[0].Product.ID
+	var updated struct {
+		UpdateProduct struct {
+			Categories []category `json:"categories"`
+		} `json:"updateProduct"`
+	}
+	query(t, url, token, `mutation($id: String!, $category: String!) { updateProduct(id: $id, product: {categoryIds: [$category]}) { categories { id path } } }`,
+		map[string]any{"id": pid, "category": shoes.CreateCategory.ID}, &updated)
+	if len(updated.UpdateProduct.Categories) != 1 || updated.UpdateProduct.Categories[0].Path != "outdoors/trail-shoes" {
+		t.Fatalf("expected the product to be filed under trail shoes, got %+v", updated.UpdateProduct.Categories)
+	}
+
+	var inCategory struct {
+		Products []struct {
+			Product struct {
+				ID         string     `json:"id"`
+				Categories []category `json:"categories"`
+			} `json:"product"`
+		} `json:"products"`
+		Categories []category `json:"categories"`
+	}
+	query(t, url, "", `query($category: String!) { products(category: $category) { product { id categories { path } } } categories { path } }`,
+		map[string]any{"category": outdoors.CreateCategory.ID}, &inCategory)
+	if len(inCategory.Products) != 1 || inCategory.Products[0].Product.ID != pid {
+		t.Errorf("expected the product to be listed under outdoors, got %+v", inCategory.Products)
+	}
+	if len(inCategory.Categories) != 2 || inCategory.Categories[0].Path != "outdoors" {
+		t.Errorf("expected the category tree, got %+v", inCategory.Categories)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/allinone"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/metrics"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/order"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	Port int `envconfig:"PORT" default:"8080"`
+	// ShutdownTimeout is how long requests in flight get to finish on SIGTERM.
+	ShutdownTimeout time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"10s"`
+	// MetricsPort serves /metrics for Prometheus.
+	MetricsPort int `envconfig:"METRICS_PORT" default:"9080"`
+	// The DatabaseURLs are those of the service binaries. The default,
+	// memory://, keeps everything in memory.
+	AccountDatabaseURL   string `envconfig:"ACCOUNT_DATABASE_URL" default:"memory://"`
+	CatalogDatabaseURL   string `envconfig:"CATALOG_DATABASE_URL" default:"memory://"`
+	InventoryDatabaseURL string `envconfig:"INVENTORY_DATABASE_URL" default:"memory://"`
+	OrderDatabaseURL     string `envconfig:"ORDER_DATABASE_URL" default:"memory://"`
+	// Seed adds demo accounts, products and stock on start.
+	Seed bool `envconfig:"SEED" default:"true"`
+	// JWTSecret signs the access tokens. A random one is used if not set,
+	// so tokens are only valid until the process exits.
+	JWTSecret string `envconfig:"JWT_SECRET"`
+	// CancellableUntil is the furthest order status that can still be cancelled.
+	CancellableUntil string `envconfig:"ORDER_CANCELLABLE_UNTIL" default:"paid"`
+	// TraceExporter is where spans are sent: "otlp", "stdout" or "none".
+	TraceExporter string `envconfig:"OTEL_TRACES_EXPORTER" default:"none"`
+}
+
+func main() {
+	var cfg Config
+	err := envconfig.Process("", &cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	secret := []byte(cfg.JWTSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, auth.MinSecretLength)
+		rand.Read(secret)
+	}
+	tokens, err := auth.NewSigner(secret)
+	if err != nil {
+		log.Fatalf("invalid JWT_SECRET: %v", err)
+	}
+	cancellableUntil := order.OrderStatus(cfg.CancellableUntil)
+	if !cancellableUntil.Valid() {
+		log.Fatalf("invalid ORDER_CANCELLABLE_UNTIL: %q", cfg.CancellableUntil)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, "allinone", cfg.TraceExporter)
+	if err != nil {
+		log.Fatalf("invalid OTEL_TRACES_EXPORTER: %v", err)
+	}
+
+	go func() {
+		if err := metrics.ListenAndServe(ctx, cfg.MetricsPort); err != nil {
+			log.Println("metrics:", err)
+		}
+	}()
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Port))
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Serving every service on :%d, playground at http://localhost:%d/playground", cfg.Port, cfg.Port)
+	err = allinone.Run(ctx, allinone.Config{
+		AccountDatabaseURL:   cfg.AccountDatabaseURL,
+		CatalogDatabaseURL:   cfg.CatalogDatabaseURL,
+		InventoryDatabaseURL: cfg.InventoryDatabaseURL,
+		OrderDatabaseURL:     cfg.OrderDatabaseURL,
+		Tokens:               tokens,
+		Seed:                 cfg.Seed,
+		CancellableUntil:     cancellableUntil,
+		DrainTimeout:         cfg.ShutdownTimeout,
+	}, lis)
+	shutdownTracing(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,82 @@
+package allinone
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/account"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+)
+
+// SeedPassword is the password of the seeded accounts.
+const SeedPassword = "password"
+
+// seedAccounts can log in with SeedPassword, one per role.
+var seedAccounts = []struct {
+	name, email string
+	role        auth.Role
+}{
+	{"Admin", "admin@example.com", auth.RoleAdmin},
+	{"Merchant", "merchant@example.com", auth.RoleMerchant},
+	{"Customer", "customer@example.com", auth.RoleCustomer},
+}
+
+// seedProducts are stocked with seedStock units each.
+var seedProducts = []struct {
+	name, description string
+	price             money.Money
+}{
+	{"Trail running shoes", "Light shoes with a grippy sole for muddy paths", money.New(8999, "USD")},
+	{"Rain jacket", "Packable waterproof jacket with taped seams", money.New(12000, "USD")},
+	{"Merino t-shirt", "Soft wool t-shirt that doesn't hold odours", money.New(4500, "USD")},
+	{"Water bottle", "750ml insulated steel bottle", money.New(2499, "USD")},
+	{"Headlamp", "Rechargeable 400 lumen headlamp", money.New(3950, "USD")},
+	{"Running socks", "Pack of three cushioned socks", money.New(1800, "USD")},
+}
+
+const seedStock = 100
+
+// seed adds the demo accounts missing from accounts, and the demo products
+// with their stock if the catalog is empty.
+func seed(ctx context.Context, accounts account.Service, products catalog.Service, stock inventory.Service) error {
+	for _, a := range seedAccounts {
+		created, _, err := accounts.Register(ctx, a.name, a.email, SeedPassword)
+		if errors.Is(err, account.ErrEmailTaken) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := accounts.SetRole(ctx, created.ID, a.role); err != nil {
+			return err
+		}
+		log.Printf("seeded %s account %s with password %q", a.role, a.email, SeedPassword)
+	}
+
+	existing, err := products.GetProducts(ctx, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(existing) != 0 {
+		return nil
+	}
+	pids := make([]string, 0, len(seedProducts))
+	deltas := make([]int32, 0, len(seedProducts))
+	for _, p := range seedProducts {
+		created, err := products.PostProduct(ctx, p.name, p.description, p.price, nil)
+		if err != nil {
+			return err
+		}
+		pids = append(pids, created.ID)
+		deltas = append(deltas, seedStock)
+	}
+	if _, err := stock.UpdateStock(ctx, pids, deltas, ""); err != nil {
+		return err
+	}
+	log.Printf("seeded %d products with %d units each", len(pids), seedStock)
+	return nil
+}
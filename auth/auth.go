@@ -0,0 +1,217 @@
+// Package auth issues and verifies the signed tokens that identify an
+// account to the gateway and the services.
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	DefaultAccessTTL  = 15 * time.Minute
+	DefaultRefreshTTL = 30 * 24 * time.Hour
+	// MinSecretLength is the shortest signing secret NewSigner accepts.
+	MinSecretLength = 32
+
+	issuer = "go-microservice-graphql-grpc"
+)
+
+// TokenKind tells access tokens, sent with every request, from refresh
+// tokens, which are only good for getting a new pair.
+type TokenKind string
+
+const (
+	AccessToken  TokenKind = "access"
+	RefreshToken TokenKind = "refresh"
+)
+
+// Role is what an account is allowed to do. Each role includes the ones
+// before it: customers place orders, merchants also manage the catalog,
+// stock and order fulfilment, and admins can do everything.
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleMerchant Role = "merchant"
+	RoleAdmin    Role = "admin"
+	// RoleService is held by the services when they call each other. It
+	// is never assigned to an account and is trusted like an admin.
+	RoleService Role = "service"
+)
+
+var roleRank = map[Role]int{
+	RoleCustomer: 1,
+	RoleMerchant: 2,
+	RoleAdmin:    3,
+	RoleService:  3,
+}
+
+// Valid reports whether r can be assigned to an account.
+func (r Role) Valid() bool {
+	return r == RoleCustomer || r == RoleMerchant || r == RoleAdmin
+}
+
+// Includes reports whether r grants everything other does.
+func (r Role) Includes(other Role) bool {
+	return roleRank[r] != 0 && roleRank[r] >= roleRank[other]
+}
+
+var (
+	ErrInvalidToken = errors.New("invalid or expired token")
+	ErrShortSecret  = errors.New("token secret is too short")
+)
+
+type Claims struct {
+	Kind TokenKind `json:"kind"`
+	Role Role      `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Identity returns the caller the claims were issued to. Tokens issued
+// before roles existed belong to customers.
+func (c *Claims) Identity() Identity {
+	role := c.Role
+	if role == "" {
+		role = RoleCustomer
+	}
+	return Identity{AccountID: c.Subject, Role: role}
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresAt is when AccessToken stops being accepted.
+	ExpiresAt time.Time
+}
+
+// Signer issues and verifies HMAC-SHA256 signed tokens. Every service that
+// verifies tokens must be configured with the same secret.
+type Signer struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+type SignerOption func(*Signer)
+
+func WithAccessTTL(d time.Duration) SignerOption {
+	return func(s *Signer) {
+		s.accessTTL = d
+	}
+}
+
+func WithRefreshTTL(d time.Duration) SignerOption {
+	return func(s *Signer) {
+		s.refreshTTL = d
+	}
+}
+
+func NewSigner(secret []byte, opts ...SignerOption) (*Signer, error) {
+	if len(secret) < MinSecretLength {
+		return nil, ErrShortSecret
+	}
+
+	s := &Signer{
+		secret:     secret,
+		accessTTL:  DefaultAccessTTL,
+		refreshTTL: DefaultRefreshTTL,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Issue returns a new access and refresh token for accountID acting as role.
+func (s *Signer) Issue(accountID string, role Role) (*TokenPair, error) {
+	now := s.now().UTC()
+	access, err := s.sign(accountID, role, AccessToken, now, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := s.sign(accountID, role, RefreshToken, now, s.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresAt:    now.Add(s.accessTTL),
+	}, nil
+}
+
+func (s *Signer) sign(subject string, role Role, kind TokenKind, now time.Time, ttl time.Duration) (string, error) {
+	claims := Claims{
+		Kind: kind,
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+// Verify checks the signature, expiry and kind of token and returns its claims.
+func (s *Signer) Verify(token string, kind TokenKind) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(
+		token,
+		claims,
+		func(*jwt.Token) (any, error) { return s.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if err != nil || claims.Kind != kind || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	AccountID string
+	Role      Role
+}
+
+// Owns reports whether the caller may act on the resources of accountID:
+// its own, or anyone's for admins.
+func (id Identity) Owns(accountID string) bool {
+	return id.AccountID == accountID || id.Role.Includes(RoleAdmin)
+}
+
+type identityKey struct{}
+
+func NewContext(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the caller stored by NewContext, if any.
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+type tokenKey struct{}
+
+// NewTokenContext stores the access token the caller authenticated with,
+// so that calls made on its behalf can forward it.
+func NewTokenContext(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+func TokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(tokenKey{}).(string)
+	return token, ok
+}
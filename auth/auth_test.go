@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testSecret = []byte(strings.Repeat("s", MinSecretLength))
+
+func TestSigner_IssueAndVerify(t *testing.T) {
+	s, err := NewSigner(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pair, err := s.Issue("acc1", RoleMerchant)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := s.Verify(pair.AccessToken, AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := claims.Identity(); id.AccountID != "acc1" || id.Role != RoleMerchant {
+		t.Errorf("expected acc1, got %+v", claims.Identity())
+	}
+	if _, err := s.Verify(pair.RefreshToken, RefreshToken); err != nil {
+		t.Errorf("expected refresh token to verify, got %v", err)
+	}
+
+	// Tokens are only good for what they were issued for.
+	if _, err := s.Verify(pair.RefreshToken, AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected refresh token to be rejected as access token, got %v", err)
+	}
+	if _, err := s.Verify(pair.AccessToken, RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected access token to be rejected as refresh token, got %v", err)
+	}
+}
+
+func TestSigner_RejectsBadTokens(t *testing.T) {
+	s, _ := NewSigner(testSecret)
+	pair, _ := s.Issue("acc1", RoleCustomer)
+
+	other, _ := NewSigner([]byte(strings.Repeat("o", MinSecretLength)))
+	if _, err := other.Verify(pair.AccessToken, AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected token signed with another secret to be rejected, got %v", err)
+	}
+
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{
+		Kind:             AccessToken,
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: issuer, Subject: "acc1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if _, err := s.Verify(unsigned, AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected unsigned token to be rejected, got %v", err)
+	}
+
+	s.now = func() time.Time { return time.Now().Add(DefaultAccessTTL + time.Minute) }
+	if _, err := s.Verify(pair.AccessToken, AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected expired token to be rejected, got %v", err)
+	}
+}
+
+func TestNewSigner_ShortSecret(t *testing.T) {
+	if _, err := NewSigner([]byte("short")); !errors.Is(err, ErrShortSecret) {
+		t.Errorf("expected ErrShortSecret, got %v", err)
+	}
+}
+
+func TestRole_Includes(t *testing.T) {
+	testCases := []struct {
+		role, other Role
+		want        bool
+	}{
+		{RoleCustomer, RoleCustomer, true},
+		{RoleCustomer, RoleMerchant, false},
+		{RoleMerchant, RoleCustomer, true},
+		{RoleAdmin, RoleMerchant, true},
+		{RoleMerchant, RoleAdmin, false},
+		{RoleService, RoleAdmin, true},
+		{"", RoleCustomer, false},
+		{"owner", RoleCustomer, false},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.role.Includes(tc.other); got != tc.want {
+			t.Errorf("%q.Includes(%q) = %v, want %v", tc.role, tc.other, got, tc.want)
+		}
+	}
+}
+
+func TestClaims_IdentityWithoutRole(t *testing.T) {
+	c := &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "acc1"}}
+	if id := c.Identity(); id.Role != RoleCustomer {
+		t.Errorf("expected tokens without a role to belong to customers, got %+v", id)
+	}
+}
+
+func TestContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("expected no identity")
+	}
+	ctx := NewContext(context.Background(), Identity{AccountID: "acc1"})
+	if id, ok := FromContext(ctx); !ok || id.AccountID != "acc1" {
+		t.Errorf("expected acc1, got %+v", id)
+	}
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	s, _ := NewSigner(testSecret)
+	customer, _ := s.Issue("acc1", RoleCustomer)
+	merchant, _ := s.Issue("acc2", RoleMerchant)
+	service, err := ServiceCredentials(s, "order").GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	interceptor := UnaryServerInterceptor(s, map[string]Role{"/pb.Svc/Write": RoleMerchant})
+
+	testCases := []struct {
+		name     string
+		method   string
+		header   string
+		wantCode codes.Code
+		wantID   string
+	}{
+		{name: "anonymous open method", method: "/pb.Svc/Read", wantCode: codes.OK},
+		{name: "authenticated open method", method: "/pb.Svc/Read", header: "Bearer " + customer.AccessToken, wantCode: codes.OK, wantID: "acc1"},
+		{name: "anonymous restricted method", method: "/pb.Svc/Write", wantCode: codes.Unauthenticated},
+		{name: "role too low", method: "/pb.Svc/Write", header: "Bearer " + customer.AccessToken, wantCode: codes.PermissionDenied},
+		{name: "role high enough", method: "/pb.Svc/Write", header: "Bearer " + merchant.AccessToken, wantCode: codes.OK, wantID: "acc2"},
+		{name: "service", method: "/pb.Svc/Write", header: service[metadataKey], wantCode: codes.OK, wantID: "service:order"},
+		{name: "refresh token", method: "/pb.Svc/Read", header: "Bearer " + customer.RefreshToken, wantCode: codes.Unauthenticated},
+		{name: "not a bearer token", method: "/pb.Svc/Read", header: customer.AccessToken, wantCode: codes.Unauthenticated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.header != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(metadataKey, tc.header))
+			}
+
+			var gotID string
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, func(ctx context.Context, _ any) (any, error) {
+				id, _ := FromContext(ctx)
+				gotID = id.AccountID
+				return nil, nil
+			})
+			if code := status.Code(err); code != tc.wantCode {
+				t.Fatalf("expected %v, got %v", tc.wantCode, err)
+			}
+			if gotID != tc.wantID {
+				t.Errorf("expected identity %q, got %q", tc.wantID, gotID)
+			}
+		})
+	}
+}
+
+func TestForwardCredentials(t *testing.T) {
+	creds := ForwardCredentials()
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	if err != nil || len(md) != 0 {
+		t.Errorf("expected no metadata without a token, got %v, %v", md, err)
+	}
+
+	md, err = creds.GetRequestMetadata(NewTokenContext(context.Background(), "tok"))
+	if err != nil || md[metadataKey] != "Bearer tok" {
+		t.Errorf("expected token to be forwarded, got %v, %v", md, err)
+	}
+}
+
+func TestServiceCredentials_ReusesToken(t *testing.T) {
+	s, _ := NewSigner(testSecret)
+	creds := ServiceCredentials(s, "order")
+
+	first, _ := creds.GetRequestMetadata(context.Background())
+	second, _ := creds.GetRequestMetadata(context.Background())
+	if first[metadataKey] != second[metadataKey] {
+		t.Error("expected the token to be reused until it is about to expire")
+	}
+}
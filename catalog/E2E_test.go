@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	moneypb "github.com/RathodViraj/go-microservice-graphql-grpc/money/pb"
+
 	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog/pb"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
 	inventorypb "github.com/RathodViraj/go-microservice-graphql-grpc/inventory/pb"
@@ -49,13 +51,13 @@ func (s *fakeInventoryServerE2E) CheckStock(ctx context.Context, r *inventorypb.
 
 func startE2EServer(t *testing.T) (string, func()) {
 	url := os.Getenv("ELASTICSEARCH_URL_FOR_TEST")
-	if url == "" {
-		url = "http://localhost:9200"
-	}
 
-	repo, err := NewElasticRepository(url)
-	if err != nil {
-		t.Fatalf("failed to connect to Elasticsearch: %v", err)
+	repo := NewMemoryRepository()
+	if url != "" && url != "memory://" {
+		var err error
+		if repo, err = NewElasticRepository(url); err != nil {
+			t.Fatalf("failed to connect to Elasticsearch: %v", err)
+		}
 	}
 
 	svc := NewSerivce(repo)
@@ -108,7 +110,7 @@ func TestE2E_PostAndGetProduct(t *testing.T) {
 	postRes, err := client.PostProduct(ctx, &pb.PostProductRequest{
 		Name:        "E2E Test Laptop",
 		Description: "A laptop for end-to-end testing",
-		Price:       1299.99,
+		Price:       &moneypb.Money{Amount: 129999, Currency: "USD"},
 	})
 	if err != nil {
 		t.Fatalf("post failed: %v", err)
@@ -127,8 +129,8 @@ func TestE2E_PostAndGetProduct(t *testing.T) {
 		t.Errorf("unexpected name, got %s", getRes.Product.Product.Name)
 	}
 
-	if getRes.Product.Product.Price != 1299.99 {
-		t.Errorf("unexpected price, got %f", getRes.Product.Product.Price)
+	if getRes.Product.Product.Price.GetAmount() != 129999 {
+		t.Errorf("unexpected price, got %v", getRes.Product.Product.Price)
 	}
 }
 
@@ -153,7 +155,7 @@ func TestE2E_GetProducts(t *testing.T) {
 	_, err = client.PostProduct(ctx, &pb.PostProductRequest{
 		Name:        "E2E Product Alpha",
 		Description: "First product",
-		Price:       10.00,
+		Price:       &moneypb.Money{Amount: 1000, Currency: "USD"},
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -162,7 +164,7 @@ func TestE2E_GetProducts(t *testing.T) {
 	_, err = client.PostProduct(ctx, &pb.PostProductRequest{
 		Name:        "E2E Product Beta",
 		Description: "Second product",
-		Price:       20.00,
+		Price:       &moneypb.Money{Amount: 2000, Currency: "USD"},
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -171,7 +173,7 @@ func TestE2E_GetProducts(t *testing.T) {
 	_, err = client.PostProduct(ctx, &pb.PostProductRequest{
 		Name:        "E2E Product Gamma",
 		Description: "Third product",
-		Price:       30.00,
+		Price:       &moneypb.Money{Amount: 3000, Currency: "USD"},
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -208,7 +210,7 @@ func TestE2E_SearchProducts(t *testing.T) {
 	_, err = client.PostProduct(ctx, &pb.PostProductRequest{
 		Name:        "Wireless Mechanical Keyboard",
 		Description: "RGB backlit mechanical keyboard",
-		Price:       89.99,
+		Price:       &moneypb.Money{Amount: 8999, Currency: "USD"},
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -263,7 +265,7 @@ func TestE2E_GetProductsByIds(t *testing.T) {
 	res1, err := client.PostProduct(ctx, &pb.PostProductRequest{
 		Name:        "Monitor",
 		Description: "4K monitor",
-		Price:       399.99,
+		Price:       &moneypb.Money{Amount: 39999, Currency: "USD"},
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -272,7 +274,7 @@ func TestE2E_GetProductsByIds(t *testing.T) {
 	res2, err := client.PostProduct(ctx, &pb.PostProductRequest{
 		Name:        "Mouse",
 		Description: "Wireless mouse",
-		Price:       29.99,
+		Price:       &moneypb.Money{Amount: 2999, Currency: "USD"},
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -0,0 +1,133 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryExists   = errors.New("category already exists")
+	// ErrInvalidSlug is returned for slugs other than lowercase words of
+	// letters and digits joined by hyphens.
+	ErrInvalidSlug = errors.New("invalid category slug")
+)
+
+// Category is a node of the category tree products are filed under.
+// Categories can only be added: renaming, moving and deleting them are out
+// of scope for now, as each would change the paths of a whole subtree.
+type Category struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Slug names the category in URLs. It is unique among its siblings.
+	Slug string `json:"slug"`
+	// ParentID is empty for top-level categories.
+	ParentID string `json:"parent_id"`
+	// Path joins the slugs from the root down to the category, e.g.
+	// "clothing/shoes/running", so it is unique.
+	Path string `json:"path"`
+}
+
+// contains reports whether other is c or a category below it.
+func (c Category) contains(other Category) bool {
+	return other.Path == c.Path || strings.HasPrefix(other.Path, c.Path+"/")
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// slugify turns name into a slug, e.g. "Running & Trail" into
+// "running-trail".
+func slugify(name string) string {
+	return strings.Join(tokenize(name), "-")
+}
+
+func (s *catalogService) PostCategory(ctx context.Context, name, slug, parentID string) (*Category, error) {
+	if slug == "" {
+		slug = slugify(name)
+	}
+	if !slugPattern.MatchString(slug) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSlug, slug)
+	}
+
+	categories, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := &Category{
+		ID:       ksuid.New().String(),
+		Name:     name,
+		Slug:     slug,
+		ParentID: parentID,
+		Path:     slug,
+	}
+	if parentID != "" {
+		i := slices.IndexFunc(categories, func(c Category) bool { return c.ID == parentID })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: parent %s", ErrCategoryNotFound, parentID)
+		}
+		c.Path = categories[i].Path + "/" + slug
+	}
+
+	// The repository refuses a second category with the same path, also
+	// when both are created at once.
+	if err := s.repository.PutCategory(ctx, *c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *catalogService) GetCategories(ctx context.Context) ([]Category, error) {
+	categories, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(categories, func(a, b Category) int { return strings.Compare(a.Path, b.Path) })
+	return categories, nil
+}
+
+func (s *catalogService) GetProductsInCategory(ctx context.Context, categoryID, query string, skip, take uint64) ([]Product, error) {
+	if take > 100 || (skip == 0 && take == 0) {
+		take = 100
+	}
+
+	categories, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(categories, func(c Category) bool { return c.ID == categoryID })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, categoryID)
+	}
+	ids := []string{}
+	for _, c := range categories {
+		if categories[i].contains(c) {
+			ids = append(ids, c.ID)
+		}
+	}
+
+	return s.repository.ListProductsInCategories(ctx, ids, query, skip, take)
+}
+
+// checkCategories fails with ErrCategoryNotFound unless every one of ids is
+// a category.
+func (s *catalogService) checkCategories(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	categories, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if !slices.ContainsFunc(categories, func(c Category) bool { return c.ID == id }) {
+			return fmt.Errorf("%w: %s", ErrCategoryNotFound, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+)
+
+func TestService_PostCategory(t *testing.T) {
+	ctx := context.Background()
+	svc := NewSerivce(NewMemoryRepository())
+
+	clothing, err := svc.PostCategory(ctx, "Clothing", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shoes, err := svc.PostCategory(ctx, "Running & Trail Shoes", "", clothing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shoes.Slug != "running-trail-shoes" || shoes.Path != "clothing/running-trail-shoes" || shoes.ParentID != clothing.ID {
+		t.Errorf("unexpected category: %+v", shoes)
+	}
+
+	if _, err := svc.PostCategory(ctx, "Shoes", "running-trail-shoes", clothing.ID); !errors.Is(err, ErrCategoryExists) {
+		t.Errorf("expected ErrCategoryExists, got %v", err)
+	}
+	if _, err := svc.PostCategory(ctx, "Shoes", "running-trail-shoes", ""); err != nil {
+		t.Errorf("expected the same slug to be allowed under another parent, got %v", err)
+	}
+	if _, err := svc.PostCategory(ctx, "Shoes", "", "c9"); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("expected ErrCategoryNotFound, got %v", err)
+	}
+	if _, err := svc.PostCategory(ctx, "Shoes", "Shoes/Boots", ""); !errors.Is(err, ErrInvalidSlug) {
+		t.Errorf("expected ErrInvalidSlug, got %v", err)
+	}
+
+	categories, err := svc.GetCategories(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{}
+	for _, c := range categories {
+		paths = append(paths, c.Path)
+	}
+	if len(paths) != 3 || paths[0] != "clothing" || paths[1] != "clothing/running-trail-shoes" || paths[2] != "running-trail-shoes" {
+		t.Errorf("expected the categories ordered by path, got %v", paths)
+	}
+}
+
+func TestService_GetProductsInCategory_IncludesDescendants(t *testing.T) {
+	ctx := context.Background()
+	svc := NewSerivce(NewMemoryRepository())
+
+	clothing, _ := svc.PostCategory(ctx, "Clothing", "", "")
+	shoes, _ := svc.PostCategory(ctx, "Shoes", "", clothing.ID)
+	garden, _ := svc.PostCategory(ctx, "Garden", "", "")
+
+	file := func(name string, categoryIDs ...string) *Product {
+		t.Helper()
+		p, err := svc.PostProduct(ctx, name, "", money.New(100, "USD"), categoryIDs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	shirt := file("Blue shirt", clothing.ID)
+	boots := file("Blue boots", shoes.ID, shoes.ID)
+	file("Blue rake", garden.ID)
+
+	if len(boots.CategoryIDs) != 1 {
+		t.Errorf("expected repeated categories to be stored once, got %v", boots.CategoryIDs)
+	}
+
+	products, err := svc.GetProductsInCategory(ctx, clothing.ID, "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := productIDs(products); len(ids) != 2 || ids[0] != shirt.ID || ids[1] != boots.ID {
+		t.Errorf("expected the products of clothing and shoes, got %v", ids)
+	}
+
+	products, _ = svc.GetProductsInCategory(ctx, clothing.ID, "boots", 0, 0)
+	if ids := productIDs(products); len(ids) != 1 || ids[0] != boots.ID {
+		t.Errorf("expected only the boots to match, got %v", ids)
+	}
+
+	if _, err := svc.GetProductsInCategory(ctx, "c9", "", 0, 0); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("expected ErrCategoryNotFound, got %v", err)
+	}
+	unknown := []string{"c9"}
+	if _, err := svc.UpdateProduct(ctx, shirt.ID, "", ProductUpdate{CategoryIDs: &unknown}); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("expected filing under an unknown category to fail, got %v", err)
+	}
+	if _, err := svc.PostProduct(ctx, "Red rake", "", money.New(100, "USD"), []string{garden.ID, "c9"}); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("expected creating a product in an unknown category to fail, got %v", err)
+	}
+	if products, _ := svc.GetProductsInCategory(ctx, garden.ID, "red", 0, 0); len(products) != 0 {
+		t.Errorf("expected the product not to be created, got %v", productIDs(products))
+	}
+}
+
+func TestService_PostCategory_Concurrently(t *testing.T) {
+	ctx := context.Background()
+	svc := NewSerivce(NewMemoryRepository())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Go(func() {
+			_, err := svc.PostCategory(ctx, "Shoes", "", "")
+			errs <- err
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, ErrCategoryExists):
+			t.Errorf("expected ErrCategoryExists, got %v", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("expected one category to be created, got %d", created)
+	}
+}
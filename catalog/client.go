@@ -5,8 +5,14 @@ import (
 	"fmt"
 
 	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/health"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/middleware"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/resilience"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type ProductResponse struct {
@@ -19,8 +25,12 @@ type Client struct {
 	Service pb.CatalogServiceClient
 }
 
-func NewClient(url string) (*Client, error) {
-	conn, err := grpc.NewClient(url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// NewClient connects to the service at url. opts are added to the default
+// dial options, e.g. to authenticate calls. Connections are plaintext
+// unless opts set transport credentials, such as tlsconfig's.
+func NewClient(url string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DialOption(), tracing.DialOption(), resilience.DialOption()}, opts...)
+	conn, err := grpc.NewClient(url, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create catalog client: %w", err)
 	}
@@ -33,13 +43,19 @@ func (c *Client) Close() {
 	c.Conn.Close()
 }
 
-func (c *Client) PostProduct(ctx context.Context, name, description string, price float64) (*Product, error) {
+// Ping reports whether the catalog service is serving.
+func (c *Client) Ping(ctx context.Context) error {
+	return health.Conn(c.Conn)(ctx)
+}
+
+func (c *Client) PostProduct(ctx context.Context, name, description string, price money.Money, categoryIDs []string) (*Product, error) {
 	res, err := c.Service.PostProduct(
 		ctx,
 		&pb.PostProductRequest{
 			Name:        name,
 			Description: description,
-			Price:       price,
+			Price:       price.Proto(),
+			CategoryIds: categoryIDs,
 		},
 	)
 
@@ -47,12 +63,7 @@ func (c *Client) PostProduct(ctx context.Context, name, description string, pric
 		return nil, err
 	}
 
-	return &Product{
-		ID:          res.Product.Id,
-		Name:        res.Product.Name,
-		Description: res.Product.Description,
-		Price:       res.Product.Price,
-	}, nil
+	return productFromProto(res.Product), nil
 }
 
 func (c *Client) GetProduct(ctx context.Context, id string) (*ProductResponse, error) {
@@ -61,18 +72,14 @@ func (c *Client) GetProduct(ctx context.Context, id string) (*ProductResponse, e
 		&pb.GetProductRequest{
 			Id: id,
 		},
+		resilience.Idempotent(),
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ProductResponse{
-		Product: &Product{
-			ID:          res.Product.Product.Id,
-			Name:        res.Product.Product.Name,
-			Description: res.Product.Product.Description,
-			Price:       res.Product.Product.Price,
-		},
+		Product:  productFromProto(res.Product.Product),
 		Quantity: res.Product.Quntity,
 	}, nil
 
@@ -87,6 +94,7 @@ func (c *Client) GetProducts(ctx context.Context, skip, take uint64, ids []strin
 			Ids:   ids,
 			Query: query,
 		},
+		resilience.Idempotent(),
 	)
 	if err != nil {
 		return nil, err
@@ -97,12 +105,7 @@ func (c *Client) GetProducts(ctx context.Context, skip, take uint64, ids []strin
 		products = append(
 			products,
 			ProductResponse{
-				Product: &Product{
-					ID:          p.Product.Id,
-					Name:        p.Product.Name,
-					Description: p.Product.Description,
-					Price:       p.Product.Price,
-				},
+				Product:  productFromProto(p.Product),
 				Quantity: p.Quntity,
 			},
 		)
@@ -110,3 +113,121 @@ func (c *Client) GetProducts(ctx context.Context, skip, take uint64, ids []strin
 
 	return products, nil
 }
+
+// UpdateProduct changes the fields set in update. Unless version is empty,
+// the call fails with codes.Aborted if the product is no longer at version.
+func (c *Client) UpdateProduct(ctx context.Context, id, version string, update ProductUpdate) (*Product, error) {
+	product := &pb.Product{Id: id, Version: version}
+	mask := &fieldmaskpb.FieldMask{}
+	if update.Name != nil {
+		product.Name = *update.Name
+		mask.Paths = append(mask.Paths, "name")
+	}
+	if update.Description != nil {
+		product.Description = *update.Description
+		mask.Paths = append(mask.Paths, "description")
+	}
+	if update.Price != nil {
+		product.Price = update.Price.Proto()
+		mask.Paths = append(mask.Paths, "price")
+	}
+	if update.CategoryIDs != nil {
+		product.CategoryIds = *update.CategoryIDs
+		mask.Paths = append(mask.Paths, "category_ids")
+	}
+
+	res, err := c.Service.UpdateProduct(ctx, &pb.UpdateProductRequest{Product: product, UpdateMask: mask})
+	if err != nil {
+		return nil, err
+	}
+
+	return productFromProto(res.Product), nil
+}
+
+// DeleteProduct hides the product from listings and searches. Unless
+// version is empty, the call fails with codes.Aborted if the product is no
+// longer at version.
+func (c *Client) DeleteProduct(ctx context.Context, id, version string) (*Product, error) {
+	res, err := c.Service.DeleteProduct(ctx, &pb.DeleteProductRequest{Id: id, Version: version})
+	if err != nil {
+		return nil, err
+	}
+
+	return productFromProto(res.Product), nil
+}
+
+func productFromProto(p *pb.Product) *Product {
+	return &Product{
+		ID:          p.Id,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       money.FromProto(p.Price),
+		Version:     p.Version,
+		Deleted:     p.Deleted,
+		CategoryIDs: p.CategoryIds,
+	}
+}
+
+// GetProductsInCategory lists the products in the category or any category
+// below it, only those matching query unless it is empty.
+func (c *Client) GetProductsInCategory(ctx context.Context, categoryID, query string, skip, take uint64) ([]ProductResponse, error) {
+	res, err := c.Service.GetProducts(
+		ctx,
+		&pb.GetProductsRequest{
+			Skip:       skip,
+			Take:       take,
+			Query:      query,
+			CategoryId: categoryID,
+		},
+		resilience.Idempotent(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []ProductResponse
+	for _, p := range res.Products {
+		products = append(products, ProductResponse{
+			Product:  productFromProto(p.Product),
+			Quantity: p.Quntity,
+		})
+	}
+
+	return products, nil
+}
+
+// PostCategory adds a category below parentID, or at the top of the tree if
+// parentID is empty. The slug is derived from name if empty.
+func (c *Client) PostCategory(ctx context.Context, name, slug, parentID string) (*Category, error) {
+	res, err := c.Service.PostCategory(ctx, &pb.PostCategoryRequest{Name: name, Slug: slug, ParentId: parentID})
+	if err != nil {
+		return nil, err
+	}
+
+	return categoryFromProto(res.Category), nil
+}
+
+// GetCategories returns the whole category tree, ordered by path so every
+// category comes after its parent.
+func (c *Client) GetCategories(ctx context.Context) ([]Category, error) {
+	res, err := c.Service.GetCategories(ctx, &pb.GetCategoriesRequest{}, resilience.Idempotent())
+	if err != nil {
+		return nil, err
+	}
+
+	categories := []Category{}
+	for _, category := range res.Categories {
+		categories = append(categories, *categoryFromProto(category))
+	}
+	return categories, nil
+}
+
+func categoryFromProto(c *pb.Category) *Category {
+	return &Category{
+		ID:       c.Id,
+		Name:     c.Name,
+		Slug:     c.Slug,
+		ParentID: c.ParentId,
+		Path:     c.Path,
+	}
+}
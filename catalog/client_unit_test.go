@@ -4,7 +4,10 @@ import (
 	"context"
 	"testing"
 
+	moneypb "github.com/RathodViraj/go-microservice-graphql-grpc/money/pb"
+
 	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"go.uber.org/mock/gomock"
 )
 
@@ -15,15 +18,15 @@ func TestClient_PostProduct(t *testing.T) {
 	mockPB := NewMockCatalogServiceClient(ctrl)
 
 	mockPB.EXPECT().
-		PostProduct(gomock.Any(), &pb.PostProductRequest{Name: "product", Description: "test product", Price: 3.23}).
-		Return(&pb.PostProductResponse{Product: &pb.Product{Id: "p1", Name: "product", Description: "test product", Price: 3.23}}, nil)
+		PostProduct(gomock.Any(), &pb.PostProductRequest{Name: "product", Description: "test product", Price: &moneypb.Money{Amount: 323, Currency: "USD"}}).
+		Return(&pb.PostProductResponse{Product: &pb.Product{Id: "p1", Name: "product", Description: "test product", Price: &moneypb.Money{Amount: 323, Currency: "USD"}}}, nil)
 
 	c := &Client{
 		Conn:    nil,
 		Service: mockPB,
 	}
 
-	_, err := c.PostProduct(context.Background(), "product", "test product", 3.23)
+	_, err := c.PostProduct(context.Background(), "product", "test product", money.New(323, "USD"), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -34,8 +37,8 @@ func TestClient_GetProduct(t *testing.T) {
 	defer ctrl.Finish()
 	mockPB := NewMockCatalogServiceClient(ctrl)
 	mockPB.EXPECT().
-		GetProduct(gomock.Any(), &pb.GetProductRequest{Id: "p1"}).
-		Return(&pb.GetProductResponse{Product: &pb.ProductInResponse{Product: &pb.Product{Id: "p1", Name: "product", Description: "test product", Price: 3.23}, Quntity: 1}}, nil)
+		GetProduct(gomock.Any(), &pb.GetProductRequest{Id: "p1"}, gomock.Any()).
+		Return(&pb.GetProductResponse{Product: &pb.ProductInResponse{Product: &pb.Product{Id: "p1", Name: "product", Description: "test product", Price: &moneypb.Money{Amount: 323, Currency: "USD"}}, Quntity: 1}}, nil)
 	c := &Client{Service: mockPB}
 
 	_, err := c.GetProduct(context.Background(), "p1")
@@ -49,10 +52,10 @@ func TestClient_ListProducts(t *testing.T) {
 	defer ctrl.Finish()
 	mockPB := NewMockCatalogServiceClient(ctrl)
 	mockPB.EXPECT().
-		GetProducts(gomock.Any(), &pb.GetProductsRequest{Skip: 0, Take: 2}).
+		GetProducts(gomock.Any(), &pb.GetProductsRequest{Skip: 0, Take: 2}, gomock.Any()).
 		Return(&pb.GetProductsResponse{Products: []*pb.ProductInResponse{
-			{Product: &pb.Product{Id: "p1", Name: "product1", Description: "test product1", Price: 3.23}, Quntity: 1},
-			{Product: &pb.Product{Id: "p2", Name: "product2", Description: "test product2", Price: 4.56}, Quntity: 1},
+			{Product: &pb.Product{Id: "p1", Name: "product1", Description: "test product1", Price: &moneypb.Money{Amount: 323, Currency: "USD"}}, Quntity: 1},
+			{Product: &pb.Product{Id: "p2", Name: "product2", Description: "test product2", Price: &moneypb.Money{Amount: 456, Currency: "USD"}}, Quntity: 1},
 		}}, nil)
 	c := &Client{Service: mockPB}
 
@@ -1,16 +1,53 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/metrics"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/resilience"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/serve"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tlsconfig"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
 	"github.com/kelseyhightower/envconfig"
 )
 
+// memoryURL selects the in-memory repository, for development and tests
+// without a database. Nothing stored survives a restart.
+const memoryURL = "memory://"
+
 type Config struct {
+	Port int `envconfig:"PORT"`
+	// ShutdownTimeout is how long calls in flight get to finish on SIGTERM.
+	ShutdownTimeout time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"10s"`
+	// MetricsPort serves /metrics for Prometheus.
+	MetricsPort int `envconfig:"METRICS_PORT" default:"9082"`
+	// DatabaseURL set to memoryURL keeps the products in memory instead.
 	DatabaseURL  string `envconfig:"DATABASE_URL"`
 	InventoryURL string `envconfig:"INVENTORY_URL"`
+	// JWTSecret verifies the tokens callers authenticate with. It must match
+	// the secret of the account service.
+	JWTSecret string `envconfig:"JWT_SECRET" required:"true"`
+	// TraceExporter is where spans are sent: "otlp", "stdout" or "none".
+	TraceExporter string `envconfig:"OTEL_TRACES_EXPORTER" default:"none"`
+	// TLSCertFile and TLSKeyFile are the certificate presented to callers and
+	// to the services this one calls. With TLSCAFile, the other side must
+	// present a certificate issued by that CA too. Changes to the files are
+	// picked up without a restart.
+	TLSCertFile string `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile  string `envconfig:"TLS_KEY_FILE"`
+	TLSCAFile   string `envconfig:"TLS_CA_FILE"`
+	// ClientTimeout bounds each call to another service. Reads failing
+	// because a service is unavailable are tried up to ClientMaxAttempts
+	// times in all.
+	ClientTimeout     time.Duration `envconfig:"CLIENT_TIMEOUT" default:"5s"`
+	ClientMaxAttempts int           `envconfig:"CLIENT_MAX_ATTEMPTS" default:"3"`
 }
 
 func main() {
@@ -20,7 +57,10 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// Default for local development
+	// Defaults for local development
+	if cfg.Port == 0 {
+		cfg.Port = catalog.DefaultPort
+	}
 	if cfg.DatabaseURL == "" {
 		cfg.DatabaseURL = "http://localhost:9200"
 	}
@@ -28,19 +68,70 @@ func main() {
 		cfg.InventoryURL = "http://localhost:8084"
 	}
 
+	signer, err := auth.NewSigner([]byte(cfg.JWTSecret))
+	if err != nil {
+		log.Fatalf("invalid JWT_SECRET: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, "catalog", cfg.TraceExporter)
+	if err != nil {
+		log.Fatalf("invalid OTEL_TRACES_EXPORTER: %v", err)
+	}
+
+	policy := resilience.DefaultPolicy
+	policy.Timeout, policy.MaxAttempts = cfg.ClientTimeout, cfg.ClientMaxAttempts
+	opts := []serve.Option{
+		serve.WithPort(cfg.Port),
+		serve.WithDrainTimeout(cfg.ShutdownTimeout),
+		serve.WithDialOptions(resilience.WithDefaultPolicy(policy)),
+	}
+	certFiles := tlsconfig.Files{CertFile: cfg.TLSCertFile, KeyFile: cfg.TLSKeyFile, CAFile: cfg.TLSCAFile}
+	if certFiles.Enabled() {
+		if certFiles.CertFile == "" {
+			log.Fatal("TLS_CERT_FILE is required to serve over TLS")
+		}
+		certs, err := tlsconfig.NewReloader(certFiles)
+		if err != nil {
+			log.Fatalf("invalid TLS configuration: %v", err)
+		}
+		go certs.Run(ctx, tlsconfig.DefaultReloadInterval)
+		opts = append(opts, serve.WithServerOptions(certs.ServerOption()), serve.WithDialOptions(certs.DialOption()))
+	}
+
+	go func() {
+		if err := metrics.ListenAndServe(ctx, cfg.MetricsPort); err != nil {
+			log.Println("metrics:", err)
+		}
+	}()
+
 	var r catalog.Repository
-	for {
-		r, err = catalog.NewElasticRepository(cfg.DatabaseURL)
-		if err == nil {
-			break
+	if cfg.DatabaseURL == memoryURL {
+		log.Println("Storing everything in memory, nothing is kept on exit")
+		r = catalog.NewMemoryRepository()
+	} else {
+		for {
+			r, err = catalog.NewElasticRepository(cfg.DatabaseURL)
+			if err == nil {
+				break
+			}
+			log.Println(err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(2 * time.Second):
+			}
 		}
-		log.Println(err)
-		time.Sleep(2 * time.Second)
 	}
-	defer r.Close()
 
-	log.Println("Listening on port 8082...")
+	log.Printf("Listening on port %d...", cfg.Port)
 	s := catalog.NewSerivce(r)
-	log.Fatal(catalog.ListenGRPC(s, cfg.InventoryURL, 8082))
-
+	err = catalog.ListenGRPC(ctx, s, signer, cfg.InventoryURL, opts...)
+	r.Close()
+	shutdownTracing(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
 }
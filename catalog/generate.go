@@ -1,3 +1,3 @@
 package catalog
 
-//go:generate protoc --go_out=./pb --go_opt=paths=source_relative --go-grpc_out=./pb --go-grpc_opt=paths=source_relative ./catalog.proto
+//go:generate protoc -I. -I.. --go_out=./pb --go_opt=paths=source_relative --go-grpc_out=./pb --go-grpc_opt=paths=source_relative ./catalog.proto
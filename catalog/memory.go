@@ -0,0 +1,216 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// memoryRepository keeps products in memory, for development and tests
+// without Elasticsearch. Everything is lost when the process exits.
+type memoryRepository struct {
+	mu       sync.RWMutex
+	products map[string]Product
+	// ids is the order products were first indexed in, which listings and
+	// ties between search results follow.
+	ids []string
+	// writes counts the writes so far, numbering the versions of products.
+	writes     int64
+	categories map[string]Category
+}
+
+func NewMemoryRepository() Repository {
+	return &memoryRepository{products: map[string]Product{}, categories: map[string]Category{}}
+}
+
+func (r *memoryRepository) Close() {}
+
+func (r *memoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryRepository) PutProduct(ctx context.Context, p Product) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[p.ID]; !ok {
+		r.ids = append(r.ids, p.ID)
+	}
+	return r.put(p), nil
+}
+
+func (r *memoryRepository) UpdateProduct(ctx context.Context, p Product) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.products[p.ID]
+	if !ok {
+		return "", ErrNotFound
+	}
+	if stored.Version != p.Version {
+		return "", ErrVersionConflict
+	}
+	return r.put(p), nil
+}
+
+// put stores p at a new version, which it returns. r.mu must be held.
+func (r *memoryRepository) put(p Product) string {
+	r.writes++
+	p.Version = strconv.FormatInt(r.writes, 10)
+	p.CategoryIDs = slices.Clone(p.CategoryIDs)
+	r.products[p.ID] = p
+	return p.Version
+}
+
+func (r *memoryRepository) GetProductByID(ctx context.Context, id string) (*Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.products[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *memoryRepository) ListProducts(ctx context.Context, skip, take uint64) ([]Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return page(r.listed(nil), skip, take), nil
+}
+
+// listed returns the products that aren't deleted, and for which keep
+// returns true unless it is nil, in the order they were first indexed.
+// r.mu must be held.
+func (r *memoryRepository) listed(keep func(Product) bool) []Product {
+	products := make([]Product, 0, len(r.ids))
+	for _, id := range r.ids {
+		if p := r.products[id]; !p.Deleted && (keep == nil || keep(p)) {
+			products = append(products, p)
+		}
+	}
+	return products
+}
+
+// ListProductsWithIDs returns the products found among ids, deleted or not.
+// Unknown IDs are skipped.
+func (r *memoryRepository) ListProductsWithIDs(ctx context.Context, ids []string) ([]Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	products := []Product{}
+	for _, id := range r.ids {
+		if wanted[id] {
+			products = append(products, r.products[id])
+		}
+	}
+	return products, nil
+}
+
+func (r *memoryRepository) SearchProducts(ctx context.Context, query string, skip, take uint64) ([]Product, error) {
+	r.mu.RLock()
+	products := r.listed(nil)
+	r.mu.RUnlock()
+
+	return page(search(products, query), skip, take), nil
+}
+
+func (r *memoryRepository) ListProductsInCategories(ctx context.Context, categoryIDs []string, query string, skip, take uint64) ([]Product, error) {
+	r.mu.RLock()
+	products := r.listed(func(p Product) bool {
+		return slices.ContainsFunc(p.CategoryIDs, func(id string) bool { return slices.Contains(categoryIDs, id) })
+	})
+	r.mu.RUnlock()
+
+	if query != "" {
+		products = search(products, query)
+	}
+	return page(products, skip, take), nil
+}
+
+func (r *memoryRepository) PutCategory(ctx context.Context, c Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, other := range r.categories {
+		if other.Path == c.Path {
+			return fmt.Errorf("%w: %s", ErrCategoryExists, c.Path)
+		}
+	}
+	r.categories[c.ID] = c
+	return nil
+}
+
+func (r *memoryRepository) ListCategories(ctx context.Context) ([]Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := make([]Category, 0, len(r.categories))
+	for _, c := range r.categories {
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+// search approximates the multi_match query of Elasticsearch: products
+// whose name or description contain any word of query match, and those
+// containing more of them come first.
+func search(products []Product, query string) []Product {
+	terms := tokenize(query)
+
+	type hit struct {
+		product Product
+		score   int
+	}
+	hits := []hit{}
+	for _, p := range products {
+		words := map[string]bool{}
+		for _, w := range tokenize(p.Name + " " + p.Description) {
+			words[w] = true
+		}
+		score := 0
+		for _, term := range terms {
+			if words[term] {
+				score++
+			}
+		}
+		if score > 0 {
+			hits = append(hits, hit{p, score})
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	matches := make([]Product, len(hits))
+	for i, h := range hits {
+		matches[i] = h.product
+	}
+	return matches
+}
+
+// tokenize splits s into lowercase words, roughly like the standard analyzer.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func page(products []Product, skip, take uint64) []Product {
+	if skip >= uint64(len(products)) {
+		return []Product{}
+	}
+	products = products[skip:]
+	if take < uint64(len(products)) {
+		products = products[:take]
+	}
+	return products
+}
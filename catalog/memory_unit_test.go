@@ -0,0 +1,114 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+)
+
+func newMemoryCatalog(t *testing.T, products ...Product) Repository {
+	t.Helper()
+	r := NewMemoryRepository()
+	for _, p := range products {
+		if _, err := r.PutProduct(context.Background(), p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+func productIDs(products []Product) []string {
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestMemoryRepository_PutProduct(t *testing.T) {
+	r := newMemoryCatalog(t,
+		Product{ID: "p1", Name: "Shoes", Price: money.New(1000, "USD")},
+		Product{ID: "p2", Name: "Shirt"},
+	)
+	if _, err := r.PutProduct(context.Background(), Product{ID: "p1", Name: "Boots"}); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := r.GetProductByID(context.Background(), "p1")
+	if err != nil || p.Name != "Boots" {
+		t.Fatalf("expected the product to be replaced, got %+v: %v", p, err)
+	}
+	if _, err := r.GetProductByID(context.Background(), "p3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	products, _ := r.ListProducts(context.Background(), 0, 10)
+	if ids := productIDs(products); len(ids) != 2 || ids[0] != "p1" || ids[1] != "p2" {
+		t.Errorf("expected replacing a product to keep its place, got %v", ids)
+	}
+	products, _ = r.ListProductsWithIDs(context.Background(), []string{"p2", "p9"})
+	if ids := productIDs(products); len(ids) != 1 || ids[0] != "p2" {
+		t.Errorf("expected only the known product, got %v", ids)
+	}
+}
+
+func TestMemoryRepository_SearchProducts(t *testing.T) {
+	r := newMemoryCatalog(t,
+		Product{ID: "p1", Name: "Running shoes", Description: "Light and fast"},
+		Product{ID: "p2", Name: "Rain jacket", Description: "Keeps you dry while running"},
+		Product{ID: "p3", Name: "Blue shoes", Description: "For running, walking, anything."},
+		Product{ID: "p4", Name: "Mug"},
+	)
+
+	products, err := r.SearchProducts(context.Background(), "Running SHOES", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := productIDs(products)
+	if len(ids) != 3 || ids[0] != "p1" || ids[1] != "p3" || ids[2] != "p2" {
+		t.Fatalf("expected the products matching both words first, got %v", ids)
+	}
+
+	products, _ = r.SearchProducts(context.Background(), "running", 1, 1)
+	if ids := productIDs(products); len(ids) != 1 || ids[0] != "p2" {
+		t.Errorf("expected the second page to hold the second match, got %v", ids)
+	}
+}
+
+func TestMemoryRepository_UpdateProduct(t *testing.T) {
+	ctx := context.Background()
+	r := newMemoryCatalog(t, Product{ID: "p2", Name: "Running socks"})
+	version, err := r.PutProduct(ctx, Product{ID: "p1", Name: "Running shoes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := r.UpdateProduct(ctx, Product{ID: "p1", Name: "Trail shoes", Version: version})
+	if err != nil || updated == version {
+		t.Fatalf("expected a new version, got %q: %v", updated, err)
+	}
+	if _, err := r.UpdateProduct(ctx, Product{ID: "p1", Name: "Road shoes", Version: version}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+	if _, err := r.UpdateProduct(ctx, Product{ID: "p9", Version: version}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if _, err := r.UpdateProduct(ctx, Product{ID: "p1", Name: "Trail shoes", Version: updated, Deleted: true}); err != nil {
+		t.Fatal(err)
+	}
+	products, _ := r.ListProducts(ctx, 0, 10)
+	if ids := productIDs(products); len(ids) != 1 || ids[0] != "p2" {
+		t.Errorf("expected deleted products to be left out, got %v", ids)
+	}
+	products, _ = r.SearchProducts(ctx, "running shoes", 0, 10)
+	if ids := productIDs(products); len(ids) != 1 || ids[0] != "p2" {
+		t.Errorf("expected deleted products not to be found, got %v", ids)
+	}
+	products, _ = r.ListProductsWithIDs(ctx, []string{"p1"})
+	if len(products) != 1 || !products[0].Deleted {
+		t.Errorf("expected deleted products to be found by ID, got %+v", products)
+	}
+}
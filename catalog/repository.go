@@ -6,36 +6,127 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"go.opentelemetry.io/otel"
 )
 
 var (
 	ErrNotFound = errors.New("Entity not found")
+	// ErrUnavailable is returned when Elasticsearch can't be reached or is
+	// overloaded. Trying again later may succeed.
+	ErrUnavailable = errors.New("search backend unavailable")
 )
 
 type Repository interface {
 	Close()
-	PutProduct(ctx context.Context, p Product) error
+	Ping(ctx context.Context) error
+	// PutProduct stores p, replacing the product with the same ID if any,
+	// and returns its new version.
+	PutProduct(ctx context.Context, p Product) (string, error)
+	// UpdateProduct replaces the stored product if it is still at p.Version
+	// and returns its new version. It fails with ErrVersionConflict if
+	// another write got there first.
+	UpdateProduct(ctx context.Context, p Product) (string, error)
 	GetProductByID(ctx context.Context, id string) (*Product, error)
 	ListProducts(ctx context.Context, skip, take uint64) ([]Product, error)
 	ListProductsWithIDs(ctx context.Context, ids []string) ([]Product, error)
 	SearchProducts(ctx context.Context, query string, skip, take uint64) ([]Product, error)
+	// ListProductsInCategories lists the products filed under any of
+	// categoryIDs, only those matching query unless it is empty.
+	ListProductsInCategories(ctx context.Context, categoryIDs []string, query string, skip, take uint64) ([]Product, error)
+	// PutCategory stores the new category c, failing with
+	// ErrCategoryExists if another has its path.
+	PutCategory(ctx context.Context, c Category) error
+	// ListCategories returns every category, in no particular order.
+	ListCategories(ctx context.Context) ([]Category, error)
 }
 
 type elasticRepository struct {
 	client *elasticsearch.Client
 }
 
+// productDocument is the indexed form of a product. Documents written before
+// prices were exact only have the floating point Price, which is converted on
+// read.
 type productDocument struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Price       float64  `json:"price,omitempty"`
+	PriceAmount *int64   `json:"price_amount,omitempty"`
+	Currency    string   `json:"currency,omitempty"`
+	Deleted     bool     `json:"deleted,omitempty"`
+	CategoryIDs []string `json:"category_ids,omitempty"`
+}
+
+func newProductDocument(p Product) productDocument {
+	return productDocument{
+		Name:        p.Name,
+		Description: p.Description,
+		PriceAmount: &p.Price.Amount,
+		Currency:    p.Price.Currency,
+		Deleted:     p.Deleted,
+		CategoryIDs: p.CategoryIDs,
+	}
+}
+
+func (d productDocument) product(id string) Product {
+	price := money.FromFloat(d.Price, money.DefaultCurrency)
+	if d.PriceAmount != nil {
+		price = money.New(*d.PriceAmount, d.Currency)
+	}
+
+	return Product{
+		ID:          id,
+		Name:        d.Name,
+		Description: d.Description,
+		Price:       price,
+		Deleted:     d.Deleted,
+		CategoryIDs: d.CategoryIDs,
+	}
+}
+
+// productHit is a product document as returned by gets and searches, with
+// the sequence number and primary term its version is made of.
+type productHit struct {
+	ID          string          `json:"_id"`
+	Source      productDocument `json:"_source"`
+	SeqNo       int64           `json:"_seq_no"`
+	PrimaryTerm int64           `json:"_primary_term"`
+}
+
+func (h productHit) product() Product {
+	p := h.Source.product(h.ID)
+	p.Version = formatVersion(h.PrimaryTerm, h.SeqNo)
+	return p
+}
+
+// formatVersion writes the version of a document, which Elasticsearch
+// tracks as a primary term and a sequence number.
+func formatVersion(primaryTerm, seqNo int64) string {
+	return fmt.Sprintf("%d.%d", primaryTerm, seqNo)
+}
+
+func parseVersion(version string) (primaryTerm, seqNo int64, err error) {
+	if _, err := fmt.Sscanf(version, "%d.%d", &primaryTerm, &seqNo); err != nil {
+		return 0, 0, fmt.Errorf("invalid product version %q", version)
+	}
+	return primaryTerm, seqNo, nil
+}
+
+// notDeleted excludes deleted products from a bool query.
+var notDeleted = map[string]interface{}{
+	"term": map[string]interface{}{"deleted": true},
 }
 
 func NewElasticRepository(url string) (Repository, error) {
 	client, err := elasticsearch.NewClient(elasticsearch.Config{
-		Addresses: []string{url},
+		Addresses:       []string{url},
+		Instrumentation: elasticsearch.NewOpenTelemetryInstrumentation(otel.GetTracerProvider(), false),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error creating elasticsearch client: %w", err)
@@ -49,45 +140,95 @@ func NewElasticRepository(url string) (Repository, error) {
 	return &elasticRepository{client}, nil
 }
 
+// unavailable reports err, from a request Elasticsearch didn't answer, as
+// ErrUnavailable unless the caller gave up on it.
+func unavailable(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
+
+// responseError describes a failed response. Server errors and throttling
+// are reported as ErrUnavailable.
+func responseError(what string, res *esapi.Response) error {
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %s: %s", ErrUnavailable, what, res.String())
+	}
+	return fmt.Errorf("%s: %s", what, res.String())
+}
+
 func (r *elasticRepository) Close() {
 	// The official client doesn't require explicit close
 }
 
-func (r *elasticRepository) PutProduct(ctx context.Context, p Product) error {
-	body := productDocument{
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
+func (r *elasticRepository) Ping(ctx context.Context) error {
+	res, err := r.client.Info(r.client.Info.WithContext(ctx))
+	if err != nil {
+		return unavailable(err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch: %s", res.Status())
+	}
+	return nil
+}
+
+func (r *elasticRepository) PutProduct(ctx context.Context, p Product) (string, error) {
+	return r.index(ctx, p)
+}
+
+func (r *elasticRepository) UpdateProduct(ctx context.Context, p Product) (string, error) {
+	primaryTerm, seqNo, err := parseVersion(p.Version)
+	if err != nil {
+		return "", err
 	}
+	return r.index(ctx, p, r.client.Index.WithIfPrimaryTerm(int(primaryTerm)), r.client.Index.WithIfSeqNo(int(seqNo)))
+}
 
-	data, err := json.Marshal(body)
+// index writes p with opts, returning the version it is stored at.
+func (r *elasticRepository) index(ctx context.Context, p Product, opts ...func(*esapi.IndexRequest)) (string, error) {
+	data, err := json.Marshal(newProductDocument(p))
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	res, err := r.client.Index(
 		"catalog",
 		bytes.NewReader(data),
-		r.client.Index.WithContext(ctx),
-		r.client.Index.WithDocumentID(p.ID),
-		r.client.Index.WithRefresh("true"),
+		append([]func(*esapi.IndexRequest){
+			r.client.Index.WithContext(ctx),
+			r.client.Index.WithDocumentID(p.ID),
+			r.client.Index.WithRefresh("true"),
+		}, opts...)...,
 	)
 	if err != nil {
-		return err
+		return "", unavailable(err)
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusConflict {
+		return "", ErrVersionConflict
+	}
 	if res.IsError() {
-		return fmt.Errorf("error indexing document: %s", res.String())
+		return "", responseError("error indexing document", res)
 	}
 
-	return nil
+	var result struct {
+		SeqNo       int64 `json:"_seq_no"`
+		PrimaryTerm int64 `json:"_primary_term"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	return formatVersion(result.PrimaryTerm, result.SeqNo), nil
 }
 
 func (r *elasticRepository) GetProductByID(ctx context.Context, id string) (*Product, error) {
 	res, err := r.client.Get("catalog", id, r.client.Get.WithContext(ctx))
 	if err != nil {
-		return nil, err
+		return nil, unavailable(err)
 	}
 	defer res.Body.Close()
 
@@ -96,91 +237,89 @@ func (r *elasticRepository) GetProductByID(ctx context.Context, id string) (*Pro
 	}
 
 	if res.IsError() {
-		return nil, fmt.Errorf("error getting document: %s", res.String())
-	}
-
-	var result struct {
-		Source productDocument `json:"_source"`
-		ID     string          `json:"_id"`
+		return nil, responseError("error getting document", res)
 	}
 
+	var result productHit
 	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
 		return nil, err
 	}
 
-	return &Product{
-		ID:          result.ID,
-		Name:        result.Source.Name,
-		Description: result.Source.Description,
-		Price:       result.Source.Price,
-	}, nil
+	p := result.product()
+	return &p, nil
 }
 
 func (r *elasticRepository) ListProducts(ctx context.Context, skip, take uint64) ([]Product, error) {
-	var buf bytes.Buffer
-	query := map[string]interface{}{
+	return r.searchProducts(ctx, map[string]interface{}{
 		"query": map[string]interface{}{
-			"match_all": map[string]interface{}{},
+			"bool": map[string]interface{}{
+				"must_not": notDeleted,
+			},
 		},
 		"from": skip,
 		"size": take,
-	}
-
-	if err := json.NewEncoder(&buf).Encode(query); err != nil {
-		return nil, err
-	}
-
-	res, err := r.client.Search(
-		r.client.Search.WithContext(ctx),
-		r.client.Search.WithIndex("catalog"),
-		r.client.Search.WithBody(&buf),
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
+	})
+}
 
-	if res.IsError() {
-		return nil, fmt.Errorf("error searching documents: %s", res.String())
-	}
+func (r *elasticRepository) ListProductsWithIDs(ctx context.Context, ids []string) ([]Product, error) {
+	return r.searchProducts(ctx, map[string]interface{}{
+		"query": map[string]interface{}{
+			"ids": map[string]interface{}{
+				"values": ids,
+			},
+		},
+		// Searches return 10 hits unless told otherwise.
+		"size": len(ids),
+	})
+}
 
-	var result struct {
-		Hits struct {
-			Hits []struct {
-				ID     string          `json:"_id"`
-				Source productDocument `json:"_source"`
-			} `json:"hits"`
-		} `json:"hits"`
-	}
+func (r *elasticRepository) SearchProducts(ctx context.Context, query string, skip, take uint64) ([]Product, error) {
+	return r.searchProducts(ctx, map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must":     matchQuery(query),
+				"must_not": notDeleted,
+			},
+		},
+		"from": skip,
+		"size": take,
+	})
+}
 
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return nil, err
+func (r *elasticRepository) ListProductsInCategories(ctx context.Context, categoryIDs []string, query string, skip, take uint64) ([]Product, error) {
+	filter := map[string]interface{}{
+		// category_ids is mapped dynamically, as text with a keyword
+		// subfield that matches IDs exactly.
+		"filter": map[string]interface{}{
+			"terms": map[string]interface{}{"category_ids.keyword": categoryIDs},
+		},
+		"must_not": notDeleted,
 	}
-
-	products := []Product{}
-	for _, hit := range result.Hits.Hits {
-		products = append(products, Product{
-			ID:          hit.ID,
-			Name:        hit.Source.Name,
-			Description: hit.Source.Description,
-			Price:       hit.Source.Price,
-		})
+	if query != "" {
+		filter["must"] = matchQuery(query)
 	}
 
-	return products, nil
+	return r.searchProducts(ctx, map[string]interface{}{
+		"query": map[string]interface{}{"bool": filter},
+		"from":  skip,
+		"size":  take,
+	})
 }
 
-func (r *elasticRepository) ListProductsWithIDs(ctx context.Context, ids []string) ([]Product, error) {
-	var buf bytes.Buffer
-	query := map[string]interface{}{
-		"query": map[string]interface{}{
-			"ids": map[string]interface{}{
-				"values": ids,
-			},
+// matchQuery matches products whose name or description contain query.
+func matchQuery(query string) map[string]interface{} {
+	return map[string]interface{}{
+		"multi_match": map[string]interface{}{
+			"query":  query,
+			"fields": []string{"name", "description"},
 		},
 	}
+}
 
-	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+// searchProducts runs the search request body against the catalog index.
+func (r *elasticRepository) searchProducts(ctx context.Context, body map[string]interface{}) ([]Product, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
 		return nil, err
 	}
 
@@ -188,22 +327,20 @@ func (r *elasticRepository) ListProductsWithIDs(ctx context.Context, ids []strin
 		r.client.Search.WithContext(ctx),
 		r.client.Search.WithIndex("catalog"),
 		r.client.Search.WithBody(&buf),
+		r.client.Search.WithSeqNoPrimaryTerm(true),
 	)
 	if err != nil {
-		return nil, err
+		return nil, unavailable(err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return nil, fmt.Errorf("error searching documents: %s", res.String())
+		return nil, responseError("error searching documents", res)
 	}
 
 	var result struct {
 		Hits struct {
-			Hits []struct {
-				ID     string          `json:"_id"`
-				Source productDocument `json:"_source"`
-			} `json:"hits"`
+			Hits []productHit `json:"hits"`
 		} `json:"hits"`
 	}
 
@@ -213,54 +350,84 @@ func (r *elasticRepository) ListProductsWithIDs(ctx context.Context, ids []strin
 
 	products := []Product{}
 	for _, hit := range result.Hits.Hits {
-		products = append(products, Product{
-			ID:          hit.ID,
-			Name:        hit.Source.Name,
-			Description: hit.Source.Description,
-			Price:       hit.Source.Price,
-		})
+		products = append(products, hit.product())
 	}
 
 	return products, nil
+}
 
+// categoryDocument is the indexed form of a category. Categories are
+// indexed under their path, which keeps paths unique.
+type categoryDocument struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	ParentID string `json:"parent_id,omitempty"`
+	Path     string `json:"path"`
 }
 
-func (r *elasticRepository) SearchProducts(ctx context.Context, query string, skip, take uint64) ([]Product, error) {
-	var buf bytes.Buffer
-	searchQuery := map[string]interface{}{
-		"query": map[string]interface{}{
-			"multi_match": map[string]interface{}{
-				"query":  query,
-				"fields": []string{"name", "description"},
-			},
-		},
-		"from": skip,
-		"size": take,
+// maxCategories is the most categories ListCategories returns, the largest
+// page Elasticsearch serves by default.
+const maxCategories = 10000
+
+func (r *elasticRepository) PutCategory(ctx context.Context, c Category) error {
+	data, err := json.Marshal(categoryDocument{
+		ID:       c.ID,
+		Name:     c.Name,
+		Slug:     c.Slug,
+		ParentID: c.ParentID,
+		Path:     c.Path,
+	})
+	if err != nil {
+		return err
 	}
 
-	if err := json.NewEncoder(&buf).Encode(searchQuery); err != nil {
-		return nil, err
+	res, err := r.client.Index(
+		"categories",
+		bytes.NewReader(data),
+		r.client.Index.WithContext(ctx),
+		r.client.Index.WithDocumentID(url.PathEscape(c.Path)),
+		r.client.Index.WithOpType("create"),
+		r.client.Index.WithRefresh("true"),
+	)
+	if err != nil {
+		return unavailable(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusConflict {
+		return fmt.Errorf("%w: %s", ErrCategoryExists, c.Path)
+	}
+	if res.IsError() {
+		return responseError("error indexing category", res)
 	}
 
+	return nil
+}
+
+func (r *elasticRepository) ListCategories(ctx context.Context) ([]Category, error) {
 	res, err := r.client.Search(
 		r.client.Search.WithContext(ctx),
-		r.client.Search.WithIndex("catalog"),
-		r.client.Search.WithBody(&buf),
+		r.client.Search.WithIndex("categories"),
+		r.client.Search.WithSize(maxCategories),
 	)
 	if err != nil {
-		return nil, err
+		return nil, unavailable(err)
 	}
 	defer res.Body.Close()
 
+	// The index is only created with the first category.
+	if res.StatusCode == http.StatusNotFound {
+		return []Category{}, nil
+	}
 	if res.IsError() {
-		return nil, fmt.Errorf("error searching documents: %s", res.String())
+		return nil, responseError("error searching categories", res)
 	}
 
 	var result struct {
 		Hits struct {
 			Hits []struct {
-				ID     string          `json:"_id"`
-				Source productDocument `json:"_source"`
+				Source categoryDocument `json:"_source"`
 			} `json:"hits"`
 		} `json:"hits"`
 	}
@@ -269,15 +436,16 @@ func (r *elasticRepository) SearchProducts(ctx context.Context, query string, sk
 		return nil, err
 	}
 
-	products := []Product{}
+	categories := []Category{}
 	for _, hit := range result.Hits.Hits {
-		products = append(products, Product{
-			ID:          hit.ID,
-			Name:        hit.Source.Name,
-			Description: hit.Source.Description,
-			Price:       hit.Source.Price,
+		categories = append(categories, Category{
+			ID:       hit.Source.ID,
+			Name:     hit.Source.Name,
+			Slug:     hit.Source.Slug,
+			ParentID: hit.Source.ParentID,
+			Path:     hit.Source.Path,
 		})
 	}
 
-	return products, nil
+	return categories, nil
 }
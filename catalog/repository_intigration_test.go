@@ -4,19 +4,23 @@ import (
 	"context"
 	"os"
 	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 )
 
 var testRepo Repository
 
+// TestMain runs the repository tests against the Elasticsearch at
+// ELASTICSEARCH_URL_FOR_TEST, or in memory if it is unset or "memory://".
 func TestMain(m *testing.M) {
 	url := os.Getenv("ELASTICSEARCH_URL_FOR_TEST")
-	if url == "" {
-		url = "http://localhost:9200"
-	}
 
-	r, err := NewElasticRepository(url)
-	if err != nil {
-		panic(err)
+	r := NewMemoryRepository()
+	if url != "" && url != "memory://" {
+		var err error
+		if r, err = NewElasticRepository(url); err != nil {
+			panic(err)
+		}
 	}
 
 	testRepo = r
@@ -26,13 +30,13 @@ func TestMain(m *testing.M) {
 }
 
 func TestElasticRepository_PutAndGetProduct(t *testing.T) {
-	err := testRepo.PutProduct(
+	_, err := testRepo.PutProduct(
 		context.Background(),
 		Product{
 			ID:          "test-prod-1",
 			Name:        "Test Product 1",
 			Description: "This is a test product",
-			Price:       19.99,
+			Price:       money.New(1999, "USD"),
 		},
 	)
 	if err != nil {
@@ -56,37 +60,37 @@ func TestElasticRepository_GetProduct_NotFound(t *testing.T) {
 }
 
 func TestElasticRepository_ListProducts(t *testing.T) {
-	err := testRepo.PutProduct(
+	_, err := testRepo.PutProduct(
 		context.Background(),
 		Product{
 			ID:          "test-prod-3",
 			Name:        "Test Product 1",
 			Description: "This is a test product",
-			Price:       11,
+			Price:       money.New(1100, "USD"),
 		},
 	)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = testRepo.PutProduct(
+	_, err = testRepo.PutProduct(
 		context.Background(),
 		Product{
 			ID:          "test-prod-1",
 			Name:        "Test Product 1",
 			Description: "This is a test product",
-			Price:       2.99,
+			Price:       money.New(299, "USD"),
 		},
 	)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = testRepo.PutProduct(
+	_, err = testRepo.PutProduct(
 		context.Background(),
 		Product{
 			ID:          "test-prod-4",
 			Name:        "Test Product 1",
 			Description: "This is a test product",
-			Price:       14.99,
+			Price:       money.New(1499, "USD"),
 		},
 	)
 	if err != nil {
@@ -104,37 +108,37 @@ func TestElasticRepository_ListProducts(t *testing.T) {
 }
 
 func TestElasticRepository_SearchProduct(t *testing.T) {
-	err := testRepo.PutProduct(
+	_, err := testRepo.PutProduct(
 		context.Background(),
 		Product{
 			ID:          "test-prod-5",
 			Name:        "Include Product",
 			Description: "This is a test product",
-			Price:       11,
+			Price:       money.New(1100, "USD"),
 		},
 	)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = testRepo.PutProduct(
+	_, err = testRepo.PutProduct(
 		context.Background(),
 		Product{
 			ID:          "test-prod-6",
 			Name:        "Test Product 1",
 			Description: "This is a test product",
-			Price:       2.99,
+			Price:       money.New(299, "USD"),
 		},
 	)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = testRepo.PutProduct(
+	_, err = testRepo.PutProduct(
 		context.Background(),
 		Product{
 			ID:          "test-prod-7",
 			Name:        "Include Product",
 			Description: "This is a test product",
-			Price:       14.99,
+			Price:       money.New(1499, "USD"),
 		},
 	)
 	if err != nil {
@@ -67,6 +67,21 @@ func (mr *MockRepositoryMockRecorder) GetProductByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByID", reflect.TypeOf((*MockRepository)(nil).GetProductByID), ctx, id)
 }
 
+// ListCategories mocks base method.
+func (m *MockRepository) ListCategories(ctx context.Context) ([]Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategories", ctx)
+	ret0, _ := ret[0].([]Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategories indicates an expected call of ListCategories.
+func (mr *MockRepositoryMockRecorder) ListCategories(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategories", reflect.TypeOf((*MockRepository)(nil).ListCategories), ctx)
+}
+
 // ListProducts mocks base method.
 func (m *MockRepository) ListProducts(ctx context.Context, skip, take uint64) ([]Product, error) {
 	m.ctrl.T.Helper()
@@ -82,6 +97,21 @@ func (mr *MockRepositoryMockRecorder) ListProducts(ctx, skip, take any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProducts", reflect.TypeOf((*MockRepository)(nil).ListProducts), ctx, skip, take)
 }
 
+// ListProductsInCategories mocks base method.
+func (m *MockRepository) ListProductsInCategories(ctx context.Context, categoryIDs []string, query string, skip, take uint64) ([]Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductsInCategories", ctx, categoryIDs, query, skip, take)
+	ret0, _ := ret[0].([]Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductsInCategories indicates an expected call of ListProductsInCategories.
+func (mr *MockRepositoryMockRecorder) ListProductsInCategories(ctx, categoryIDs, query, skip, take any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductsInCategories", reflect.TypeOf((*MockRepository)(nil).ListProductsInCategories), ctx, categoryIDs, query, skip, take)
+}
+
 // ListProductsWithIDs mocks base method.
 func (m *MockRepository) ListProductsWithIDs(ctx context.Context, ids []string) ([]Product, error) {
 	m.ctrl.T.Helper()
@@ -97,14 +127,43 @@ func (mr *MockRepositoryMockRecorder) ListProductsWithIDs(ctx, ids any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductsWithIDs", reflect.TypeOf((*MockRepository)(nil).ListProductsWithIDs), ctx, ids)
 }
 
-// PutProduct mocks base method.
-func (m *MockRepository) PutProduct(ctx context.Context, p Product) error {
+// Ping mocks base method.
+func (m *MockRepository) Ping(ctx context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PutProduct", ctx, p)
+	ret := m.ctrl.Call(m, "Ping", ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// Ping indicates an expected call of Ping.
+func (mr *MockRepositoryMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepository)(nil).Ping), ctx)
+}
+
+// PutCategory mocks base method.
+func (m *MockRepository) PutCategory(ctx context.Context, c Category) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutCategory", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutCategory indicates an expected call of PutCategory.
+func (mr *MockRepositoryMockRecorder) PutCategory(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutCategory", reflect.TypeOf((*MockRepository)(nil).PutCategory), ctx, c)
+}
+
+// PutProduct mocks base method.
+func (m *MockRepository) PutProduct(ctx context.Context, p Product) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutProduct", ctx, p)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // PutProduct indicates an expected call of PutProduct.
 func (mr *MockRepositoryMockRecorder) PutProduct(ctx, p any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
@@ -125,3 +184,18 @@ func (mr *MockRepositoryMockRecorder) SearchProducts(ctx, query, skip, take any)
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockRepository)(nil).SearchProducts), ctx, query, skip, take)
 }
+
+// UpdateProduct mocks base method.
+func (m *MockRepository) UpdateProduct(ctx context.Context, p Product) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, p)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockRepositoryMockRecorder) UpdateProduct(ctx, p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockRepository)(nil).UpdateProduct), ctx, p)
+}
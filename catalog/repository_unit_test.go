@@ -2,11 +2,14 @@ package catalog
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"testing"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"github.com/elastic/go-elasticsearch/v8"
 )
 
@@ -43,7 +46,7 @@ func TestPutProduct_Success(t *testing.T) {
 				if req.Method != "PUT" {
 					t.Errorf("expected PUT, got %s", req.Method)
 				}
-				return mockResponse(201, `{"result":"created"}`), nil
+				return mockResponse(201, `{"result":"created","_seq_no":0,"_primary_term":1}`), nil
 			},
 		},
 	})
@@ -53,18 +56,21 @@ func TestPutProduct_Success(t *testing.T) {
 
 	mockRepo := &elasticRepository{client}
 
-	err = mockRepo.PutProduct(
+	version, err := mockRepo.PutProduct(
 		context.Background(),
 		Product{
 			ID:          "p1",
 			Name:        "unit test product",
 			Description: "put test",
-			Price:       10.02,
+			Price:       money.New(1002, "USD"),
 		},
 	)
 	if err != nil {
 		t.Error(err)
 	}
+	if version != "1.0" {
+		t.Errorf("expected version 1.0, got %q", version)
+	}
 }
 
 func TestGetProductByID_Success(t *testing.T) {
@@ -102,7 +108,7 @@ func TestGetProductByID_Success(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if p.Name != "Pen" || p.Description != "Blue" || p.Price != 5 {
+	if p.Name != "Pen" || p.Description != "Blue" || p.Price != money.New(500, "USD") {
 		t.Errorf("expected Pen, got %s", p.Name)
 	}
 }
@@ -153,7 +159,7 @@ func TestListProducts_Success(t *testing.T) {
 				  "hits": {
 				    "hits": [
 				      {"_id":"p1","_source":{"name":"A","description":"d","price":1}},
-				      {"_id":"p2","_source":{"name":"B","description":"e","price":2}}
+				      {"_id":"p2","_source":{"name":"B","description":"e","price_amount":250,"currency":"EUR"}}
 				    ]
 				  }
 				}`), nil
@@ -169,7 +175,10 @@ func TestListProducts_Success(t *testing.T) {
 	}
 
 	if len(res) != 2 {
-		t.Errorf("expected 2 products, got %d", len(res))
+		t.Fatalf("expected 2 products, got %d", len(res))
+	}
+	if res[0].Price != money.New(100, "USD") || res[1].Price != money.New(250, "EUR") {
+		t.Errorf("unexpected prices: %v, %v", res[0].Price, res[1].Price)
 	}
 }
 
@@ -207,3 +216,203 @@ func TestSearchProducts_WithQuery(t *testing.T) {
 		t.Errorf("Expected 1; got %d", len(res))
 	}
 }
+
+func TestListProductsWithIDs_Unavailable(t *testing.T) {
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Transport: mockTransport{
+			fn: func(req *http.Request) (*http.Response, error) {
+				return mockResponse(503, `{"error":"no shard available"}`), nil
+			},
+		},
+		MaxRetries: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockRepo := &elasticRepository{client}
+
+	_, err = mockRepo.ListProductsWithIDs(context.Background(), []string{"p1"})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestGetProductByID_BadRequest(t *testing.T) {
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Transport: mockTransport{
+			fn: func(req *http.Request) (*http.Response, error) {
+				return mockResponse(400, `{"error":"bad request"}`), nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockRepo := &elasticRepository{client}
+
+	_, err = mockRepo.GetProductByID(context.Background(), "p1")
+	if err == nil || errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected a permanent error, got %v", err)
+	}
+}
+
+func TestUpdateProduct_WritesIfStillAtVersion(t *testing.T) {
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Transport: mockTransport{
+			fn: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/" {
+					return mockResponse(200, `{"version":{"number":"8.0.0"}}`), nil
+				}
+				if q := req.URL.Query(); q.Get("if_primary_term") != "1" || q.Get("if_seq_no") != "4" {
+					t.Errorf("expected the write to be conditional on version 1.4, got %s", req.URL.RawQuery)
+				}
+				return mockResponse(200, `{"result":"updated","_seq_no":5,"_primary_term":1}`), nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockRepo := &elasticRepository{client}
+
+	version, err := mockRepo.UpdateProduct(context.Background(), Product{ID: "p1", Name: "Pen", Version: "1.4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "1.5" {
+		t.Errorf("expected version 1.5, got %q", version)
+	}
+}
+
+func TestUpdateProduct_Conflict(t *testing.T) {
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Transport: mockTransport{
+			fn: func(req *http.Request) (*http.Response, error) {
+				return mockResponse(409, `{"error":{"type":"version_conflict_engine_exception"}}`), nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockRepo := &elasticRepository{client}
+
+	_, err = mockRepo.UpdateProduct(context.Background(), Product{ID: "p1", Version: "1.4"})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+}
+
+func TestListCategories_NoIndexYet(t *testing.T) {
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Transport: mockTransport{
+			fn: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/" {
+					return mockResponse(200, `{"version":{"number":"8.0.0"}}`), nil
+				}
+				return mockResponse(404, `{"error":{"type":"index_not_found_exception"}}`), nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockRepo := &elasticRepository{client}
+
+	categories, err := mockRepo.ListCategories(context.Background())
+	if err != nil || len(categories) != 0 {
+		t.Errorf("expected no categories, got %v: %v", categories, err)
+	}
+}
+
+func TestListProductsInCategories_FiltersByCategory(t *testing.T) {
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Transport: mockTransport{
+			fn: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/" {
+					return mockResponse(200, `{"version":{"number":"8.0.0"}}`), nil
+				}
+				body, _ := io.ReadAll(req.Body)
+				if !strings.Contains(string(body), `"terms":{"category_ids.keyword":["c1","c2"]}`) {
+					t.Errorf("expected a filter on the categories, got %s", body)
+				}
+				return mockResponse(200, `{"hits":{"hits":[{"_id":"p1","_source":{"name":"A","price_amount":100,"currency":"USD","category_ids":["c2"]}}]}}`), nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockRepo := &elasticRepository{client}
+
+	res, err := mockRepo.ListProductsInCategories(context.Background(), []string{"c1", "c2"}, "", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || len(res[0].CategoryIDs) != 1 || res[0].CategoryIDs[0] != "c2" {
+		t.Errorf("unexpected products: %+v", res)
+	}
+}
+
+func TestPutCategory_PathTaken(t *testing.T) {
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Transport: mockTransport{
+			fn: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/" {
+					return mockResponse(200, `{"version":{"number":"8.0.0"}}`), nil
+				}
+				if req.URL.EscapedPath() != "/categories/_doc/clothing%2Fshoes" || req.URL.Query().Get("op_type") != "create" {
+					t.Errorf("expected the category to be created under its path, got %s", req.URL)
+				}
+				return mockResponse(409, `{"error":{"type":"version_conflict_engine_exception"}}`), nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockRepo := &elasticRepository{client}
+
+	err = mockRepo.PutCategory(context.Background(), Category{ID: "c2", Name: "Shoes", Slug: "shoes", ParentID: "c1", Path: "clothing/shoes"})
+	if !errors.Is(err, ErrCategoryExists) {
+		t.Errorf("expected ErrCategoryExists, got %v", err)
+	}
+}
+
+func TestListProductsWithIDs_AsksForEveryProduct(t *testing.T) {
+	ids := []string{}
+	for i := range 12 {
+		ids = append(ids, fmt.Sprintf("p%d", i))
+	}
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Transport: mockTransport{
+			fn: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/" {
+					return mockResponse(200, `{"version":{"number":"8.0.0"}}`), nil
+				}
+				body, _ := io.ReadAll(req.Body)
+				if !strings.Contains(string(body), `"size":12`) {
+					t.Errorf("expected a hit for each of the 12 IDs, got %s", body)
+				}
+				return mockResponse(200, `{"hits":{"hits":[]}}`), nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockRepo := &elasticRepository{client}
+
+	if _, err := mockRepo.ListProductsWithIDs(context.Background(), ids); err != nil {
+		t.Fatal(err)
+	}
+}
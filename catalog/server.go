@@ -2,12 +2,21 @@ package catalog
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/errcode"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/health"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/metrics"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/middleware"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/serve"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -17,34 +26,74 @@ type grpcServer struct {
 	inventoryClient *inventory.Client
 }
 
-func ListenGRPC(s Service, inventoryURL string, port int) error {
-	invetoryClient, err := inventory.NewClient(inventoryURL)
+// methodRoles are the roles required by the methods that change the
+// catalog. Browsing it is open to everyone.
+var methodRoles = map[string]auth.Role{
+	pb.CatalogService_PostProduct_FullMethodName:   auth.RoleMerchant,
+	pb.CatalogService_UpdateProduct_FullMethodName: auth.RoleMerchant,
+	pb.CatalogService_DeleteProduct_FullMethodName: auth.RoleMerchant,
+	pb.CatalogService_PostCategory_FullMethodName:  auth.RoleMerchant,
+}
+
+// ErrInvalidUpdateMask is returned for an UpdateProduct call naming no
+// fields, or fields that can't be changed.
+var ErrInvalidUpdateMask = errors.New("invalid update mask")
+
+var errorCodes = errcode.Codes{
+	ErrNotFound:               codes.NotFound,
+	ErrUnavailable:            codes.Unavailable,
+	ErrInvalidPrice:           codes.InvalidArgument,
+	ErrInvalidUpdateMask:      codes.InvalidArgument,
+	ErrVersionConflict:        codes.Aborted,
+	ErrCategoryNotFound:       codes.NotFound,
+	ErrCategoryExists:         codes.AlreadyExists,
+	ErrInvalidSlug:            codes.InvalidArgument,
+	money.ErrInvalidAmount:    codes.InvalidArgument,
+	money.ErrInvalidCurrency:  codes.InvalidArgument,
+	money.ErrCurrencyMismatch: codes.InvalidArgument,
+}
+
+// DefaultPort is the port ListenGRPC listens on unless told otherwise.
+const DefaultPort = 8082
+
+// ListenGRPC serves s until ctx is done, then drains the calls in flight.
+// tokens verifies the access tokens callers authenticate with.
+func ListenGRPC(ctx context.Context, s Service, tokens *auth.Signer, inventoryURL string, opts ...serve.Option) error {
+	cfg := serve.NewConfig(DefaultPort, opts...)
+	invetoryClient, err := inventory.NewClient(inventoryURL, cfg.DialOptions...)
 	if err != nil {
 		return err
 	}
+	defer invetoryClient.Close()
 
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	lis, err := cfg.Listen()
 	if err != nil {
-		invetoryClient.Close()
 		return err
 	}
-	srv := grpc.NewServer()
+	srv := grpc.NewServer(append([]grpc.ServerOption{
+		tracing.ServerOption(),
+		metrics.ServerOption(),
+		middleware.ServerOption(),
+		grpc.ChainUnaryInterceptor(
+			errcode.UnaryServerInterceptor(errorCodes),
+			auth.UnaryServerInterceptor(tokens, methodRoles),
+		),
+	}, cfg.ServerOptions...)...)
 	pb.RegisterCatalogServiceServer(srv, &grpcServer{service: s, inventoryClient: invetoryClient})
+	health.Serve(ctx, srv, map[string]health.Check{
+		"elasticsearch": s.Ping,
+		"inventory":     invetoryClient.Ping,
+	})
 	reflection.Register(srv)
-	return srv.Serve(lis)
+	return serve.GRPC(ctx, srv, lis, cfg.DrainTimeout)
 }
 
 func (s *grpcServer) PostProduct(ctx context.Context, r *pb.PostProductRequest) (*pb.PostProductResponse, error) {
-	p, err := s.service.PostProduct(ctx, r.Name, r.Description, r.Price)
+	p, err := s.service.PostProduct(ctx, r.Name, r.Description, money.FromProto(r.Price), r.CategoryIds)
 	if err != nil {
 		return nil, err
 	}
-	return &pb.PostProductResponse{Product: &pb.Product{
-		Id:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-	}}, nil
+	return &pb.PostProductResponse{Product: productToProto(p)}, nil
 }
 
 func (s *grpcServer) GetProduct(ctx context.Context, r *pb.GetProductRequest) (*pb.GetProductResponse, error) {
@@ -53,18 +102,13 @@ func (s *grpcServer) GetProduct(ctx context.Context, r *pb.GetProductRequest) (*
 		return nil, err
 	}
 
-	q, err := s.inventoryClient.CheckStock(ctx, []string{r.Id})
+	q, err := s.checkStock(ctx, []string{r.Id})
 	if err != nil {
 		return nil, err
 	}
 
 	res := &pb.ProductInResponse{
-		Product: &pb.Product{
-			Id:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-		},
+		Product: productToProto(p),
 		Quntity: q[0],
 	}
 
@@ -77,7 +121,9 @@ func (s *grpcServer) GetProducts(ctx context.Context, r *pb.GetProductsRequest)
 		err error
 	)
 
-	if r.Query != "" {
+	if r.CategoryId != "" {
+		res, err = s.service.GetProductsInCategory(ctx, r.CategoryId, r.Query, r.Skip, r.Take)
+	} else if r.Query != "" {
 		res, err = s.service.SearchProduct(ctx, r.Query, r.Skip, r.Take)
 	} else if len(r.Ids) != 0 {
 		res, err = s.service.GetProductsById(ctx, r.Ids)
@@ -93,19 +139,17 @@ func (s *grpcServer) GetProducts(ctx context.Context, r *pb.GetProductsRequest)
 		ids = append(ids, p.ID)
 	}
 
-	quantities, err := s.inventoryClient.CheckStock(ctx, ids)
+	quantities, err := s.checkStock(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
 
 	products := []*pb.ProductInResponse{}
 	for i, p := range res {
 		products = append(
 			products,
 			&pb.ProductInResponse{
-				Product: &pb.Product{
-					Id:          p.ID,
-					Name:        p.Name,
-					Description: p.Description,
-					Price:       p.Price,
-				},
+				Product: productToProto(&p),
 				Quntity: quantities[i],
 			},
 		)
@@ -113,3 +157,106 @@ func (s *grpcServer) GetProducts(ctx context.Context, r *pb.GetProductsRequest)
 
 	return &pb.GetProductsResponse{Products: products}, nil
 }
+
+func (s *grpcServer) UpdateProduct(ctx context.Context, r *pb.UpdateProductRequest) (*pb.UpdateProductResponse, error) {
+	update, err := productUpdate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.service.UpdateProduct(ctx, r.Product.GetId(), r.Product.GetVersion(), update)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateProductResponse{Product: productToProto(p)}, nil
+}
+
+// productUpdate takes the fields named by the update mask of r from its
+// product.
+func productUpdate(r *pb.UpdateProductRequest) (ProductUpdate, error) {
+	var update ProductUpdate
+	paths := r.UpdateMask.GetPaths()
+	if len(paths) == 0 || r.Product == nil {
+		return update, fmt.Errorf("%w: no fields to update", ErrInvalidUpdateMask)
+	}
+	for _, path := range paths {
+		switch path {
+		case "name":
+			update.Name = &r.Product.Name
+		case "description":
+			update.Description = &r.Product.Description
+		case "price":
+			price := money.FromProto(r.Product.Price)
+			update.Price = &price
+		case "category_ids":
+			update.CategoryIDs = &r.Product.CategoryIds
+		default:
+			return update, fmt.Errorf("%w: %q can't be updated", ErrInvalidUpdateMask, path)
+		}
+	}
+	return update, nil
+}
+
+func (s *grpcServer) DeleteProduct(ctx context.Context, r *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	p, err := s.service.DeleteProduct(ctx, r.Id, r.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DeleteProductResponse{Product: productToProto(p)}, nil
+}
+
+func productToProto(p *Product) *pb.Product {
+	return &pb.Product{
+		Id:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price.Proto(),
+		Version:     p.Version,
+		Deleted:     p.Deleted,
+		CategoryIds: p.CategoryIDs,
+	}
+}
+
+func (s *grpcServer) PostCategory(ctx context.Context, r *pb.PostCategoryRequest) (*pb.PostCategoryResponse, error) {
+	c, err := s.service.PostCategory(ctx, r.Name, r.Slug, r.ParentId)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostCategoryResponse{Category: categoryToProto(c)}, nil
+}
+
+func (s *grpcServer) GetCategories(ctx context.Context, r *pb.GetCategoriesRequest) (*pb.GetCategoriesResponse, error) {
+	categories, err := s.service.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.GetCategoriesResponse{}
+	for i := range categories {
+		res.Categories = append(res.Categories, categoryToProto(&categories[i]))
+	}
+	return res, nil
+}
+
+func categoryToProto(c *Category) *pb.Category {
+	return &pb.Category{
+		Id:       c.ID,
+		Name:     c.Name,
+		Slug:     c.Slug,
+		ParentId: c.ParentID,
+		Path:     c.Path,
+	}
+}
+
+// checkStock returns the stock of each of ids, failing instead of leaving
+// the caller to index past the end if inventory doesn't answer for all.
+func (s *grpcServer) checkStock(ctx context.Context, ids []string) ([]int32, error) {
+	q, err := s.inventoryClient.CheckStock(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(q) != len(ids) {
+		return nil, fmt.Errorf("inventory returned stock for %d of %d products", len(q), len(ids))
+	}
+	return q, nil
+}
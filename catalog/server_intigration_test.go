@@ -5,6 +5,8 @@ import (
 	"net"
 	"testing"
 
+	moneypb "github.com/RathodViraj/go-microservice-graphql-grpc/money/pb"
+
 	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog/pb"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
 	inventorypb "github.com/RathodViraj/go-microservice-graphql-grpc/inventory/pb"
@@ -90,7 +92,7 @@ func TestGRPC_PostProduct_Integration(t *testing.T) {
 		&pb.PostProductRequest{
 			Name:        "Integration Test Product",
 			Description: "This is a test product",
-			Price:       29.99,
+			Price:       &moneypb.Money{Amount: 2999, Currency: "USD"},
 		},
 	)
 	if err != nil {
@@ -105,8 +107,8 @@ func TestGRPC_PostProduct_Integration(t *testing.T) {
 		t.Error("expected non-empty product ID")
 	}
 
-	if res.Product.Price != 29.99 {
-		t.Errorf("expected price 29.99, got %f", res.Product.Price)
+	if res.Product.Price.GetAmount() != 2999 {
+		t.Errorf("expected price 29.99, got %v", res.Product.Price)
 	}
 }
 
@@ -122,7 +124,7 @@ func TestGRPC_GetProduct_Integration(t *testing.T) {
 		&pb.PostProductRequest{
 			Name:        "Laptop",
 			Description: "High-performance laptop",
-			Price:       999.99,
+			Price:       &moneypb.Money{Amount: 99999, Currency: "USD"},
 		},
 	)
 	if err != nil {
@@ -156,7 +158,7 @@ func TestGRPC_GetProducts_Integration(t *testing.T) {
 	// Create multiple products
 	_, err := client.PostProduct(
 		context.Background(),
-		&pb.PostProductRequest{Name: "Product A", Description: "Description A", Price: 10.00},
+		&pb.PostProductRequest{Name: "Product A", Description: "Description A", Price: &moneypb.Money{Amount: 1000, Currency: "USD"}},
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -164,7 +166,7 @@ func TestGRPC_GetProducts_Integration(t *testing.T) {
 
 	_, err = client.PostProduct(
 		context.Background(),
-		&pb.PostProductRequest{Name: "Product B", Description: "Description B", Price: 20.00},
+		&pb.PostProductRequest{Name: "Product B", Description: "Description B", Price: &moneypb.Money{Amount: 2000, Currency: "USD"}},
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -172,7 +174,7 @@ func TestGRPC_GetProducts_Integration(t *testing.T) {
 
 	_, err = client.PostProduct(
 		context.Background(),
-		&pb.PostProductRequest{Name: "Product C", Description: "Description C", Price: 30.00},
+		&pb.PostProductRequest{Name: "Product C", Description: "Description C", Price: &moneypb.Money{Amount: 3000, Currency: "USD"}},
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -204,7 +206,7 @@ func TestGRPC_SearchProducts_Integration(t *testing.T) {
 		&pb.PostProductRequest{
 			Name:        "Special Gaming Mouse",
 			Description: "RGB gaming mouse",
-			Price:       49.99,
+			Price:       &moneypb.Money{Amount: 4999, Currency: "USD"},
 		},
 	)
 	if err != nil {
@@ -246,7 +248,7 @@ func TestGRPC_GetProductsByIds_Integration(t *testing.T) {
 	// Create products and collect IDs
 	res1, err := client.PostProduct(
 		context.Background(),
-		&pb.PostProductRequest{Name: "Product X", Description: "Desc X", Price: 15.00},
+		&pb.PostProductRequest{Name: "Product X", Description: "Desc X", Price: &moneypb.Money{Amount: 1500, Currency: "USD"}},
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -254,7 +256,7 @@ func TestGRPC_GetProductsByIds_Integration(t *testing.T) {
 
 	res2, err := client.PostProduct(
 		context.Background(),
-		&pb.PostProductRequest{Name: "Product Y", Description: "Desc Y", Price: 25.00},
+		&pb.PostProductRequest{Name: "Product Y", Description: "Desc Y", Price: &moneypb.Money{Amount: 2500, Currency: "USD"}},
 	)
 	if err != nil {
 		t.Fatal(err)
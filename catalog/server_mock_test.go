@@ -42,6 +42,46 @@ func (m *MockCatalogServiceClient) EXPECT() *MockCatalogServiceClientMockRecorde
 	return m.recorder
 }
 
+// DeleteProduct mocks base method.
+func (m *MockCatalogServiceClient) DeleteProduct(ctx context.Context, in *pb.DeleteProductRequest, opts ...grpc.CallOption) (*pb.DeleteProductResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteProduct", varargs...)
+	ret0, _ := ret[0].(*pb.DeleteProductResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteProduct indicates an expected call of DeleteProduct.
+func (mr *MockCatalogServiceClientMockRecorder) DeleteProduct(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockCatalogServiceClient)(nil).DeleteProduct), varargs...)
+}
+
+// GetCategories mocks base method.
+func (m *MockCatalogServiceClient) GetCategories(ctx context.Context, in *pb.GetCategoriesRequest, opts ...grpc.CallOption) (*pb.GetCategoriesResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetCategories", varargs...)
+	ret0, _ := ret[0].(*pb.GetCategoriesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategories indicates an expected call of GetCategories.
+func (mr *MockCatalogServiceClientMockRecorder) GetCategories(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockCatalogServiceClient)(nil).GetCategories), varargs...)
+}
+
 // GetProduct mocks base method.
 func (m *MockCatalogServiceClient) GetProduct(ctx context.Context, in *pb.GetProductRequest, opts ...grpc.CallOption) (*pb.GetProductResponse, error) {
 	m.ctrl.T.Helper()
@@ -82,6 +122,26 @@ func (mr *MockCatalogServiceClientMockRecorder) GetProducts(ctx, in any, opts ..
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockCatalogServiceClient)(nil).GetProducts), varargs...)
 }
 
+// PostCategory mocks base method.
+func (m *MockCatalogServiceClient) PostCategory(ctx context.Context, in *pb.PostCategoryRequest, opts ...grpc.CallOption) (*pb.PostCategoryResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostCategory", varargs...)
+	ret0, _ := ret[0].(*pb.PostCategoryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostCategory indicates an expected call of PostCategory.
+func (mr *MockCatalogServiceClientMockRecorder) PostCategory(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostCategory", reflect.TypeOf((*MockCatalogServiceClient)(nil).PostCategory), varargs...)
+}
+
 // PostProduct mocks base method.
 func (m *MockCatalogServiceClient) PostProduct(ctx context.Context, in *pb.PostProductRequest, opts ...grpc.CallOption) (*pb.PostProductResponse, error) {
 	m.ctrl.T.Helper()
@@ -101,3 +161,23 @@ func (mr *MockCatalogServiceClientMockRecorder) PostProduct(ctx, in any, opts ..
 	varargs := append([]any{ctx, in}, opts...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostProduct", reflect.TypeOf((*MockCatalogServiceClient)(nil).PostProduct), varargs...)
 }
+
+// UpdateProduct mocks base method.
+func (m *MockCatalogServiceClient) UpdateProduct(ctx context.Context, in *pb.UpdateProductRequest, opts ...grpc.CallOption) (*pb.UpdateProductResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateProduct", varargs...)
+	ret0, _ := ret[0].(*pb.UpdateProductResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockCatalogServiceClientMockRecorder) UpdateProduct(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockCatalogServiceClient)(nil).UpdateProduct), varargs...)
+}
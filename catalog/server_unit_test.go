@@ -6,12 +6,16 @@ import (
 	"net"
 	"testing"
 
+	moneypb "github.com/RathodViraj/go-microservice-graphql-grpc/money/pb"
+
 	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog/pb"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
 	inventorypb "github.com/RathodViraj/go-microservice-graphql-grpc/inventory/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 const bufSize = 1024 * 1024
@@ -97,14 +101,14 @@ func TestServer_PostProduct(t *testing.T) {
 
 	mockSvc := NewMockService(ctrl)
 	mockSvc.EXPECT().
-		PostProduct(gomock.Any(), "Pen", "Blue ink", 4.99).
-		Return(&Product{ID: "p1", Name: "Pen", Description: "Blue ink", Price: 4.99}, nil)
+		PostProduct(gomock.Any(), "Pen", "Blue ink", money.New(499, "USD"), []string{"c1"}).
+		Return(&Product{ID: "p1", Name: "Pen", Description: "Blue ink", Price: money.New(499, "USD")}, nil)
 
 	conn, cleanup := startTestServer(t, mockSvc)
 	defer cleanup()
 	client := pb.NewCatalogServiceClient(conn)
 
-	_, err := client.PostProduct(context.Background(), &pb.PostProductRequest{Name: "Pen", Description: "Blue ink", Price: 4.99})
+	_, err := client.PostProduct(context.Background(), &pb.PostProductRequest{Name: "Pen", Description: "Blue ink", Price: &moneypb.Money{Amount: 499, Currency: "USD"}, CategoryIds: []string{"c1"}})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -117,7 +121,7 @@ func TestServer_GetProduct(t *testing.T) {
 	mockSvc := NewMockService(ctrl)
 	mockSvc.EXPECT().
 		GetProduct(gomock.Any(), "p1").
-		Return(&Product{ID: "p1", Name: "Pen", Description: "Blue ink", Price: 4.99}, nil)
+		Return(&Product{ID: "p1", Name: "Pen", Description: "Blue ink", Price: money.New(499, "USD")}, nil)
 
 	conn, cleanup := startTestServer(t, mockSvc)
 	defer cleanup()
@@ -147,3 +151,39 @@ func TestServer_ListProduct(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestServer_UpdateProduct_AppliesMask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	name := "Pencil"
+	mockSvc := NewMockService(ctrl)
+	mockSvc.EXPECT().
+		UpdateProduct(gomock.Any(), "p1", "1.3", ProductUpdate{Name: &name}).
+		Return(&Product{ID: "p1", Name: "Pencil", Price: money.New(499, "USD"), Version: "1.4"}, nil)
+
+	conn, cleanup := startTestServer(t, mockSvc)
+	defer cleanup()
+	client := pb.NewCatalogServiceClient(conn)
+
+	res, err := client.UpdateProduct(context.Background(), &pb.UpdateProductRequest{
+		Product:    &pb.Product{Id: "p1", Version: "1.3", Name: "Pencil", Description: "ignored"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Product.Version != "1.4" {
+		t.Errorf("expected version 1.4, got %q", res.Product.Version)
+	}
+
+	for _, paths := range [][]string{nil, {"id"}} {
+		_, err := client.UpdateProduct(context.Background(), &pb.UpdateProductRequest{
+			Product:    &pb.Product{Id: "p1"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
+		})
+		if err == nil {
+			t.Errorf("%v: expected the update mask to be rejected", paths)
+		}
+	}
+}
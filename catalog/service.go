@@ -2,23 +2,69 @@ package catalog
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"github.com/segmentio/ksuid"
 )
 
+var (
+	ErrInvalidPrice = errors.New("invalid product price")
+	// ErrVersionConflict means the product changed after the version an
+	// update or delete was based on.
+	ErrVersionConflict = errors.New("product changed concurrently")
+)
+
 type Product struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	// Version identifies the stored revision of the product. It changes on
+	// every write.
+	Version string `json:"version"`
+	// Deleted products are left out of listings and searches, but can still
+	// be looked up by ID for the orders they are part of.
+	Deleted     bool     `json:"deleted"`
+	CategoryIDs []string `json:"category_ids"`
+}
+
+// ProductUpdate holds the fields UpdateProduct changes. Nil ones are kept.
+type ProductUpdate struct {
+	Name        *string
+	Description *string
+	Price       *money.Money
+	// CategoryIDs replaces the categories of the product.
+	CategoryIDs *[]string
 }
 
 type Service interface {
-	PostProduct(ctx context.Context, name, description string, price float64) (*Product, error)
+	// Ping reports whether the store behind the service can be reached.
+	Ping(ctx context.Context) error
+	// PostProduct adds a product filed under categoryIDs, failing with
+	// ErrCategoryNotFound unless every one of them is a category.
+	PostProduct(ctx context.Context, name, description string, price money.Money, categoryIDs []string) (*Product, error)
 	GetProduct(ctx context.Context, id string) (*Product, error)
 	GetProducts(ctx context.Context, skip, take uint64) ([]Product, error)
 	GetProductsById(ctx context.Context, ids []string) ([]Product, error)
 	SearchProduct(ctx context.Context, query string, skip, take uint64) ([]Product, error)
+	// UpdateProduct changes the fields set in update. Unless version is
+	// empty, it fails with ErrVersionConflict if the product is no longer at
+	// version.
+	UpdateProduct(ctx context.Context, id, version string, update ProductUpdate) (*Product, error)
+	// DeleteProduct marks the product deleted, checking version like
+	// UpdateProduct.
+	DeleteProduct(ctx context.Context, id, version string) (*Product, error)
+	// PostCategory adds a category below parentID, or at the top of the
+	// tree if parentID is empty. The slug is derived from name if empty.
+	PostCategory(ctx context.Context, name, slug, parentID string) (*Category, error)
+	// GetCategories returns the whole category tree, ordered by path.
+	GetCategories(ctx context.Context) ([]Category, error)
+	// GetProductsInCategory lists the products in the category or any
+	// category below it, only those matching query unless it is empty.
+	GetProductsInCategory(ctx context.Context, categoryID, query string, skip, take uint64) ([]Product, error)
 }
 
 type catalogService struct {
@@ -29,21 +75,38 @@ func NewSerivce(r Repository) Service {
 	return &catalogService{r}
 }
 
-func (s *catalogService) PostProduct(ctx context.Context, name, description string, price float64) (*Product, error) {
+func (s *catalogService) PostProduct(ctx context.Context, name, description string, price money.Money, categoryIDs []string) (*Product, error) {
+	if err := validatePrice(price); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategories(ctx, categoryIDs); err != nil {
+		return nil, err
+	}
+
 	p := &Product{
 		ID:          ksuid.New().String(),
 		Name:        name,
 		Description: description,
 		Price:       price,
+		CategoryIDs: slices.Compact(slices.Sorted(slices.Values(categoryIDs))),
 	}
 
-	if err := s.repository.PutProduct(ctx, *p); err != nil {
+	version, err := s.repository.PutProduct(ctx, *p)
+	if err != nil {
 		return nil, err
 	}
+	p.Version = version
 
 	return p, nil
 }
 
+func validatePrice(price money.Money) error {
+	if !money.ValidCurrency(price.Currency) || price.Amount < 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidPrice, price)
+	}
+	return nil
+}
+
 func (s *catalogService) GetProduct(ctx context.Context, id string) (*Product, error) {
 	return s.repository.GetProductByID(ctx, id)
 }
@@ -67,3 +130,63 @@ func (s *catalogService) SearchProduct(ctx context.Context, query string, skip,
 
 	return s.repository.SearchProducts(ctx, query, skip, take)
 }
+
+func (s *catalogService) UpdateProduct(ctx context.Context, id, version string, update ProductUpdate) (*Product, error) {
+	if update.Price != nil {
+		if err := validatePrice(*update.Price); err != nil {
+			return nil, err
+		}
+	}
+	if update.CategoryIDs != nil {
+		if err := s.checkCategories(ctx, *update.CategoryIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.modify(ctx, id, version, func(p *Product) {
+		if update.Name != nil {
+			p.Name = *update.Name
+		}
+		if update.Description != nil {
+			p.Description = *update.Description
+		}
+		if update.Price != nil {
+			p.Price = *update.Price
+		}
+		if update.CategoryIDs != nil {
+			p.CategoryIDs = slices.Compact(slices.Sorted(slices.Values(*update.CategoryIDs)))
+		}
+	})
+}
+
+func (s *catalogService) DeleteProduct(ctx context.Context, id, version string) (*Product, error) {
+	return s.modify(ctx, id, version, func(p *Product) {
+		p.Deleted = true
+	})
+}
+
+// modify applies change to the product with id and writes it back, unless
+// the product was written in between. Deleted products can't be changed.
+func (s *catalogService) modify(ctx context.Context, id, version string, change func(p *Product)) (*Product, error) {
+	p, err := s.repository.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Deleted {
+		return nil, ErrNotFound
+	}
+	if version != "" && version != p.Version {
+		return nil, ErrVersionConflict
+	}
+
+	change(p)
+	p.Version, err = s.repository.UpdateProduct(ctx, *p)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *catalogService) Ping(ctx context.Context) error {
+	return s.repository.Ping(ctx)
+}
@@ -3,13 +3,15 @@ package catalog
 import (
 	"context"
 	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 )
 
 func TestService_PostGetProduct(t *testing.T) {
 	svc := &catalogService{testRepo}
 	ctx := context.Background()
 
-	p, err := svc.PostProduct(ctx, "Pen", "black ink", 1.92)
+	p, err := svc.PostProduct(ctx, "Pen", "black ink", money.New(192, "USD"), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -23,7 +25,7 @@ func TestService_PostGetProduct(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if getPrd.Name != "Pen" || getPrd.Description != "black ink" || getPrd.Price != 1.92 {
+	if getPrd.Name != "Pen" || getPrd.Description != "black ink" || getPrd.Price != money.New(192, "USD") {
 		t.Errorf("unexpected output: %#v", p)
 	}
 }
@@ -32,15 +34,15 @@ func TestService_SearchProdct(t *testing.T) {
 	svc := &catalogService{testRepo}
 	ctx := context.Background()
 
-	_, err := svc.PostProduct(ctx, "Pen", "black ink", 1.92)
+	_, err := svc.PostProduct(ctx, "Pen", "black ink", money.New(192, "USD"), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, err = svc.PostProduct(ctx, "Pen", "red ink", 2.64)
+	_, err = svc.PostProduct(ctx, "Pen", "red ink", money.New(264, "USD"), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, err = svc.PostProduct(ctx, "Pen", "bue ink", 1)
+	_, err = svc.PostProduct(ctx, "Pen", "bue ink", money.New(100, "USD"), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	money "github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +41,36 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// DeleteProduct mocks base method.
+func (m *MockService) DeleteProduct(ctx context.Context, id, version string) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProduct", ctx, id, version)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteProduct indicates an expected call of DeleteProduct.
+func (mr *MockServiceMockRecorder) DeleteProduct(ctx, id, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockService)(nil).DeleteProduct), ctx, id, version)
+}
+
+// GetCategories mocks base method.
+func (m *MockService) GetCategories(ctx context.Context) ([]Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategories", ctx)
+	ret0, _ := ret[0].([]Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategories indicates an expected call of GetCategories.
+func (mr *MockServiceMockRecorder) GetCategories(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockService)(nil).GetCategories), ctx)
+}
+
 // GetProduct mocks base method.
 func (m *MockService) GetProduct(ctx context.Context, id string) (*Product, error) {
 	m.ctrl.T.Helper()
@@ -85,19 +116,63 @@ func (mr *MockServiceMockRecorder) GetProductsById(ctx, ids any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductsById", reflect.TypeOf((*MockService)(nil).GetProductsById), ctx, ids)
 }
 
+// GetProductsInCategory mocks base method.
+func (m *MockService) GetProductsInCategory(ctx context.Context, categoryID, query string, skip, take uint64) ([]Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductsInCategory", ctx, categoryID, query, skip, take)
+	ret0, _ := ret[0].([]Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductsInCategory indicates an expected call of GetProductsInCategory.
+func (mr *MockServiceMockRecorder) GetProductsInCategory(ctx, categoryID, query, skip, take any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductsInCategory", reflect.TypeOf((*MockService)(nil).GetProductsInCategory), ctx, categoryID, query, skip, take)
+}
+
+// Ping mocks base method.
+func (m *MockService) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockServiceMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockService)(nil).Ping), ctx)
+}
+
+// PostCategory mocks base method.
+func (m *MockService) PostCategory(ctx context.Context, name, slug, parentID string) (*Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostCategory", ctx, name, slug, parentID)
+	ret0, _ := ret[0].(*Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostCategory indicates an expected call of PostCategory.
+func (mr *MockServiceMockRecorder) PostCategory(ctx, name, slug, parentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostCategory", reflect.TypeOf((*MockService)(nil).PostCategory), ctx, name, slug, parentID)
+}
+
 // PostProduct mocks base method.
-func (m *MockService) PostProduct(ctx context.Context, name, description string, price float64) (*Product, error) {
+func (m *MockService) PostProduct(ctx context.Context, name, description string, price money.Money, categoryIDs []string) (*Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PostProduct", ctx, name, description, price)
+	ret := m.ctrl.Call(m, "PostProduct", ctx, name, description, price, categoryIDs)
 	ret0, _ := ret[0].(*Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // PostProduct indicates an expected call of PostProduct.
-func (mr *MockServiceMockRecorder) PostProduct(ctx, name, description, price any) *gomock.Call {
+func (mr *MockServiceMockRecorder) PostProduct(ctx, name, description, price, categoryIDs any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostProduct", reflect.TypeOf((*MockService)(nil).PostProduct), ctx, name, description, price)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostProduct", reflect.TypeOf((*MockService)(nil).PostProduct), ctx, name, description, price, categoryIDs)
 }
 
 // SearchProduct mocks base method.
@@ -114,3 +189,18 @@ func (mr *MockServiceMockRecorder) SearchProduct(ctx, query, skip, take any) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProduct", reflect.TypeOf((*MockService)(nil).SearchProduct), ctx, query, skip, take)
 }
+
+// UpdateProduct mocks base method.
+func (m *MockService) UpdateProduct(ctx context.Context, id, version string, update ProductUpdate) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, id, version, update)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockServiceMockRecorder) UpdateProduct(ctx, id, version, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockService)(nil).UpdateProduct), ctx, id, version, update)
+}
@@ -2,8 +2,10 @@ package catalog
 
 import (
 	"context"
+	"errors"
 	"testing"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"go.uber.org/mock/gomock"
 )
 
@@ -17,13 +19,14 @@ func TestService_PostProduct(t *testing.T) {
 
 	mockRepo.EXPECT().
 		PutProduct(gomock.Any(), gomock.AssignableToTypeOf(Product{})).
-		Return(nil)
+		Return("1.0", nil)
 
 	p, err := svc.PostProduct(
 		context.Background(),
 		"Pen",
 		"Blue ink",
-		4.99,
+		money.New(499, "USD"),
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -32,11 +35,27 @@ func TestService_PostProduct(t *testing.T) {
 	if p.ID == "" {
 		t.Error("expeced non empty ID")
 	}
-	if p.Name != "Pen" || p.Description != "Blue ink" || p.Price != 4.99 {
+	if p.Name != "Pen" || p.Description != "Blue ink" || p.Price != money.New(499, "USD") || p.Version != "1.0" {
 		t.Errorf("unexpected output: %#v", p)
 	}
 }
 
+func TestService_PostProduct_InvalidPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := &catalogService{repository: mockRepo}
+
+	mockRepo.EXPECT().PutProduct(gomock.Any(), gomock.Any()).Times(0)
+
+	for _, price := range []money.Money{money.New(-1, "USD"), money.New(100, ""), money.New(100, "usd")} {
+		if _, err := svc.PostProduct(context.Background(), "Pen", "Blue ink", price, nil); !errors.Is(err, ErrInvalidPrice) {
+			t.Errorf("%v: expected ErrInvalidPrice, got %v", price, err)
+		}
+	}
+}
+
 func TestService_GetProduct_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -96,3 +115,77 @@ func TestService_GetProductsById_DelegatesToRepo(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestService_UpdateProduct_ChangesOnlyGivenFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := &catalogService{repository: mockRepo}
+
+	mockRepo.EXPECT().
+		GetProductByID(gomock.Any(), "p1").
+		Return(&Product{ID: "p1", Name: "Pen", Description: "Blue ink", Price: money.New(499, "USD"), Version: "1.3"}, nil)
+	mockRepo.EXPECT().
+		UpdateProduct(gomock.Any(), Product{ID: "p1", Name: "Pen", Description: "Blue ink", Price: money.New(599, "USD"), Version: "1.3"}).
+		Return("1.4", nil)
+
+	price := money.New(599, "USD")
+	p, err := svc.UpdateProduct(context.Background(), "p1", "1.3", ProductUpdate{Price: &price})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Price != price || p.Name != "Pen" || p.Version != "1.4" {
+		t.Errorf("unexpected output: %#v", p)
+	}
+}
+
+func TestService_UpdateProduct_StaleVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := &catalogService{repository: mockRepo}
+
+	mockRepo.EXPECT().
+		GetProductByID(gomock.Any(), "p1").
+		Return(&Product{ID: "p1", Name: "Pen", Version: "1.4"}, nil)
+	mockRepo.EXPECT().UpdateProduct(gomock.Any(), gomock.Any()).Times(0)
+
+	name := "Pencil"
+	if _, err := svc.UpdateProduct(context.Background(), "p1", "1.3", ProductUpdate{Name: &name}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+}
+
+func TestService_DeleteProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := &catalogService{repository: mockRepo}
+
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			GetProductByID(gomock.Any(), "p1").
+			Return(&Product{ID: "p1", Version: "1.3"}, nil),
+		mockRepo.EXPECT().
+			UpdateProduct(gomock.Any(), Product{ID: "p1", Version: "1.3", Deleted: true}).
+			Return("1.4", nil),
+		mockRepo.EXPECT().
+			GetProductByID(gomock.Any(), "p1").
+			Return(&Product{ID: "p1", Version: "1.4", Deleted: true}, nil),
+	)
+
+	p, err := svc.DeleteProduct(context.Background(), "p1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Deleted || p.Version != "1.4" {
+		t.Errorf("unexpected output: %#v", p)
+	}
+
+	if _, err := svc.DeleteProduct(context.Background(), "p1", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected deleting again to fail with ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Package errcode reports the domain errors of the services as gRPC
+// statuses, so callers can tell a missing entity or a bad request from an
+// outage.
+package errcode
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ViolationOutOfStock is the type of the precondition violations listing
+// the products an OutOfStock error was about.
+const ViolationOutOfStock = "OUT_OF_STOCK"
+
+// Codes maps domain errors, matched with errors.Is, to the gRPC code they
+// are reported with.
+type Codes map[error]codes.Code
+
+// Status converts err into a gRPC status error. Errors that already carry
+// a status, such as those returned by another service, are kept as they
+// are. Errors not found in c are logged and reported as codes.Internal
+// with a generic message, as theirs may give away internal details.
+func (c Codes) Status(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	for target, code := range c {
+		if errors.Is(err, target) {
+			return status.Error(code, err.Error())
+		}
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	log.Printf("internal error: %v", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// UnaryServerInterceptor converts the errors returned by handlers with
+// c.Status.
+func UnaryServerInterceptor(c Codes) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		res, err := handler(ctx, req)
+		return res, c.Status(err)
+	}
+}
+
+// OutOfStock returns a codes.FailedPrecondition error listing the products
+// that are out of stock.
+func OutOfStock(productIDs []string) error {
+	st := status.New(codes.FailedPrecondition, "products out of stock")
+	failure := &errdetails.PreconditionFailure{}
+	for _, id := range productIDs {
+		failure.Violations = append(failure.Violations, &errdetails.PreconditionFailure_Violation{
+			Type:        ViolationOutOfStock,
+			Subject:     id,
+			Description: "not enough stock",
+		})
+	}
+	if detailed, err := st.WithDetails(failure); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// OutOfStockProducts returns the products listed by an OutOfStock error,
+// or false if err is not one.
+func OutOfStockProducts(err error) ([]string, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.FailedPrecondition {
+		return nil, false
+	}
+
+	var ids []string
+	for _, d := range st.Details() {
+		failure, ok := d.(*errdetails.PreconditionFailure)
+		if !ok {
+			continue
+		}
+		for _, v := range failure.Violations {
+			if v.Type == ViolationOutOfStock {
+				ids = append(ids, v.Subject)
+			}
+		}
+	}
+	return ids, len(ids) != 0
+}
@@ -0,0 +1,61 @@
+package errcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errMissing = errors.New("missing")
+
+func TestCodes_Status(t *testing.T) {
+	c := Codes{errMissing: codes.NotFound}
+
+	testCases := []struct {
+		name    string
+		err     error
+		want    codes.Code
+		wantMsg string
+	}{
+		{name: "nil", want: codes.OK},
+		{name: "known", err: errMissing, want: codes.NotFound, wantMsg: "missing"},
+		{name: "wrapped", err: fmt.Errorf("loading: %w", errMissing), want: codes.NotFound, wantMsg: "loading: missing"},
+		{name: "already a status", err: status.Error(codes.Unavailable, "down"), want: codes.Unavailable, wantMsg: "down"},
+		{name: "deadline", err: context.DeadlineExceeded, want: codes.DeadlineExceeded, wantMsg: "context deadline exceeded"},
+		{name: "unknown", err: errors.New("boom"), want: codes.Internal, wantMsg: "internal error"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := c.Status(tc.err)
+			if got := status.Code(err); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+			if msg := status.Convert(err).Message(); msg != tc.wantMsg {
+				t.Errorf("expected message %q, got %q", tc.wantMsg, msg)
+			}
+		})
+	}
+}
+
+func TestOutOfStock(t *testing.T) {
+	err := OutOfStock([]string{"p1", "p2"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+
+	// The details survive being sent over the wire.
+	received := status.FromProto(status.Convert(err).Proto()).Err()
+	ids, ok := OutOfStockProducts(received)
+	if !ok || len(ids) != 2 || ids[0] != "p1" || ids[1] != "p2" {
+		t.Errorf("expected p1 and p2, got %v, %v", ids, ok)
+	}
+
+	if _, ok := OutOfStockProducts(status.Error(codes.FailedPrecondition, "other")); ok {
+		t.Error("expected a precondition failure without details not to be out of stock")
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package graphql
 
 import (
 	"context"
@@ -14,31 +14,15 @@ func (r *accountResolver) Orders(ctx context.Context, obj *Account) ([]*Order, e
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	orderList, err := r.server.orderClient.GetOrdersForAccount(ctx, obj.ID)
+	orderList, err := loadersFor(ctx, r.server).ordersByAccount.Load(ctx, obj.ID)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	var orders []*Order
+	orders := []*Order{}
 	for _, o := range orderList {
-		var produts []*OrderedProduct
-		for _, p := range o.Products {
-			produts = append(produts, &OrderedProduct{
-				ID:          p.ID,
-				Name:        p.Name,
-				Description: p.Description,
-				Price:       p.Price,
-				Quantity:    int(p.Quantity),
-			})
-		}
-
-		orders = append(orders, &Order{
-			ID:         o.ID,
-			CreatedAt:  o.CreatedAt,
-			TotalPrice: o.TotalPrice,
-			Products:   produts,
-		})
+		orders = append(orders, toOrder(o))
 	}
 
 	return orders, nil
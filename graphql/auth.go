@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+)
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrForbidden       = errors.New("not allowed for this account")
+)
+
+// withAuth verifies the bearer token of a request, if any, and makes its
+// account available to the resolvers. Requests without a token go through
+// anonymously; those with a bad one are rejected.
+func (s *Server) withAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			unauthorized(w, "expected a bearer token")
+			return
+		}
+		claims, err := s.tokens.Verify(token, auth.AccessToken)
+		if err != nil {
+			unauthorized(w, err.Error())
+			return
+		}
+
+		ctx := auth.NewContext(r.Context(), claims.Identity())
+		next.ServeHTTP(w, r.WithContext(auth.NewTokenContext(ctx, token)))
+	})
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	http.Error(w, msg, http.StatusUnauthorized)
+}
+
+// caller returns the authenticated account.
+func caller(ctx context.Context) (auth.Identity, error) {
+	id, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.Identity{}, ErrUnauthenticated
+	}
+	return id, nil
+}
+
+// hasRole implements the @hasRole directive.
+func hasRole(ctx context.Context, _ any, next graphql.Resolver, role Role) (any, error) {
+	id, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !id.Role.Includes(role.toDomain()) {
+		return nil, ErrForbidden
+	}
+	return next(ctx)
+}
@@ -0,0 +1,102 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+)
+
+func TestWithAuth(t *testing.T) {
+	tokens, err := auth.NewSigner([]byte(strings.Repeat("s", auth.MinSecretLength)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair, err := tokens.Issue("acc1", auth.RoleCustomer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{tokens: tokens}
+
+	testCases := []struct {
+		name        string
+		header      string
+		wantStatus  int
+		wantAccount string
+	}{
+		{name: "anonymous", wantStatus: http.StatusOK},
+		{name: "valid token", header: "Bearer " + pair.AccessToken, wantStatus: http.StatusOK, wantAccount: "acc1"},
+		{name: "refresh token", header: "Bearer " + pair.RefreshToken, wantStatus: http.StatusUnauthorized},
+		{name: "garbage", header: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", header: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotAccount, gotToken string
+			h := s.withAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				id, _ := caller(r.Context())
+				gotAccount = id.AccountID
+				gotToken, _ = auth.TokenFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if gotAccount != tc.wantAccount {
+				t.Errorf("expected account %q, got %q", tc.wantAccount, gotAccount)
+			}
+			// The token is forwarded to the services along with the identity.
+			if (gotToken != "") != (tc.wantAccount != "") {
+				t.Errorf("expected token only for authenticated requests, got %q", gotToken)
+			}
+		})
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	testCases := []struct {
+		name    string
+		caller  *auth.Identity
+		role    Role
+		wantErr error
+	}{
+		{name: "anonymous", role: RoleCustomer, wantErr: ErrUnauthenticated},
+		{name: "customer", caller: &auth.Identity{AccountID: "acc1", Role: auth.RoleCustomer}, role: RoleMerchant, wantErr: ErrForbidden},
+		{name: "merchant", caller: &auth.Identity{AccountID: "acc1", Role: auth.RoleMerchant}, role: RoleMerchant},
+		{name: "admin includes merchant", caller: &auth.Identity{AccountID: "acc1", Role: auth.RoleAdmin}, role: RoleMerchant},
+		{name: "merchant is not admin", caller: &auth.Identity{AccountID: "acc1", Role: auth.RoleMerchant}, role: RoleAdmin, wantErr: ErrForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = auth.NewContext(ctx, *tc.caller)
+			}
+
+			called := false
+			_, err := hasRole(ctx, nil, func(context.Context) (any, error) {
+				called = true
+				return nil, nil
+			}, tc.role)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got %v", tc.wantErr, err)
+			}
+			if called != (tc.wantErr == nil) {
+				t.Errorf("expected resolver to run only when allowed, ran: %v", called)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/graphql"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/metrics"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/resilience"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/serve"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tlsconfig"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
+	"github.com/kelseyhightower/envconfig"
+	"google.golang.org/grpc"
+)
+
+type AppConfig struct {
+	Port int `envconfig:"PORT" default:"8080"`
+	// ShutdownTimeout is how long requests in flight get to finish on SIGTERM.
+	ShutdownTimeout time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"10s"`
+	// MetricsPort serves /metrics for Prometheus.
+	MetricsPort  int    `envconfig:"METRICS_PORT" default:"9080"`
+	AccountURL   string `envconfig:"ACCOUNT_SERVICE_URL"`
+	CatalogURL   string `envconfig:"CATALOG_SERVICE_URL"`
+	OrderURL     string `envconfig:"ORDER_SERVICE_URL"`
+	InventoryURL string `envconfig:"INVENTORY_SERVICE_URL"`
+	// JWTSecret verifies access tokens and must match the account service's.
+	JWTSecret string `envconfig:"JWT_SECRET" required:"true"`
+	// TraceExporter is where spans are sent: "otlp", "stdout" or "none".
+	TraceExporter string `envconfig:"OTEL_TRACES_EXPORTER" default:"none"`
+	// TLSCAFile enables TLS to the services, verifying them against that CA.
+	// TLSCertFile and TLSKeyFile are the client certificate presented to
+	// them when they require one. Changes to the files are picked up
+	// without a restart.
+	TLSCertFile string `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile  string `envconfig:"TLS_KEY_FILE"`
+	TLSCAFile   string `envconfig:"TLS_CA_FILE"`
+	// ClientTimeout bounds each call to another service. Reads failing
+	// because a service is unavailable are tried up to ClientMaxAttempts
+	// times in all.
+	ClientTimeout     time.Duration `envconfig:"CLIENT_TIMEOUT" default:"5s"`
+	ClientMaxAttempts int           `envconfig:"CLIENT_MAX_ATTEMPTS" default:"3"`
+}
+
+func main() {
+	var cfg AppConfig
+	err := envconfig.Process("", &cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Defaults for local development
+	if cfg.AccountURL == "" {
+		cfg.AccountURL = "localhost:8081"
+	}
+	if cfg.CatalogURL == "" {
+		cfg.CatalogURL = "localhost:8082"
+	}
+	if cfg.OrderURL == "" {
+		cfg.OrderURL = "localhost:8083"
+	}
+	if cfg.InventoryURL == "" {
+		cfg.InventoryURL = "localhost:8084"
+	}
+
+	log.Printf("Starting GraphQL server with:")
+	log.Printf("  Account service: %s", cfg.AccountURL)
+	log.Printf("  Catalog service: %s", cfg.CatalogURL)
+	log.Printf("  Order service: %s", cfg.OrderURL)
+	log.Printf("  Inventory service: %s", cfg.InventoryURL)
+
+	tokens, err := auth.NewSigner([]byte(cfg.JWTSecret))
+	if err != nil {
+		log.Fatalf("invalid JWT_SECRET: %v", err)
+	}
+
+	// The service clients pick up the tracer provider when created.
+	shutdownTracing, err := tracing.Setup(context.Background(), "graphql", cfg.TraceExporter)
+	if err != nil {
+		log.Fatalf("invalid OTEL_TRACES_EXPORTER: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	policy := resilience.DefaultPolicy
+	policy.Timeout, policy.MaxAttempts = cfg.ClientTimeout, cfg.ClientMaxAttempts
+	dialOpts := []grpc.DialOption{resilience.WithDefaultPolicy(policy)}
+	certFiles := tlsconfig.Files{CertFile: cfg.TLSCertFile, KeyFile: cfg.TLSKeyFile, CAFile: cfg.TLSCAFile}
+	if certFiles.Enabled() {
+		certs, err := tlsconfig.NewReloader(certFiles)
+		if err != nil {
+			log.Fatalf("invalid TLS configuration: %v", err)
+		}
+		go certs.Run(ctx, tlsconfig.DefaultReloadInterval)
+		dialOpts = append(dialOpts, certs.DialOption())
+	}
+
+	s, err := graphql.NewGraphQLServer(cfg.AccountURL, cfg.OrderURL, cfg.CatalogURL, cfg.InventoryURL, tokens, dialOpts...)
+	if err != nil {
+		log.Fatalf("Failed to create GraphQL server: %v", err)
+	}
+
+	log.Println("GraphQL server initialized successfully")
+
+	go func() {
+		if err := metrics.ListenAndServe(ctx, cfg.MetricsPort); err != nil {
+			log.Println("metrics:", err)
+		}
+	}()
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Port))
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Server listening on :%d", cfg.Port)
+	err = serve.HTTP(ctx, &http.Server{Handler: s.Handler()}, lis, cfg.ShutdownTimeout)
+	s.Close()
+	shutdownTracing(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,113 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/errcode"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The values of extensions.code on the errors of a response.
+const (
+	CodeNotFound           = "NOT_FOUND"
+	CodeBadUserInput       = "BAD_USER_INPUT"
+	CodeOutOfStock         = "OUT_OF_STOCK"
+	CodeFailedPrecondition = "FAILED_PRECONDITION"
+	CodeAlreadyExists      = "ALREADY_EXISTS"
+	CodeConflict           = "CONFLICT"
+	CodeUnauthenticated    = "UNAUTHENTICATED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeTimeout            = "TIMEOUT"
+	CodeUnavailable        = "UNAVAILABLE"
+	CodeInternal           = "INTERNAL_SERVER_ERROR"
+)
+
+// gatewayErrorCodes covers the errors raised by the gateway itself rather
+// than by a service.
+var gatewayErrorCodes = map[error]string{
+	ErrUnauthenticated:        CodeUnauthenticated,
+	ErrForbidden:              CodeForbidden,
+	ErrInvalidParameter:       CodeBadUserInput,
+	money.ErrInvalidAmount:    CodeBadUserInput,
+	money.ErrInvalidCurrency:  CodeBadUserInput,
+	money.ErrCurrencyMismatch: CodeBadUserInput,
+	context.DeadlineExceeded:  CodeTimeout,
+}
+
+var grpcErrorCodes = map[codes.Code]string{
+	codes.NotFound:           CodeNotFound,
+	codes.InvalidArgument:    CodeBadUserInput,
+	codes.OutOfRange:         CodeBadUserInput,
+	codes.FailedPrecondition: CodeFailedPrecondition,
+	codes.AlreadyExists:      CodeAlreadyExists,
+	codes.Aborted:            CodeConflict,
+	codes.Unauthenticated:    CodeUnauthenticated,
+	codes.PermissionDenied:   CodeForbidden,
+	codes.DeadlineExceeded:   CodeTimeout,
+	codes.Unavailable:        CodeUnavailable,
+}
+
+// presentError sets extensions.code on every error of a response, so
+// clients can switch on it instead of parsing messages. Errors gqlgen
+// already gave a code, such as validation failures, keep theirs. Internal
+// errors are logged and given a generic message, as theirs may give away
+// details of the services.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if _, ok := gqlErr.Extensions["code"]; ok {
+		return gqlErr
+	}
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]any{}
+	}
+
+	code := errorCode(err)
+	gqlErr.Extensions["code"] = code
+	if code == CodeOutOfStock {
+		gqlErr.Extensions["productIds"], _ = errcode.OutOfStockProducts(err)
+	}
+	switch st, ok := grpcStatus(err); {
+	case code == CodeInternal:
+		log.Printf("graphql: internal error: %v", err)
+		gqlErr.Message = "internal error"
+	case ok:
+		// Drop the "rpc error: code = ..." prefix added by gRPC.
+		gqlErr.Message = st.Message()
+	}
+	return gqlErr
+}
+
+// grpcStatus returns the status of the service call err came from, if any.
+func grpcStatus(err error) (*status.Status, bool) {
+	var se interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &se) {
+		return nil, false
+	}
+	return se.GRPCStatus(), true
+}
+
+func errorCode(err error) string {
+	for target, code := range gatewayErrorCodes {
+		if errors.Is(err, target) {
+			return code
+		}
+	}
+
+	st, ok := grpcStatus(err)
+	if !ok {
+		return CodeInternal
+	}
+	if _, outOfStock := errcode.OutOfStockProducts(err); outOfStock {
+		return CodeOutOfStock
+	}
+	if code, ok := grpcErrorCodes[st.Code()]; ok {
+		return code
+	}
+	return CodeInternal
+}
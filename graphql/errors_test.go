@@ -0,0 +1,53 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPresentError(t *testing.T) {
+	testCases := []struct {
+		name        string
+		err         error
+		wantCode    string
+		wantMessage string
+	}{
+		{name: "not found", err: status.Error(codes.NotFound, "order not found"), wantCode: CodeNotFound, wantMessage: "order not found"},
+		{name: "bad input", err: status.Error(codes.InvalidArgument, "invalid cursor"), wantCode: CodeBadUserInput, wantMessage: "invalid cursor"},
+		{name: "out of stock", err: errcode.OutOfStock([]string{"p1"}), wantCode: CodeOutOfStock, wantMessage: "products out of stock"},
+		{name: "outage", err: status.Error(codes.Unavailable, "connection refused"), wantCode: CodeUnavailable, wantMessage: "connection refused"},
+		{name: "wrapped by resolver", err: fmt.Errorf("loading orders: %w", status.Error(codes.PermissionDenied, "no")), wantCode: CodeForbidden, wantMessage: "no"},
+		{name: "gateway", err: ErrUnauthenticated, wantCode: CodeUnauthenticated, wantMessage: ErrUnauthenticated.Error()},
+		{name: "unknown", err: errors.New("boom"), wantCode: CodeInternal, wantMessage: "internal error"},
+		{name: "internal service error", err: status.Error(codes.Unknown, "pq: relation \"orders\" does not exist"), wantCode: CodeInternal, wantMessage: "internal error"},
+		{name: "existing code", err: &gqlerror.Error{Message: "bad query", Extensions: map[string]any{"code": "GRAPHQL_VALIDATION_FAILED"}}, wantCode: "GRAPHQL_VALIDATION_FAILED", wantMessage: "bad query"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := presentError(context.Background(), tc.err)
+			if got.Extensions["code"] != tc.wantCode {
+				t.Errorf("expected code %s, got %v", tc.wantCode, got.Extensions["code"])
+			}
+			if got.Message != tc.wantMessage {
+				t.Errorf("expected message %q, got %q", tc.wantMessage, got.Message)
+			}
+		})
+	}
+}
+
+func TestPresentError_OutOfStockProducts(t *testing.T) {
+	got := presentError(context.Background(), errcode.OutOfStock([]string{"p1", "p2"}))
+
+	ids, ok := got.Extensions["productIds"].([]string)
+	if !ok || len(ids) != 2 || ids[0] != "p1" || ids[1] != "p2" {
+		t.Errorf("expected productIds p1 and p2, got %v", got.Extensions["productIds"])
+	}
+}
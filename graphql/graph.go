@@ -1,11 +1,19 @@
-package main
+package graphql
 
 import (
+	"net/http"
+
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/account"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/middleware"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/order"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
@@ -13,28 +21,35 @@ type Server struct {
 	orderClient     *order.Client
 	catalogClient   *catalog.Client
 	inventoryClient *inventory.Client
+	tokens          *auth.Signer
 }
 
-func NewGraphQLServer(accountURL, orderURL, catalogURL, inventoryURL string) (*Server, error) {
-	accountClient, err := account.NewClient(accountURL)
+// NewGraphQLServer connects to the services. tokens verifies the access
+// tokens issued by the account service. opts are added to the dial options
+// of every service client.
+func NewGraphQLServer(accountURL, orderURL, catalogURL, inventoryURL string, tokens *auth.Signer, opts ...grpc.DialOption) (*Server, error) {
+	// The services see the caller of each request, not the gateway.
+	creds := append([]grpc.DialOption{grpc.WithPerRPCCredentials(auth.ForwardCredentials())}, opts...)
+
+	accountClient, err := account.NewClient(accountURL, creds...)
 	if err != nil {
 		return nil, err
 	}
 
-	catalogClient, err := catalog.NewClient(catalogURL)
+	catalogClient, err := catalog.NewClient(catalogURL, creds...)
 	if err != nil {
 		accountClient.Close()
 		return nil, err
 	}
 
-	orderClient, err := order.NewClient(orderURL)
+	orderClient, err := order.NewClient(orderURL, creds...)
 	if err != nil {
 		accountClient.Close()
 		catalogClient.Close()
 		return nil, err
 	}
 
-	inventoryClient, err := inventory.NewClient(inventoryURL)
+	inventoryClient, err := inventory.NewClient(inventoryURL, creds...)
 	if err != nil {
 		accountClient.Close()
 		catalogClient.Close()
@@ -47,9 +62,18 @@ func NewGraphQLServer(accountURL, orderURL, catalogURL, inventoryURL string) (*S
 		orderClient:     orderClient,
 		catalogClient:   catalogClient,
 		inventoryClient: inventoryClient,
+		tokens:          tokens,
 	}, nil
 }
 
+// Close closes the connections to the services.
+func (s *Server) Close() {
+	s.accountClient.Close()
+	s.orderClient.Close()
+	s.catalogClient.Close()
+	s.inventoryClient.Close()
+}
+
 func (s *Server) Mutation() MutationResolver {
 	return &mutationResolver{
 		server: s,
@@ -68,8 +92,30 @@ func (s *Server) Account() AccountResolver {
 	}
 }
 
+func (s *Server) Product() ProductResolver {
+	return &productResolver{
+		server: s,
+	}
+}
+
 func (s *Server) ToExecutableSchema() graphql.ExecutableSchema {
 	return NewExecutableSchema(Config{
-		Resolvers: s,
+		Resolvers:  s,
+		Directives: DirectiveRoot{HasRole: hasRole},
 	})
 }
+
+// Handler serves the GraphQL API on /graphql, the playground on /playground
+// and the health checks on /healthz and /readyz.
+func (s *Server) Handler() http.Handler {
+	srv := handler.NewDefaultServer(s.ToExecutableSchema())
+	srv.SetErrorPresenter(presentError)
+	srv.Use(tracer{})
+	srv.Use(metricsRecorder{})
+	mux := http.NewServeMux()
+	mux.Handle("/graphql", otelhttp.NewHandler(middleware.RequestIDHandler(s.withAuth(s.withLoaders(srv))), "graphql"))
+	mux.Handle("/playground", playground.Handler("viraj", "/graphql"))
+	mux.HandleFunc("/healthz", healthz)
+	mux.Handle("/readyz", readyz(s.readinessChecks()))
+	return mux
+}
@@ -0,0 +1,47 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/health"
+)
+
+// healthz reports that the gateway is up. It does not look at the
+// services, so a failing service doesn't get the gateway restarted.
+func healthz(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok\n"))
+}
+
+// readinessChecks asks every service the gateway depends on whether it is
+// serving.
+func (s *Server) readinessChecks() map[string]health.Check {
+	return map[string]health.Check{
+		"account":   s.accountClient.Ping,
+		"catalog":   s.catalogClient.Ping,
+		"order":     s.orderClient.Ping,
+		"inventory": s.inventoryClient.Ping,
+	}
+}
+
+// readyz runs checks and answers 200 if all of them passed, 503 otherwise,
+// with the outcome of each as a JSON object.
+func readyz(checks map[string]health.Check) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := http.StatusOK
+		report := make(map[string]string, len(checks))
+		for name, err := range health.Run(r.Context(), checks) {
+			if err != nil {
+				report[name] = err.Error()
+				code = http.StatusServiceUnavailable
+			} else {
+				report[name] = "ok"
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,57 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/health"
+)
+
+func TestReadyz(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("status NOT_SERVING") }
+
+	testCases := []struct {
+		name       string
+		checks     map[string]health.Check
+		wantStatus int
+		want       map[string]string
+	}{
+		{
+			name:       "all serving",
+			checks:     map[string]health.Check{"account": up, "order": up},
+			wantStatus: http.StatusOK,
+			want:       map[string]string{"account": "ok", "order": "ok"},
+		},
+		{
+			name:       "one down",
+			checks:     map[string]health.Check{"account": up, "order": down},
+			wantStatus: http.StatusServiceUnavailable,
+			want:       map[string]string{"account": "ok", "order": "status NOT_SERVING"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			readyz(tc.checks).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			var got map[string]string
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tc.want {
+				if got[name] != want {
+					t.Errorf("expected %s to be %q, got %q", name, want, got[name])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/order"
+)
+
+const (
+	// loaderWait is how long a loader collects keys before fetching them.
+	// Sibling fields are resolved concurrently, so this only has to cover
+	// the time it takes gqlgen to start their resolvers.
+	loaderWait = 2 * time.Millisecond
+	// loaderMaxBatch caps the keys sent in a single fetch.
+	loaderMaxBatch = 100
+)
+
+// loader batches the keys requested by concurrently running resolvers into
+// a single fetch, and caches the results, errors included, for the rest of
+// the request.
+type loader[K comparable, V any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+	wait  time.Duration
+
+	mu    sync.Mutex
+	cache map[K]*loaderResult[V]
+	batch *loaderBatch[K, V]
+}
+
+type loaderResult[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type loaderBatch[K comparable, V any] struct {
+	keys       []K
+	results    []*loaderResult[V]
+	dispatched bool
+}
+
+// newLoader returns a loader whose fetches run under ctx, normally the
+// context of the HTTP request, rather than that of whichever resolver
+// happened to start the batch.
+func newLoader[K comparable, V any](ctx context.Context, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{ctx: ctx, fetch: fetch, wait: loaderWait, cache: map[K]*loaderResult[V]{}}
+}
+
+// Load returns the value for key, fetching it together with the other keys
+// requested at about the same time. Keys missing from the fetch result get
+// the zero value.
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	res, ok := l.cache[key]
+	if !ok {
+		res = &loaderResult[V]{done: make(chan struct{})}
+		l.cache[key] = res
+		l.enqueue(key, res)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-res.done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// enqueue adds key to the pending batch. It must be called with l.mu held.
+func (l *loader[K, V]) enqueue(key K, res *loaderResult[V]) {
+	if l.batch == nil {
+		b := &loaderBatch[K, V]{}
+		l.batch = b
+		time.AfterFunc(l.wait, func() { l.dispatch(b) })
+	}
+
+	b := l.batch
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, res)
+	if len(b.keys) >= loaderMaxBatch {
+		l.batch = nil
+		b.dispatched = true
+		go l.run(b)
+	}
+}
+
+func (l *loader[K, V]) dispatch(b *loaderBatch[K, V]) {
+	l.mu.Lock()
+	if b.dispatched {
+		l.mu.Unlock()
+		return
+	}
+	b.dispatched = true
+	if l.batch == b {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	l.run(b)
+}
+
+func (l *loader[K, V]) run(b *loaderBatch[K, V]) {
+	values, err := l.fetch(l.ctx, b.keys)
+	for i, key := range b.keys {
+		res := b.results[i]
+		res.value, res.err = values[key], err
+		close(res.done)
+	}
+}
+
+// loaders holds the request-scoped loaders used by the resolvers.
+type loaders struct {
+	ordersByAccount *loader[string, []order.Order]
+	// categories holds the whole category tree under a single key, fetched
+	// once for all the products of a request.
+	categories *loader[struct{}, []catalog.Category]
+}
+
+type loadersKey struct{}
+
+func newLoaders(ctx context.Context, s *Server) *loaders {
+	return &loaders{
+		ordersByAccount: newLoader(ctx, func(ctx context.Context, accountIDs []string) (map[string][]order.Order, error) {
+			ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+			defer cancel()
+			return s.orderClient.GetOrdersForAccounts(ctx, accountIDs)
+		}),
+		categories: newLoader(ctx, func(ctx context.Context, _ []struct{}) (map[struct{}][]catalog.Category, error) {
+			ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+			defer cancel()
+			categories, err := s.catalogClient.GetCategories(ctx)
+			return map[struct{}][]catalog.Category{{}: categories}, err
+		}),
+	}
+}
+
+// withLoaders gives every request its own loaders, so results are never
+// shared between requests.
+func (s *Server) withLoaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), loadersKey{}, newLoaders(r.Context(), s))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// loadersFor returns the loaders of the current request. Outside of
+// withLoaders it returns fresh ones, which still work but do not batch
+// across resolvers.
+func loadersFor(ctx context.Context, s *Server) *loaders {
+	if l, ok := ctx.Value(loadersKey{}).(*loaders); ok {
+		return l
+	}
+	return newLoaders(ctx, s)
+}
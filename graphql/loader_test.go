@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoader_BatchesConcurrentLoads(t *testing.T) {
+	var mu sync.Mutex
+	var calls [][]string
+	l := newLoader(context.Background(), func(ctx context.Context, keys []string) (map[string]string, error) {
+		mu.Lock()
+		calls = append(calls, keys)
+		mu.Unlock()
+		values := map[string]string{}
+		for _, k := range keys {
+			values[k] = "v" + k
+		}
+		return values, nil
+	})
+	// Leave the goroutines below plenty of time to join the batch.
+	l.wait = 100 * time.Millisecond
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprint(i % 5)
+			v, err := l.Load(context.Background(), key)
+			if err != nil || v != "v"+key {
+				t.Errorf("Load(%s) = %q, %v", key, v, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(calls) != 1 || len(calls[0]) != 5 {
+		t.Fatalf("expected one fetch of 5 distinct keys, got %v", calls)
+	}
+
+	// Later loads of the same keys are served from the cache.
+	if v, err := l.Load(context.Background(), "3"); err != nil || v != "v3" {
+		t.Errorf("expected cached v3, got %q, %v", v, err)
+	}
+	if len(calls) != 1 {
+		t.Errorf("expected no further fetches, got %v", calls)
+	}
+}
+
+func TestLoader_SplitsLargeBatches(t *testing.T) {
+	var mu sync.Mutex
+	sizes := []int{}
+	l := newLoader(context.Background(), func(ctx context.Context, keys []int) (map[int]int, error) {
+		mu.Lock()
+		sizes = append(sizes, len(keys))
+		mu.Unlock()
+		return nil, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < loaderMaxBatch+1; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l.Load(context.Background(), i)
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, n := range sizes {
+		if n > loaderMaxBatch {
+			t.Errorf("batch of %d exceeds the limit", n)
+		}
+		total += n
+	}
+	if total != loaderMaxBatch+1 {
+		t.Errorf("expected every key fetched once, got batches %v", sizes)
+	}
+}
+
+func TestLoader_SharesFetchError(t *testing.T) {
+	boom := errors.New("boom")
+	l := newLoader(context.Background(), func(ctx context.Context, keys []string) (map[string]int, error) {
+		return nil, boom
+	})
+
+	if _, err := l.Load(context.Background(), "a"); !errors.Is(err, boom) {
+		t.Fatalf("expected fetch error, got %v", err)
+	}
+}
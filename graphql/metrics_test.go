@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsRecorder_IgnoresOperationNames(t *testing.T) {
+	h := handler.New((&Server{}).ToExecutableSchema())
+	h.AddTransport(transport.POST{})
+	h.Use(metricsRecorder{})
+	series := testutil.CollectAndCount(operationDuration)
+
+	for i := range 3 {
+		body := fmt.Sprintf(`{"query":"query Op%d { __typename }"}`, i)
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "errors") {
+			t.Fatalf("query failed: %d %s", rec.Code, rec.Body)
+		}
+	}
+
+	if n := testutil.CollectAndCount(operationDuration) - series; n > 1 {
+		t.Errorf("expected the operations to share one series, got %d new ones", n)
+	}
+}
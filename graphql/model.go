@@ -1,7 +1,120 @@
-package main
+package graphql
+
+import (
+	"strings"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/account"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/order"
+)
 
 type Account struct {
 	ID     string  `json:"id"`
 	Name   string  `json:"name"`
+	Role   Role    `json:"role"`
 	Orders []Order `json:"orders"`
 }
+
+func toAccount(a *account.Account) *Account {
+	return &Account{ID: a.ID, Name: a.Name, Role: toRole(a.Role)}
+}
+
+func toRole(r auth.Role) Role {
+	if r == "" {
+		return RoleCustomer
+	}
+	return Role(strings.ToUpper(string(r)))
+}
+
+func (r Role) toDomain() auth.Role {
+	return auth.Role(strings.ToLower(string(r)))
+}
+
+func toAuthPayload(a *account.Account, tokens *auth.TokenPair) *AuthPayload {
+	return &AuthPayload{
+		Account:      toAccount(a),
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}
+}
+
+type Product struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	Version     string      `json:"version"`
+	Deleted     bool        `json:"deleted"`
+	// CategoryIDs are resolved into categories by Product.categories.
+	CategoryIDs []string `json:"-"`
+}
+
+func toProduct(p *catalog.Product) *Product {
+	return &Product{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Version:     p.Version,
+		Deleted:     p.Deleted,
+		CategoryIDs: p.CategoryIDs,
+	}
+}
+
+func toCategory(c catalog.Category) *Category {
+	category := &Category{ID: c.ID, Name: c.Name, Slug: c.Slug, Path: c.Path}
+	if c.ParentID != "" {
+		category.ParentID = &c.ParentID
+	}
+	return category
+}
+
+func toOrder(o order.Order) *Order {
+	products := []*OrderedProduct{}
+	for _, p := range o.Products {
+		products = append(products, &OrderedProduct{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       p.Price,
+			Quantity:    int(p.Quantity),
+		})
+	}
+
+	return &Order{
+		ID:         o.ID,
+		AccountID:  o.AccountID,
+		CreatedAt:  o.CreatedAt,
+		TotalPrice: o.TotalPrice,
+		Status:     toOrderStatus(o.Status),
+		Products:   products,
+	}
+}
+
+func toOrderStatus(s order.OrderStatus) OrderStatus {
+	return OrderStatus(strings.ToUpper(string(s)))
+}
+
+func (e OrderStatus) toDomain() order.OrderStatus {
+	return order.OrderStatus(strings.ToLower(string(e)))
+}
+
+func (f OrderFilter) toDomain() order.OrderFilter {
+	filter := order.OrderFilter{MinTotal: f.MinTotal}
+	if f.AccountID != nil {
+		filter.AccountID = *f.AccountID
+	}
+	if f.CreatedAfter != nil {
+		filter.CreatedAfter = *f.CreatedAfter
+	}
+	if f.CreatedBefore != nil {
+		filter.CreatedBefore = *f.CreatedBefore
+	}
+	for _, st := range f.Status {
+		filter.Statuses = append(filter.Statuses, st.toDomain())
+	}
+	return filter
+}
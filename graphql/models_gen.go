@@ -1,15 +1,50 @@
 // Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
 
-package main
+package graphql
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
 	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 )
 
 type AccountInput struct {
 	Name string `json:"name"`
 }
 
+type AuthPayload struct {
+	Account *Account `json:"account"`
+	// Sent as a bearer token in the Authorization header.
+	AccessToken string `json:"accessToken"`
+	// Exchanged for a new pair with refreshToken once accessToken expires.
+	RefreshToken string    `json:"refreshToken"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// A node of the category tree.
+type Category struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Unique among the children of the same parent.
+	Slug string `json:"slug"`
+	// Joins the slugs from the root down to the category, e.g. clothing/shoes/running.
+	Path string `json:"path"`
+	// Null for top-level categories.
+	ParentID *string `json:"parentId,omitempty"`
+}
+
+type CategoryInput struct {
+	Name string `json:"name"`
+	// Derived from name if not set, e.g. running-shoes for Running Shoes.
+	Slug *string `json:"slug,omitempty"`
+	// Null for a top-level category.
+	ParentID *string `json:"parentId,omitempty"`
+}
+
 type CheckStockInput struct {
 	Ids []string `json:"ids"`
 }
@@ -19,22 +54,58 @@ type Mutation struct {
 
 type Order struct {
 	ID         string            `json:"id"`
+	AccountID  string            `json:"accountId"`
 	CreatedAt  time.Time         `json:"createdAt"`
-	TotalPrice float64           `json:"totalPrice"`
+	TotalPrice money.Money       `json:"totalPrice"`
+	Status     OrderStatus       `json:"status"`
 	Products   []*OrderedProduct `json:"products"`
 }
 
+// Orders, newest first.
+type OrderConnection struct {
+	Edges    []*OrderEdge `json:"edges"`
+	PageInfo *PageInfo    `json:"pageInfo"`
+}
+
+type OrderEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Order `json:"node"`
+}
+
+// Every field that is set must match.
+type OrderFilter struct {
+	AccountID *string `json:"accountId,omitempty"`
+	// Inclusive.
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Exclusive.
+	CreatedBefore *time.Time    `json:"createdBefore,omitempty"`
+	Status        []OrderStatus `json:"status,omitempty"`
+	// Matches orders in the same currency totalling at least this amount.
+	MinTotal *money.Money `json:"minTotal,omitempty"`
+}
+
 type OrderInput struct {
-	AccountID string                 `json:"accountId"`
+	// Defaults to the authenticated account, the only one allowed.
+	AccountID *string                `json:"accountId,omitempty"`
 	Products  []*OrderedProductInput `json:"products"`
+	// Retrying createOrder with the same idempotencyKey returns the order
+	// placed by the first request instead of placing another one.
+	IdempotencyKey *string `json:"idempotencyKey,omitempty"`
+}
+
+type OrderStatusChange struct {
+	OrderID   string      `json:"orderId"`
+	From      OrderStatus `json:"from"`
+	To        OrderStatus `json:"to"`
+	ChangedAt time.Time   `json:"changedAt"`
 }
 
 type OrderedProduct struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	Quantity    int         `json:"quantity"`
 }
 
 type OrderedProductInput struct {
@@ -46,33 +117,170 @@ type OutOfStock struct {
 	Ids []string `json:"ids"`
 }
 
+type PageInfo struct {
+	EndCursor   *string `json:"endCursor,omitempty"`
+	HasNextPage bool    `json:"hasNextPage"`
+}
+
 type PaginationInput struct {
 	Skip int `json:"skip"`
 	Take int `json:"take"`
 }
 
-type Product struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-}
-
 type ProductInResponse struct {
 	Product  *Product `json:"product"`
 	Quantity int      `json:"quantity"`
 }
 
 type ProductInput struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	// Files the product under these categories.
+	CategoryIds []string `json:"categoryIds,omitempty"`
+}
+
+// Fields left out are kept.
+type ProductUpdateInput struct {
+	Name        *string      `json:"name,omitempty"`
+	Description *string      `json:"description,omitempty"`
+	Price       *money.Money `json:"price,omitempty"`
+	// Replaces the categories of the product.
+	CategoryIds []string `json:"categoryIds,omitempty"`
 }
 
 type Query struct {
 }
 
+type RegisterInput struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
 type UpdateStocksRequestInput struct {
 	Ids    []string `json:"ids"`
 	Deltas []int    `json:"deltas"`
+	// Retrying with the same idempotencyKey applies the deltas only once.
+	IdempotencyKey *string `json:"idempotencyKey,omitempty"`
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "PENDING"
+	OrderStatusPaid      OrderStatus = "PAID"
+	OrderStatusShipped   OrderStatus = "SHIPPED"
+	OrderStatusDelivered OrderStatus = "DELIVERED"
+	OrderStatusCancelled OrderStatus = "CANCELLED"
+	OrderStatusRefunded  OrderStatus = "REFUNDED"
+)
+
+var AllOrderStatus = []OrderStatus{
+	OrderStatusPending,
+	OrderStatusPaid,
+	OrderStatusShipped,
+	OrderStatusDelivered,
+	OrderStatusCancelled,
+	OrderStatusRefunded,
+}
+
+func (e OrderStatus) IsValid() bool {
+	switch e {
+	case OrderStatusPending, OrderStatusPaid, OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled, OrderStatusRefunded:
+		return true
+	}
+	return false
+}
+
+func (e OrderStatus) String() string {
+	return string(e)
+}
+
+func (e *OrderStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderStatus", str)
+	}
+	return nil
+}
+
+func (e OrderStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OrderStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OrderStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Role string
+
+const (
+	RoleCustomer Role = "CUSTOMER"
+	RoleMerchant Role = "MERCHANT"
+	RoleAdmin    Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleCustomer,
+	RoleMerchant,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleCustomer, RoleMerchant, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Role) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Role) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
 }
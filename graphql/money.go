@@ -0,0 +1,45 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+)
+
+// MarshalMoney writes a Money scalar as a decimal amount followed by its
+// currency code, e.g. "12.34 USD".
+func MarshalMoney(m money.Money) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(m.String()))
+	})
+}
+
+// UnmarshalMoney accepts "12.34 USD", or a bare amount in the default
+// currency. Floats are rejected since they may not be exact.
+func UnmarshalMoney(v any) (money.Money, error) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	case int:
+		s = strconv.Itoa(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	default:
+		return money.Money{}, fmt.Errorf("%w: money must be a string such as \"12.34 USD\", got %T", money.ErrInvalidAmount, v)
+	}
+
+	amount, currency, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		currency = money.DefaultCurrency
+	}
+
+	return money.Parse(amount, strings.TrimSpace(currency))
+}
@@ -1,4 +1,4 @@
-package main
+package graphql
 
 import (
 	"context"
@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/order"
 )
 
@@ -17,6 +18,45 @@ type mutationResolver struct {
 	server *Server
 }
 
+func (r *mutationResolver) Register(ctx context.Context, in RegisterInput) (*AuthPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, tokens, err := r.server.accountClient.Register(ctx, in.Name, in.Email, in.Password)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toAuthPayload(a, tokens), nil
+}
+
+func (r *mutationResolver) Login(ctx context.Context, email string, password string) (*AuthPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, tokens, err := r.server.accountClient.Login(ctx, email, password)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toAuthPayload(a, tokens), nil
+}
+
+func (r *mutationResolver) RefreshToken(ctx context.Context, token string) (*AuthPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, tokens, err := r.server.accountClient.RefreshToken(ctx, token)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toAuthPayload(a, tokens), nil
+}
+
 func (r *mutationResolver) CreateAccount(ctx context.Context, in AccountInput) (*Account, error) {
 	log.Printf("CreateAccount called with name: %s", in.Name)
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
@@ -29,10 +69,20 @@ func (r *mutationResolver) CreateAccount(ctx context.Context, in AccountInput) (
 	}
 
 	log.Printf("Account created successfully: ID=%s, Name=%s", account.ID, account.Name)
-	return &Account{
-		ID:   account.ID,
-		Name: account.Name,
-	}, nil
+	return toAccount(account), nil
+}
+
+func (r *mutationResolver) SetAccountRole(ctx context.Context, id string, role Role) (*Account, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, err := r.server.accountClient.SetRole(ctx, id, role.toDomain())
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toAccount(a), nil
 }
 
 func (r *mutationResolver) CreateProduct(ctx context.Context, in ProductInput) (*Product, error) {
@@ -41,24 +91,90 @@ func (r *mutationResolver) CreateProduct(ctx context.Context, in ProductInput) (
 	defer cancel()
 
 	log.Printf("Calling catalog service...")
-	product, err := r.server.catalogClient.PostProduct(ctx, in.Name, in.Description, in.Price)
+	product, err := r.server.catalogClient.PostProduct(ctx, in.Name, in.Description, in.Price, in.CategoryIds)
 	if err != nil {
 		log.Printf("ERROR in CreateProduct: %v", err)
 		return nil, err
 	}
 
-	return &Product{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-	}, nil
+	return toProduct(product), nil
+}
+
+func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, in ProductUpdateInput, version *string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	v := ""
+	if version != nil {
+		v = *version
+	}
+	update := catalog.ProductUpdate{
+		Name:        in.Name,
+		Description: in.Description,
+		Price:       in.Price,
+	}
+	if in.CategoryIds != nil {
+		update.CategoryIDs = &in.CategoryIds
+	}
+	product, err := r.server.catalogClient.UpdateProduct(ctx, id, v, update)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toProduct(product), nil
+}
+
+func (r *mutationResolver) DeleteProduct(ctx context.Context, id string, version *string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	v := ""
+	if version != nil {
+		v = *version
+	}
+	product, err := r.server.catalogClient.DeleteProduct(ctx, id, v)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toProduct(product), nil
+}
+
+func (r *mutationResolver) CreateCategory(ctx context.Context, in CategoryInput) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	slug, parentID := "", ""
+	if in.Slug != nil {
+		slug = *in.Slug
+	}
+	if in.ParentID != nil {
+		parentID = *in.ParentID
+	}
+	c, err := r.server.catalogClient.PostCategory(ctx, in.Name, slug, parentID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return toCategory(*c), nil
 }
 
 func (r *mutationResolver) CreateOrder(ctx context.Context, in OrderInput) (*Order, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	id, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accountID := id.AccountID
+	if in.AccountID != nil && *in.AccountID != accountID {
+		return nil, ErrForbidden
+	}
+
 	var products []order.OrderedProduct
 	for _, p := range in.Products {
 		if p.Quantity <= 0 {
@@ -70,7 +186,12 @@ func (r *mutationResolver) CreateOrder(ctx context.Context, in OrderInput) (*Ord
 		})
 	}
 
-	order, err := r.server.orderClient.PostOrder(ctx, in.AccountID, products)
+	idempotencyKey := ""
+	if in.IdempotencyKey != nil {
+		idempotencyKey = *in.IdempotencyKey
+	}
+
+	order, err := r.server.orderClient.PostOrder(ctx, accountID, products, idempotencyKey)
 	if err != nil {
 		log.Println(err)
 		return nil, err
@@ -78,8 +199,10 @@ func (r *mutationResolver) CreateOrder(ctx context.Context, in OrderInput) (*Ord
 
 	return &Order{
 		ID:         order.ID,
+		AccountID:  order.AccountID,
 		CreatedAt:  order.CreatedAt,
 		TotalPrice: order.TotalPrice,
+		Status:     toOrderStatus(order.Status),
 	}, nil
 }
 
@@ -92,7 +215,12 @@ func (r *mutationResolver) UpdateStock(ctx context.Context, requests UpdateStock
 		delatas = append(delatas, int32(d))
 	}
 
-	outOfStock, err := r.server.inventoryClient.UpdateStock(ctx, requests.Ids, delatas)
+	idempotencyKey := ""
+	if requests.IdempotencyKey != nil {
+		idempotencyKey = *requests.IdempotencyKey
+	}
+
+	outOfStock, err := r.server.inventoryClient.UpdateStock(ctx, requests.Ids, delatas, idempotencyKey)
 	if err != nil {
 		log.Println(err)
 		return nil, err
@@ -100,3 +228,39 @@ func (r *mutationResolver) UpdateStock(ctx context.Context, requests UpdateStock
 
 	return &OutOfStock{Ids: outOfStock}, nil
 }
+
+func (r *mutationResolver) UpdateOrderStatus(ctx context.Context, id string, status OrderStatus) (*OrderStatusChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	change, err := r.server.orderClient.UpdateOrderStatus(ctx, id, status.toDomain())
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return &OrderStatusChange{
+		OrderID:   change.OrderID,
+		From:      toOrderStatus(change.From),
+		To:        toOrderStatus(change.To),
+		ChangedAt: change.ChangedAt,
+	}, nil
+}
+
+func (r *mutationResolver) CancelOrder(ctx context.Context, id string) (*OrderStatusChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	change, err := r.server.orderClient.CancelOrder(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return &OrderStatusChange{
+		OrderID:   change.OrderID,
+		From:      toOrderStatus(change.From),
+		To:        toOrderStatus(change.To),
+		ChangedAt: change.ChangedAt,
+	}, nil
+}
@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+)
+
+type productResolver struct {
+	server *Server
+}
+
+func (r *productResolver) Categories(ctx context.Context, obj *Product) ([]*Category, error) {
+	categories := []*Category{}
+	if len(obj.CategoryIDs) == 0 {
+		return categories, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	tree, err := loadersFor(ctx, r.server).categories.Load(ctx, struct{}{})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	for _, c := range tree {
+		if slices.Contains(obj.CategoryIDs, c.ID) {
+			categories = append(categories, toCategory(c))
+		}
+	}
+	return categories, nil
+}
@@ -1,50 +1,63 @@
-package main
+package graphql
 
 import (
 	"context"
 	"log"
 	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/order"
 )
 
 type queryResolver struct {
 	server *Server
 }
 
+// Accounts returns the account with the given id, or pages through every
+// account for admins. pagination only applies to the latter.
 func (r *queryResolver) Accounts(ctx context.Context, pagination *PaginationInput, id *string) ([]*Account, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	user, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	if id != nil {
+		if !user.Owns(*id) {
+			return nil, ErrForbidden
+		}
 		res, err := r.server.accountClient.GetAccount(ctx, *id)
 		if err != nil {
 			log.Println(err)
 			return nil, err
 		}
-		return []*Account{{
-			ID:   res.ID,
-			Name: res.Name,
-		}}, nil
+		return []*Account{toAccount(res)}, nil
 	}
 
+	if !user.Role.Includes(auth.RoleAdmin) {
+		return nil, ErrForbidden
+	}
 	skip, take := uint64(0), uint64(0)
 	if pagination != nil {
 		skip, take = pagination.bounds()
 	}
-
-	accountsList, err := r.server.accountClient.GetAccounts(ctx, skip, take)
+	res, err := r.server.accountClient.GetAccounts(ctx, skip, take)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
-	var accounts []*Account
-	for _, a := range accountsList {
-		accounts = append(accounts, &Account{ID: a.ID, Name: a.Name})
-	}
 
+	accounts := []*Account{}
+	for i := range res {
+		accounts = append(accounts, toAccount(&res[i]))
+	}
 	return accounts, nil
 }
 
-func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInput, query, id *string) ([]*ProductInResponse, error) {
+func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInput, query, id, category *string) ([]*ProductInResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
@@ -59,12 +72,7 @@ func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInpu
 		resp = append(
 			resp,
 			&ProductInResponse{
-				Product: &Product{
-					ID:          res.Product.ID,
-					Name:        res.Product.Name,
-					Description: res.Product.Description,
-					Price:       res.Product.Price,
-				},
+				Product:  toProduct(res.Product),
 				Quantity: int(res.Quantity),
 			},
 		)
@@ -81,7 +89,15 @@ func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInpu
 	if query != nil {
 		q = *query
 	}
-	productsList, err := r.server.catalogClient.GetProducts(ctx, skip, take, nil, q)
+	var (
+		productsList []catalog.ProductResponse
+		err          error
+	)
+	if category != nil {
+		productsList, err = r.server.catalogClient.GetProductsInCategory(ctx, *category, q, skip, take)
+	} else {
+		productsList, err = r.server.catalogClient.GetProducts(ctx, skip, take, nil, q)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -92,12 +108,7 @@ func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInpu
 		products = append(
 			products,
 			&ProductInResponse{
-				Product: &Product{
-					ID:          p.Product.ID,
-					Name:        p.Product.Name,
-					Description: p.Product.Description,
-					Price:       p.Product.Price,
-				},
+				Product:  toProduct(p.Product),
 				Quantity: int(p.Quantity),
 			},
 		)
@@ -106,6 +117,95 @@ func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInpu
 	return products, nil
 }
 
+func (r *queryResolver) Categories(ctx context.Context) ([]*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	tree, err := loadersFor(ctx, r.server).categories.Load(ctx, struct{}{})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	categories := []*Category{}
+	for _, c := range tree {
+		categories = append(categories, toCategory(c))
+	}
+	return categories, nil
+}
+
+func (r *queryResolver) Order(ctx context.Context, id string) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	user, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := r.server.orderClient.GetOrder(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if !user.Owns(o.AccountID) {
+		return nil, ErrForbidden
+	}
+
+	return toOrder(*o), nil
+}
+
+func (r *queryResolver) Orders(ctx context.Context, filter *OrderFilter, first *int, after *string) (*OrderConnection, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	user, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f := order.OrderFilter{}
+	if filter != nil {
+		f = filter.toDomain()
+	}
+	if !user.Role.Includes(auth.RoleAdmin) {
+		if f.AccountID != "" && f.AccountID != user.AccountID {
+			return nil, ErrForbidden
+		}
+		f.AccountID = user.AccountID
+	}
+	n := 0
+	if first != nil {
+		if *first < 0 {
+			return nil, ErrInvalidParameter
+		}
+		n = *first
+	}
+	cursor := ""
+	if after != nil {
+		cursor = *after
+	}
+
+	page, err := r.server.orderClient.ListOrders(ctx, f, n, cursor)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	conn := &OrderConnection{
+		Edges:    []*OrderEdge{},
+		PageInfo: &PageInfo{HasNextPage: page.HasNextPage},
+	}
+	for _, o := range page.Orders {
+		conn.Edges = append(conn.Edges, &OrderEdge{Cursor: order.Cursor(o.ID), Node: toOrder(o)})
+	}
+	if page.EndCursor != "" {
+		conn.PageInfo.EndCursor = &page.EndCursor
+	}
+
+	return conn, nil
+}
+
 func (p PaginationInput) bounds() (uint64, uint64) {
 	skipValue := uint64(p.Skip)
 	takeValue := uint64(p.Take)
@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/RathodViraj/go-microservice-graphql-grpc/graphql"
+
+// tracer is a gqlgen extension starting a span for every operation and,
+// within it, one for every field served by a resolver, so the calls the
+// resolvers make to the services show up under the field that made them.
+type tracer struct{}
+
+var (
+	_ graphql.HandlerExtension    = tracer{}
+	_ graphql.ResponseInterceptor = tracer{}
+	_ graphql.FieldInterceptor    = tracer{}
+)
+
+func (tracer) ExtensionName() string {
+	return "OpenTelemetry"
+}
+
+func (tracer) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (tracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+
+	var opType, opName string
+	if oc.Operation != nil {
+		opType, opName = string(oc.Operation.Operation), oc.Operation.Name
+	}
+	spanName := opName
+	if spanName == "" {
+		spanName = "anonymous"
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, opType+" "+spanName,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("graphql.operation.name", opName),
+			attribute.String("graphql.operation.type", opType),
+		),
+	)
+	defer span.End()
+
+	res := next(ctx)
+	if res != nil && len(res.Errors) != 0 {
+		span.SetStatus(codes.Error, res.Errors.Error())
+	}
+	return res
+}
+
+func (tracer) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, fc.Object+"."+fc.Field.Name,
+		trace.WithAttributes(attribute.String("graphql.field.path", fc.Path().String())),
+	)
+	defer span.End()
+
+	res, err := next(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return res, err
+}
@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
+	inventorypb "github.com/RathodViraj/go-microservice-graphql-grpc/inventory/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type stockServer struct {
+	inventorypb.UnimplementedInventoryServiceServer
+}
+
+func (stockServer) CheckStock(_ context.Context, r *inventorypb.CheckStockRequest) (*inventorypb.CheckStockResponse, error) {
+	return &inventorypb.CheckStockResponse{InStock: make([]int32, len(r.Pids))}, nil
+}
+
+func TestTracer_SpanTree(t *testing.T) {
+	exp := tracing.NewInMemory()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(tracing.ServerOption())
+	inventorypb.RegisterInventoryServiceServer(srv, stockServer{})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	client, err := inventory.NewClient("passthrough:///bufnet", grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	h := handler.New((&Server{inventoryClient: client}).ToExecutableSchema())
+	h.AddTransport(transport.POST{})
+	h.Use(tracer{})
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"query Stock { checkStock(pids: {ids: [\"p1\"]}) }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "errors") {
+		t.Fatalf("query failed: %d %s", rec.Code, rec.Body)
+	}
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, s := range exp.GetSpans() {
+		spans[s.Name+" "+s.SpanKind.String()] = s
+	}
+	operation, ok := spans["query Stock server"]
+	if !ok {
+		t.Fatalf("no operation span in %v", spanNames(exp))
+	}
+	field := spans["Query.checkStock internal"]
+	rpcClient := spans["pb.InventoryService/CheckStock client"]
+	rpcServer := spans["pb.InventoryService/CheckStock server"]
+
+	// Each span is a child of the one before it, across the gRPC call.
+	chain := []tracetest.SpanStub{operation, field, rpcClient, rpcServer}
+	for i := 1; i < len(chain); i++ {
+		parent, child := chain[i-1], chain[i]
+		if child.Parent.SpanID() != parent.SpanContext.SpanID() {
+			t.Errorf("expected %q to be a child of %q, got parent %v in %v", child.Name, parent.Name, child.Parent.SpanID(), spanNames(exp))
+		}
+		if child.SpanContext.TraceID() != operation.SpanContext.TraceID() {
+			t.Errorf("expected %q to be part of the operation's trace", child.Name)
+		}
+	}
+}
+
+func spanNames(exp *tracetest.InMemoryExporter) []string {
+	var names []string
+	for _, s := range exp.GetSpans() {
+		names = append(names, s.Name+"/"+s.SpanKind.String())
+	}
+	return names
+}
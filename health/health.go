@@ -0,0 +1,128 @@
+// Package health reports whether the services can do their job, using the
+// standard grpc.health.v1 protocol.
+package health
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// CheckInterval is how often Serve runs its checks.
+	CheckInterval = 10 * time.Second
+	// CheckTimeout bounds a single run of a check.
+	CheckTimeout = 3 * time.Second
+)
+
+// Check reports whether a dependency, such as a database or another
+// service, is usable.
+type Check func(ctx context.Context) error
+
+// Serve registers the grpc.health.v1 service on srv. It must be called
+// after the other services are registered. Every CheckInterval until ctx is
+// done, it runs checks, keyed by a name used in logs, and reports the
+// server and each of its services as SERVING only if all of them passed.
+func Serve(ctx context.Context, srv *grpc.Server, checks map[string]Check) {
+	hs := grpchealth.NewServer()
+	services := []string{""}
+	for name := range srv.GetServiceInfo() {
+		services = append(services, name)
+	}
+	healthpb.RegisterHealthServer(srv, hs)
+
+	set := func(st healthpb.HealthCheckResponse_ServingStatus) {
+		for _, name := range services {
+			hs.SetServingStatus(name, st)
+		}
+	}
+	set(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	go func() {
+		defer hs.Shutdown()
+
+		failing := ""
+		ticker := time.NewTicker(CheckInterval)
+		defer ticker.Stop()
+		for {
+			failed := describeFailures(Run(ctx, checks))
+			if failed != failing {
+				if failed == "" {
+					log.Println("health: serving")
+				} else {
+					log.Printf("health: not serving: %s", failed)
+				}
+				failing = failed
+			}
+			if failed == "" {
+				set(healthpb.HealthCheckResponse_SERVING)
+			} else {
+				set(healthpb.HealthCheckResponse_NOT_SERVING)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Run runs checks concurrently and returns the outcome of each, nil for
+// those that passed.
+func Run(ctx context.Context, checks map[string]Check) map[string]error {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(checks))
+	for name, check := range checks {
+		go func() {
+			results <- result{name, check(ctx)}
+		}()
+	}
+
+	outcomes := make(map[string]error, len(checks))
+	for range checks {
+		r := <-results
+		outcomes[r.name] = r.err
+	}
+	return outcomes
+}
+
+func describeFailures(outcomes map[string]error) string {
+	var failed []string
+	for name, err := range outcomes {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	sort.Strings(failed)
+	return strings.Join(failed, "; ")
+}
+
+// Conn returns a Check asking the server behind conn whether it is serving.
+func Conn(conn grpc.ClientConnInterface) Check {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("status %s", res.Status)
+		}
+		return nil
+	}
+}
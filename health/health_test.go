@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func startServer(t *testing.T, checks map[string]Check) grpc.ClientConnInterface {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	Serve(ctx, srv, checks)
+	go srv.Serve(lis)
+	t.Cleanup(func() {
+		cancel()
+		srv.Stop()
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// waitFor polls check until it returns want or a second has passed.
+func waitFor(t *testing.T, check Check, wantOK bool) error {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := check(context.Background())
+		if (err == nil) == wantOK || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServe_AllChecksPass(t *testing.T) {
+	conn := startServer(t, map[string]Check{
+		"db": func(context.Context) error { return nil },
+	})
+
+	if err := waitFor(t, Conn(conn), true); err != nil {
+		t.Errorf("expected serving, got %v", err)
+	}
+}
+
+func TestServe_FailingCheck(t *testing.T) {
+	conn := startServer(t, map[string]Check{
+		"db":    func(context.Context) error { return nil },
+		"cache": func(context.Context) error { return errors.New("connection refused") },
+	})
+
+	// Give the first run time to complete, then make sure it didn't flip.
+	time.Sleep(50 * time.Millisecond)
+	if err := Conn(conn)(context.Background()); err == nil {
+		t.Error("expected not serving while a check fails")
+	}
+}
+
+func TestConn_Unreachable(t *testing.T) {
+	lis := bufconn.Listen(1024)
+	lis.Close()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := Conn(conn)(ctx); err == nil {
+		t.Error("expected an error for an unreachable server")
+	}
+}
+
+func TestRun(t *testing.T) {
+	errDown := errors.New("down")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	outcomes := Run(ctx, map[string]Check{
+		"up":   func(context.Context) error { return nil },
+		"down": func(context.Context) error { return errDown },
+		"slow": func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	if len(outcomes) != 3 {
+		t.Fatalf("expected 3 outcomes, got %v", outcomes)
+	}
+	if outcomes["up"] != nil {
+		t.Errorf("expected up to pass, got %v", outcomes["up"])
+	}
+	if !errors.Is(outcomes["down"], errDown) {
+		t.Errorf("expected down to fail with %v, got %v", errDown, outcomes["down"])
+	}
+	if !errors.Is(outcomes["slow"], context.DeadlineExceeded) {
+		t.Errorf("expected slow to time out, got %v", outcomes["slow"])
+	}
+	if got := describeFailures(outcomes); got != "down: down; slow: context deadline exceeded" {
+		t.Errorf("unexpected description %q", got)
+	}
+}
@@ -0,0 +1,44 @@
+// Package idempotency holds the conventions shared by services that accept
+// idempotency keys, so a client can retry a request without applying it twice.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataKey is the gRPC metadata entry a key can be sent in instead of the request field.
+const MetadataKey = "idempotency-key"
+
+// MaxKeyLength bounds the keys accepted from clients.
+const MaxKeyLength = 255
+
+// KeyFromContext returns requestKey if set, otherwise the key sent in the
+// incoming gRPC metadata, if any.
+func KeyFromContext(ctx context.Context, requestKey string) string {
+	if requestKey != "" {
+		return requestKey
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get(MetadataKey); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Fingerprint hashes the parts of a request so that reusing a key for a
+// different request can be detected.
+func Fingerprint(parts ...any) string {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%v\x00", p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
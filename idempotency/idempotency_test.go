@@ -0,0 +1,35 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestKeyFromContext(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "from-metadata"))
+
+	if k := KeyFromContext(ctx, "from-request"); k != "from-request" {
+		t.Errorf("expected request key to win, got %q", k)
+	}
+	if k := KeyFromContext(ctx, ""); k != "from-metadata" {
+		t.Errorf("expected metadata key, got %q", k)
+	}
+	if k := KeyFromContext(context.Background(), ""); k != "" {
+		t.Errorf("expected no key, got %q", k)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := Fingerprint("acc1", []string{"p1", "p2"}, []int32{1, 2})
+	if a != Fingerprint("acc1", []string{"p1", "p2"}, []int32{1, 2}) {
+		t.Error("expected equal requests to have equal fingerprints")
+	}
+	if a == Fingerprint("acc1", []string{"p1", "p2"}, []int32{1, 3}) {
+		t.Error("expected different requests to have different fingerprints")
+	}
+	if Fingerprint("ab", "c") == Fingerprint("a", "bc") {
+		t.Error("expected part boundaries to matter")
+	}
+}
@@ -3,8 +3,13 @@ package inventory
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/health"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/middleware"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/resilience"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -14,8 +19,12 @@ type Client struct {
 	Service pb.InventoryServiceClient
 }
 
-func NewClient(url string) (*Client, error) {
-	conn, err := grpc.NewClient(url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// NewClient connects to the service at url. opts are added to the default
+// dial options, e.g. to authenticate calls. Connections are plaintext
+// unless opts set transport credentials, such as tlsconfig's.
+func NewClient(url string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DialOption(), tracing.DialOption(), resilience.DialOption()}, opts...)
+	conn, err := grpc.NewClient(url, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect inventory client: %v", err)
 	}
@@ -28,12 +37,20 @@ func (c *Client) Close() {
 	c.Conn.Close()
 }
 
-func (c *Client) UpdateStock(ctx context.Context, pids []string, deltas []int32) ([]string, error) {
+// Ping reports whether the inventory service is serving.
+func (c *Client) Ping(ctx context.Context) error {
+	return health.Conn(c.Conn)(ctx)
+}
+
+// UpdateStock applies the deltas. Calls repeating a non-empty idempotencyKey
+// are applied only once.
+func (c *Client) UpdateStock(ctx context.Context, pids []string, deltas []int32, idempotencyKey string) ([]string, error) {
 	res, err := c.Service.UpdateStock(
 		ctx,
 		&pb.UpdateStockRequest{
-			Pids:   pids,
-			Deltas: deltas,
+			Pids:           pids,
+			Deltas:         deltas,
+			IdempotencyKey: idempotencyKey,
 		},
 	)
 	if err != nil {
@@ -47,6 +64,7 @@ func (c *Client) CheckStock(ctx context.Context, pids []string) ([]int32, error)
 	res, err := c.Service.CheckStock(
 		ctx,
 		&pb.CheckStockRequest{Pids: pids},
+		resilience.Idempotent(),
 	)
 	if err != nil {
 		return nil, err
@@ -54,3 +72,35 @@ func (c *Client) CheckStock(ctx context.Context, pids []string) ([]int32, error)
 
 	return res.InStock, nil
 }
+
+func (c *Client) ReserveStock(ctx context.Context, pids []string, quantities []int32, ttl time.Duration) (*Reservation, []string, error) {
+	res, err := c.Service.ReserveStock(
+		ctx,
+		&pb.ReserveStockRequest{
+			Pids:       pids,
+			Quantities: quantities,
+			TtlSeconds: int64(ttl / time.Second),
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(res.OutOfStock) != 0 {
+		return nil, res.OutOfStock, nil
+	}
+
+	return &Reservation{
+		ID:        res.ReservationId,
+		ExpiresAt: time.Unix(res.ExpiresAt, 0).UTC(),
+	}, nil, nil
+}
+
+func (c *Client) CommitReservation(ctx context.Context, reservationID string) error {
+	_, err := c.Service.CommitReservation(ctx, &pb.CommitReservationRequest{ReservationId: reservationID})
+	return err
+}
+
+func (c *Client) ReleaseReservation(ctx context.Context, reservationID string) error {
+	_, err := c.Service.ReleaseReservation(ctx, &pb.ReleaseReservationRequest{ReservationId: reservationID})
+	return err
+}
@@ -1,15 +1,49 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/metrics"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/serve"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tlsconfig"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
 	"github.com/kelseyhightower/envconfig"
 )
 
+// memoryURL selects the in-memory repository, for development and tests
+// without a database. Nothing stored survives a restart.
+const memoryURL = "memory://"
+
 type Config struct {
+	Port int `envconfig:"PORT"`
+	// ShutdownTimeout is how long calls in flight get to finish on SIGTERM.
+	ShutdownTimeout time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"10s"`
+	// MetricsPort serves /metrics for Prometheus.
+	MetricsPort int `envconfig:"METRICS_PORT" default:"9084"`
+	// DatabaseURL set to memoryURL keeps the stock in memory instead.
 	DatabaseURL string `envconfig:"DATABASE_URL"`
+	// JWTSecret verifies the tokens callers authenticate with. It must match
+	// the secret of the account service.
+	JWTSecret string `envconfig:"JWT_SECRET" required:"true"`
+	// TraceExporter is where spans are sent: "otlp", "stdout" or "none".
+	TraceExporter string `envconfig:"OTEL_TRACES_EXPORTER" default:"none"`
+	// TLSCertFile and TLSKeyFile are the certificate presented to callers and
+	// to the services this one calls. With TLSCAFile, the other side must
+	// present a certificate issued by that CA too. Changes to the files are
+	// picked up without a restart.
+	TLSCertFile string `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile  string `envconfig:"TLS_KEY_FILE"`
+	TLSCAFile   string `envconfig:"TLS_CA_FILE"`
+	// StockWriters are the certificate names allowed to call UpdateStock
+	// when TLSCAFile is set.
+	StockWriters []string `envconfig:"STOCK_WRITERS" default:"graphql,order"`
 }
 
 func main() {
@@ -19,23 +53,75 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// Default for local development
+	// Defaults for local development
+	if cfg.Port == 0 {
+		cfg.Port = inventory.DefaultPort
+	}
 	if cfg.DatabaseURL == "" {
 		cfg.DatabaseURL = "localhost:6379"
 	}
 
+	signer, err := auth.NewSigner([]byte(cfg.JWTSecret))
+	if err != nil {
+		log.Fatalf("invalid JWT_SECRET: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, "inventory", cfg.TraceExporter)
+	if err != nil {
+		log.Fatalf("invalid OTEL_TRACES_EXPORTER: %v", err)
+	}
+
+	opts := []serve.Option{serve.WithPort(cfg.Port), serve.WithDrainTimeout(cfg.ShutdownTimeout)}
+	certFiles := tlsconfig.Files{CertFile: cfg.TLSCertFile, KeyFile: cfg.TLSKeyFile, CAFile: cfg.TLSCAFile}
+	if certFiles.Enabled() {
+		if certFiles.CertFile == "" {
+			log.Fatal("TLS_CERT_FILE is required to serve over TLS")
+		}
+		certs, err := tlsconfig.NewReloader(certFiles)
+		if err != nil {
+			log.Fatalf("invalid TLS configuration: %v", err)
+		}
+		go certs.Run(ctx, tlsconfig.DefaultReloadInterval)
+		opts = append(opts, serve.WithServerOptions(certs.ServerOption()), serve.WithDialOptions(certs.DialOption()))
+		if certFiles.CAFile != "" {
+			opts = append(opts, inventory.StockWriters(cfg.StockWriters...))
+		}
+	}
+
+	go func() {
+		if err := metrics.ListenAndServe(ctx, cfg.MetricsPort); err != nil {
+			log.Println("metrics:", err)
+		}
+	}()
+
 	var r inventory.Repository
-	for {
-		r, err = inventory.NewRepository(cfg.DatabaseURL)
-		if err == nil {
-			break
+	if cfg.DatabaseURL == memoryURL {
+		log.Println("Storing everything in memory, nothing is kept on exit")
+		r = inventory.NewMemoryRepository()
+	} else {
+		for {
+			r, err = inventory.NewRepository(cfg.DatabaseURL)
+			if err == nil {
+				break
+			}
+			log.Println(err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(2 * time.Second):
+			}
 		}
-		log.Println(err)
-		time.Sleep(2 * time.Second)
 	}
-	defer r.Close()
 
-	log.Print("Listing on port 8084...")
+	log.Printf("Listening on port %d...", cfg.Port)
 	s := inventory.NewService(r)
-	log.Fatal(inventory.ListenGRPC(s, 8084))
+	err = inventory.ListenGRPC(ctx, s, signer, opts...)
+	r.Close()
+	shutdownTracing(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,30 @@
+-- KEYS[1]: reservation, KEYS[2]: reservation expiry index, then the
+-- inventory and reserved keys of every reserved product in turn.
+-- ARGV[1]: reservation id, ARGV[2]: now (unix ms), then the id of every
+-- reserved product, in the order of KEYS.
+-- Moves the reserved quantities out of on-hand stock for good.
+local reservation = KEYS[1]
+local expiry = redis.call("ZSCORE", KEYS[2], ARGV[1])
+
+if not expiry or tonumber(expiry) <= tonumber(ARGV[2]) then
+    return 0
+end
+
+-- The products were read before the script ran; the reservation must not
+-- have been replaced since.
+if redis.call("HLEN", reservation) ~= #ARGV - 2 then
+    return redis.error_reply("reservation changed while it was committed")
+end
+
+for i = 3, #ARGV do
+    local quantity = tonumber(redis.call("HGET", reservation, ARGV[i]))
+    if not quantity then
+        return redis.error_reply("reservation changed while it was committed")
+    end
+    redis.call("DECRBY", KEYS[2 * i - 3], quantity)
+    redis.call("DECRBY", KEYS[2 * i - 2], quantity)
+end
+redis.call("DEL", reservation)
+redis.call("ZREM", KEYS[2], ARGV[1])
+
+return 1
@@ -7,25 +7,34 @@ import (
 	"testing"
 	"time"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/idempotency"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory/pb"
+	"github.com/segmentio/ksuid"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
-func startE2EServer(t *testing.T) (string, func()) {
+// newTestRepository returns a repository on the Redis at
+// REDIS_URL_FOR_TEST, or in memory if it is unset or "memory://".
+func newTestRepository(t *testing.T) Repository {
 	url := os.Getenv("REDIS_URL_FOR_TEST")
-	if url == "" {
-		url = "redis://localhost:6379"
+	if url == "" || url == "memory://" {
+		return NewMemoryRepository()
 	}
 
 	repo, err := NewRepository(url)
 	if err != nil {
-		t.Fatalf("failed to connect to Elasticsearch: %v", err)
+		t.Fatalf("failed to connect to Redis: %v", err)
 	}
+	return repo
+}
 
+func startE2EServer(t *testing.T) (string, func()) {
+	repo := newTestRepository(t)
 	svc := NewService(repo)
 
-	lis, err := net.Listen("tcp", ":9094")
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		t.Fatalf("failed to listen: %v", err)
 	}
@@ -41,7 +50,7 @@ func startE2EServer(t *testing.T) (string, func()) {
 		lis.Close()
 	}
 
-	return "localhost:9094", cleanup
+	return lis.Addr().String(), cleanup
 }
 
 func TestE2E_UpdateInventory_Add_Success(t *testing.T) {
@@ -219,3 +228,211 @@ func TestE2E_CheckInevtory(t *testing.T) {
 		t.Errorf("Invalid output: %#v", res.InStock)
 	}
 }
+
+func TestE2E_ReserveStock_HoldsAndCommits(t *testing.T) {
+	addr, cleanup := startE2EServer(t)
+	defer cleanup()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewInventoryServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pid := ksuid.New().String()
+	if _, err := client.UpdateStock(ctx, &pb.UpdateStockRequest{Pids: []string{pid}, Deltas: []int32{5}}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := client.ReserveStock(ctx, &pb.ReserveStockRequest{Pids: []string{pid}, Quantities: []int32{3}, TtlSeconds: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ReservationId == "" || len(res.OutOfStock) != 0 {
+		t.Fatalf("expected reservation, got %#v", res)
+	}
+
+	stock, err := client.CheckStock(ctx, &pb.CheckStockRequest{Pids: []string{pid}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stock.InStock[0] != 2 {
+		t.Errorf("expected 2 available while reserved, got %d", stock.InStock[0])
+	}
+
+	// Reserved units can't be taken by a direct decrement.
+	upd, err := client.UpdateStock(ctx, &pb.UpdateStockRequest{Pids: []string{pid}, Deltas: []int32{-3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upd.OutOfStock) != 1 {
+		t.Errorf("expected decrement beyond available stock to fail")
+	}
+
+	if _, err := client.CommitReservation(ctx, &pb.CommitReservationRequest{ReservationId: res.ReservationId}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CommitReservation(ctx, &pb.CommitReservationRequest{ReservationId: res.ReservationId}); err == nil {
+		t.Error("expected second commit to fail")
+	}
+
+	stock, err = client.CheckStock(ctx, &pb.CheckStockRequest{Pids: []string{pid}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stock.InStock[0] != 2 {
+		t.Errorf("expected 2 in stock after commit, got %d", stock.InStock[0])
+	}
+}
+
+func TestE2E_ReserveStock_OutOfStock(t *testing.T) {
+	addr, cleanup := startE2EServer(t)
+	defer cleanup()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewInventoryServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pid := ksuid.New().String()
+	res, err := client.ReserveStock(ctx, &pb.ReserveStockRequest{Pids: []string{pid}, Quantities: []int32{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ReservationId != "" || len(res.OutOfStock) != 1 || res.OutOfStock[0] != pid {
+		t.Errorf("expected %s out of stock, got %#v", pid, res)
+	}
+}
+
+func TestE2E_ReleaseAndReclaimReservations(t *testing.T) {
+	repo := newTestRepository(t)
+	defer repo.Close()
+	svc := NewService(repo)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pid := ksuid.New().String()
+	if _, err := svc.UpdateStock(ctx, []string{pid}, []int32{4}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	released, _, err := svc.ReserveStock(ctx, []string{pid}, []int32{2}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ReleaseReservation(ctx, released.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ReleaseReservation(ctx, released.ID); err != ErrReservationNotFound {
+		t.Errorf("expected ErrReservationNotFound, got %v", err)
+	}
+
+	if _, _, err := svc.ReserveStock(ctx, []string{pid}, []int32{3}, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if _, err := svc.ReclaimExpiredReservations(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	stock, err := svc.CheckStock(ctx, []string{pid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stock[0] != 4 {
+		t.Errorf("expected all 4 units available again, got %d", stock[0])
+	}
+}
+
+func TestE2E_UpdateStock_IdempotencyKey(t *testing.T) {
+	addr, cleanup := startE2EServer(t)
+	defer cleanup()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewInventoryServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pid := ksuid.New().String()
+	key := ksuid.New().String()
+	for i := 0; i < 2; i++ {
+		res, err := client.UpdateStock(ctx, &pb.UpdateStockRequest{Pids: []string{pid}, Deltas: []int32{5}, IdempotencyKey: key})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.OutOfStock) != 0 {
+			t.Fatalf("unexpected out of stock: %v", res.OutOfStock)
+		}
+	}
+
+	// The key can also be sent as metadata.
+	mdCtx := metadata.AppendToOutgoingContext(ctx, idempotency.MetadataKey, key)
+	if _, err := client.UpdateStock(mdCtx, &pb.UpdateStockRequest{Pids: []string{pid}, Deltas: []int32{5}}); err != nil {
+		t.Fatal(err)
+	}
+
+	stock, err := client.CheckStock(ctx, &pb.CheckStockRequest{Pids: []string{pid}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stock.InStock[0] != 5 {
+		t.Errorf("expected the delta to be applied once, got %d in stock", stock.InStock[0])
+	}
+
+	if _, err := client.UpdateStock(ctx, &pb.UpdateStockRequest{Pids: []string{pid}, Deltas: []int32{-1}, IdempotencyKey: key}); err == nil {
+		t.Error("expected reusing the key for a different request to fail")
+	}
+}
+
+func TestE2E_UpdateStock_IdempotencyKey_ReplaysOutOfStock(t *testing.T) {
+	addr, cleanup := startE2EServer(t)
+	defer cleanup()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewInventoryServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pid := ksuid.New().String()
+	key := ksuid.New().String()
+	res, err := client.UpdateStock(ctx, &pb.UpdateStockRequest{Pids: []string{pid}, Deltas: []int32{-1}, IdempotencyKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.OutOfStock) != 1 || res.OutOfStock[0] != pid {
+		t.Fatalf("expected %s out of stock, got %v", pid, res.OutOfStock)
+	}
+
+	if _, err := client.UpdateStock(ctx, &pb.UpdateStockRequest{Pids: []string{pid}, Deltas: []int32{1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = client.UpdateStock(ctx, &pb.UpdateStockRequest{Pids: []string{pid}, Deltas: []int32{-1}, IdempotencyKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.OutOfStock) != 1 || res.OutOfStock[0] != pid {
+		t.Errorf("expected the first outcome to be replayed, got %v", res.OutOfStock)
+	}
+}
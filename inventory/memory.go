@@ -0,0 +1,209 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var errReservationExists = errors.New("reservation already exists")
+
+type memoryReservation struct {
+	// products are the reserved products in the order first reserved, and
+	// quantities how many of each are held.
+	products   []string
+	quantities map[string]int64
+	expiresAt  time.Time
+}
+
+type memoryIdempotencyRecord struct {
+	fingerprint string
+	outOfStock  []string
+	expiresAt   time.Time
+}
+
+// memoryRepository keeps stock in memory, for development and tests without
+// Redis. Every method holds the lock for its whole run, so multi-product
+// updates are all-or-nothing like the Lua scripts. Everything is lost when
+// the process exits.
+type memoryRepository struct {
+	mu           sync.Mutex
+	onHand       map[string]int64
+	reserved     map[string]int64
+	reservations map[string]*memoryReservation
+	records      map[string]memoryIdempotencyRecord
+}
+
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		onHand:       map[string]int64{},
+		reserved:     map[string]int64{},
+		reservations: map[string]*memoryReservation{},
+		records:      map[string]memoryIdempotencyRecord{},
+	}
+}
+
+func (r *memoryRepository) Close() {}
+
+func (r *memoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryRepository) UpdateStock(ctx context.Context, requests []Stock) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updateStock(requests), nil
+}
+
+// UpdateStockOnce applies the deltas unless key was already used, in which
+// case the out of stock products of the first attempt are returned again.
+func (r *memoryRepository) UpdateStockOnce(ctx context.Context, key, fingerprint string, requests []Stock, ttl time.Duration) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if rec, ok := r.records[key]; ok && now.Before(rec.expiresAt) {
+		if rec.fingerprint != fingerprint {
+			return nil, ErrIdempotencyKeyReused
+		}
+		return append([]string{}, rec.outOfStock...), nil
+	}
+
+	outOfStock := r.updateStock(requests)
+	r.records[key] = memoryIdempotencyRecord{
+		fingerprint: fingerprint,
+		outOfStock:  append([]string{}, outOfStock...),
+		expiresAt:   now.Add(ttl),
+	}
+	return outOfStock, nil
+}
+
+// updateStock applies every delta if none takes more than is available, and
+// returns the products that were short otherwise.
+func (r *memoryRepository) updateStock(requests []Stock) []string {
+	outOfStock := []string{}
+	for _, s := range requests {
+		if s.Delta < 0 && r.available(s.Product_id) < -int64(s.Delta) {
+			outOfStock = append(outOfStock, s.Product_id)
+		}
+	}
+	if len(outOfStock) > 0 {
+		return outOfStock
+	}
+
+	for _, s := range requests {
+		r.onHand[s.Product_id] += int64(s.Delta)
+	}
+	return outOfStock
+}
+
+func (r *memoryRepository) available(pid string) int64 {
+	return r.onHand[pid] - r.reserved[pid]
+}
+
+// CheckStock reports the quantity available to new orders, i.e. stock on
+// hand minus whatever is held by open reservations.
+func (r *memoryRepository) CheckStock(ctx context.Context, pids []string) ([]int32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inStock := make([]int32, 0, len(pids))
+	for _, pid := range pids {
+		inStock = append(inStock, int32(max(r.available(pid), 0)))
+	}
+	return inStock, nil
+}
+
+func (r *memoryRepository) ReserveStock(ctx context.Context, reservationID string, requests []Stock, expiresAt time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if res, ok := r.reservations[reservationID]; ok && len(res.products) > 0 {
+		return nil, errReservationExists
+	}
+
+	outOfStock := []string{}
+	for _, s := range requests {
+		if r.available(s.Product_id) < int64(s.Delta) {
+			outOfStock = append(outOfStock, s.Product_id)
+		}
+	}
+	if len(outOfStock) > 0 {
+		return outOfStock, nil
+	}
+
+	res := &memoryReservation{quantities: map[string]int64{}, expiresAt: expiresAt}
+	for _, s := range requests {
+		if _, ok := res.quantities[s.Product_id]; !ok {
+			res.products = append(res.products, s.Product_id)
+		}
+		res.quantities[s.Product_id] += int64(s.Delta)
+		r.reserved[s.Product_id] += int64(s.Delta)
+	}
+	r.reservations[reservationID] = res
+	return outOfStock, nil
+}
+
+func (r *memoryRepository) CommitReservation(ctx context.Context, reservationID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, ok := r.reservations[reservationID]
+	if !ok || !time.Now().Before(res.expiresAt) {
+		return ErrReservationNotFound
+	}
+	for _, pid := range res.products {
+		r.onHand[pid] -= res.quantities[pid]
+		r.reserved[pid] -= res.quantities[pid]
+	}
+	delete(r.reservations, reservationID)
+	return nil
+}
+
+func (r *memoryRepository) ReleaseReservation(ctx context.Context, reservationID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.release(reservationID) {
+		return ErrReservationNotFound
+	}
+	return nil
+}
+
+// ReclaimExpiredReservations releases every reservation whose TTL has passed
+// and returns how many were reclaimed. Expired idempotency records, which
+// Redis would have evicted, are dropped along the way.
+func (r *memoryRepository) ReclaimExpiredReservations(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for key, rec := range r.records {
+		if !now.Before(rec.expiresAt) {
+			delete(r.records, key)
+		}
+	}
+
+	reclaimed := 0
+	for id, res := range r.reservations {
+		if !res.expiresAt.After(now) && r.release(id) {
+			reclaimed++
+		}
+	}
+	return reclaimed, nil
+}
+
+// release returns the quantities held by a reservation to available stock,
+// reporting whether there was one.
+func (r *memoryRepository) release(reservationID string) bool {
+	res, ok := r.reservations[reservationID]
+	if !ok {
+		return false
+	}
+	for _, pid := range res.products {
+		r.reserved[pid] -= res.quantities[pid]
+	}
+	delete(r.reservations, reservationID)
+	return true
+}
@@ -0,0 +1,100 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func checkStock(t *testing.T, r Repository, pids ...string) []int32 {
+	t.Helper()
+	stock, err := r.CheckStock(context.Background(), pids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stock
+}
+
+func TestMemoryRepository_UpdateStockIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	if _, err := r.UpdateStock(ctx, []Stock{{"p1", 5}, {"p2", 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	outOfStock, err := r.UpdateStock(ctx, []Stock{{"p1", -2}, {"p2", -3}, {"p3", 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(outOfStock, []string{"p2"}) {
+		t.Errorf("expected p2 to be out of stock, got %v", outOfStock)
+	}
+	if stock := checkStock(t, r, "p1", "p2", "p3"); !slices.Equal(stock, []int32{5, 1, 0}) {
+		t.Errorf("expected nothing to change, got %v", stock)
+	}
+}
+
+func TestMemoryRepository_UpdateStockOnce(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+
+	for range 2 {
+		if _, err := r.UpdateStockOnce(ctx, "k1", "f1", []Stock{{"p1", 3}}, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stock := checkStock(t, r, "p1"); stock[0] != 3 {
+		t.Errorf("expected the retry not to be applied again, got %v", stock)
+	}
+	if _, err := r.UpdateStockOnce(ctx, "k1", "f2", []Stock{{"p1", 3}}, time.Minute); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("expected ErrIdempotencyKeyReused, got %v", err)
+	}
+}
+
+func TestMemoryRepository_Reservations(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	r.UpdateStock(ctx, []Stock{{"p1", 5}})
+
+	outOfStock, err := r.ReserveStock(ctx, "r1", []Stock{{"p1", 4}}, time.Now().Add(time.Minute))
+	if err != nil || len(outOfStock) != 0 {
+		t.Fatalf("expected the reservation to succeed, got %v: %v", outOfStock, err)
+	}
+	if outOfStock, _ := r.ReserveStock(ctx, "r2", []Stock{{"p1", 2}}, time.Now().Add(time.Minute)); !slices.Equal(outOfStock, []string{"p1"}) {
+		t.Errorf("expected reserved stock to be unavailable, got %v", outOfStock)
+	}
+	if _, err := r.ReserveStock(ctx, "r1", []Stock{{"p1", 1}}, time.Now().Add(time.Minute)); err == nil {
+		t.Error("expected reusing a reservation ID to fail")
+	}
+
+	if err := r.CommitReservation(ctx, "r1"); err != nil {
+		t.Fatal(err)
+	}
+	if stock := checkStock(t, r, "p1"); stock[0] != 1 {
+		t.Errorf("expected the committed quantity to leave stock, got %v", stock)
+	}
+	if err := r.ReleaseReservation(ctx, "r1"); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("expected ErrReservationNotFound, got %v", err)
+	}
+}
+
+func TestMemoryRepository_ReclaimExpiredReservations(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	r.UpdateStock(ctx, []Stock{{"p1", 5}})
+	r.ReserveStock(ctx, "expired", []Stock{{"p1", 2}}, time.Now().Add(-time.Second))
+	r.ReserveStock(ctx, "open", []Stock{{"p1", 1}}, time.Now().Add(time.Minute))
+
+	if err := r.CommitReservation(ctx, "expired"); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("expected an expired reservation not to be committed, got %v", err)
+	}
+	n, err := r.ReclaimExpiredReservations(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("expected one reservation to be reclaimed, got %d: %v", n, err)
+	}
+	if stock := checkStock(t, r, "p1"); stock[0] != 4 {
+		t.Errorf("expected only the open reservation to hold stock, got %v", stock)
+	}
+}
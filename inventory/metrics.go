@@ -0,0 +1,12 @@
+package inventory
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var scriptDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "inventory_redis_script_duration_seconds",
+	Help:    "Time taken by the Lua scripts updating stock and reservations, by script.",
+	Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+}, []string{"script"})
@@ -22,11 +22,14 @@ const (
 )
 
 type UpdateStockRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Pids          []string               `protobuf:"bytes,1,rep,name=pids,proto3" json:"pids,omitempty"`
-	Deltas        []int32                `protobuf:"varint,2,rep,packed,name=deltas,proto3" json:"deltas,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Pids   []string               `protobuf:"bytes,1,rep,name=pids,proto3" json:"pids,omitempty"`
+	Deltas []int32                `protobuf:"varint,2,rep,packed,name=deltas,proto3" json:"deltas,omitempty"`
+	// idempotency_key makes retries safe: a request repeating a key gets the
+	// result of the first one instead of applying the deltas again.
+	IdempotencyKey string `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *UpdateStockRequest) Reset() {
@@ -73,6 +76,13 @@ func (x *UpdateStockRequest) GetDeltas() []int32 {
 	return nil
 }
 
+func (x *UpdateStockRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
 type UpdateStockResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	OutOfStock    []string               `protobuf:"bytes,1,rep,name=out_of_stock,json=outOfStock,proto3" json:"out_of_stock,omitempty"`
@@ -205,25 +215,328 @@ func (x *CheckStockResponse) GetInStock() []int32 {
 	return nil
 }
 
+type ReserveStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pids          []string               `protobuf:"bytes,1,rep,name=pids,proto3" json:"pids,omitempty"`
+	Quantities    []int32                `protobuf:"varint,2,rep,packed,name=quantities,proto3" json:"quantities,omitempty"`
+	TtlSeconds    int64                  `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveStockRequest) Reset() {
+	*x = ReserveStockRequest{}
+	mi := &file_inventory_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveStockRequest) ProtoMessage() {}
+
+func (x *ReserveStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveStockRequest.ProtoReflect.Descriptor instead.
+func (*ReserveStockRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ReserveStockRequest) GetPids() []string {
+	if x != nil {
+		return x.Pids
+	}
+	return nil
+}
+
+func (x *ReserveStockRequest) GetQuantities() []int32 {
+	if x != nil {
+		return x.Quantities
+	}
+	return nil
+}
+
+func (x *ReserveStockRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type ReserveStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReservationId string                 `protobuf:"bytes,1,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	OutOfStock    []string               `protobuf:"bytes,2,rep,name=out_of_stock,json=outOfStock,proto3" json:"out_of_stock,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveStockResponse) Reset() {
+	*x = ReserveStockResponse{}
+	mi := &file_inventory_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveStockResponse) ProtoMessage() {}
+
+func (x *ReserveStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveStockResponse.ProtoReflect.Descriptor instead.
+func (*ReserveStockResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ReserveStockResponse) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
+func (x *ReserveStockResponse) GetOutOfStock() []string {
+	if x != nil {
+		return x.OutOfStock
+	}
+	return nil
+}
+
+func (x *ReserveStockResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type CommitReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReservationId string                 `protobuf:"bytes,1,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitReservationRequest) Reset() {
+	*x = CommitReservationRequest{}
+	mi := &file_inventory_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitReservationRequest) ProtoMessage() {}
+
+func (x *CommitReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitReservationRequest.ProtoReflect.Descriptor instead.
+func (*CommitReservationRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CommitReservationRequest) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
+type CommitReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitReservationResponse) Reset() {
+	*x = CommitReservationResponse{}
+	mi := &file_inventory_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitReservationResponse) ProtoMessage() {}
+
+func (x *CommitReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitReservationResponse.ProtoReflect.Descriptor instead.
+func (*CommitReservationResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{7}
+}
+
+type ReleaseReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReservationId string                 `protobuf:"bytes,1,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseReservationRequest) Reset() {
+	*x = ReleaseReservationRequest{}
+	mi := &file_inventory_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseReservationRequest) ProtoMessage() {}
+
+func (x *ReleaseReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseReservationRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseReservationRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReleaseReservationRequest) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
+type ReleaseReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseReservationResponse) Reset() {
+	*x = ReleaseReservationResponse{}
+	mi := &file_inventory_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseReservationResponse) ProtoMessage() {}
+
+func (x *ReleaseReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseReservationResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseReservationResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{9}
+}
+
 var File_inventory_proto protoreflect.FileDescriptor
 
 const file_inventory_proto_rawDesc = "" +
 	"\n" +
-	"\x0finventory.proto\x12\x02pb\"@\n" +
+	"\x0finventory.proto\x12\x02pb\"i\n" +
 	"\x12UpdateStockRequest\x12\x12\n" +
 	"\x04pids\x18\x01 \x03(\tR\x04pids\x12\x16\n" +
-	"\x06deltas\x18\x02 \x03(\x05R\x06deltas\"7\n" +
+	"\x06deltas\x18\x02 \x03(\x05R\x06deltas\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\"7\n" +
 	"\x13UpdateStockResponse\x12 \n" +
 	"\fout_of_stock\x18\x01 \x03(\tR\n" +
 	"outOfStock\"'\n" +
 	"\x11CheckStockRequest\x12\x12\n" +
 	"\x04pids\x18\x01 \x03(\tR\x04pids\".\n" +
 	"\x12CheckStockResponse\x12\x18\n" +
-	"\ainStock\x18\x02 \x03(\x05R\ainStock2\x93\x01\n" +
+	"\ainStock\x18\x02 \x03(\x05R\ainStock\"j\n" +
+	"\x13ReserveStockRequest\x12\x12\n" +
+	"\x04pids\x18\x01 \x03(\tR\x04pids\x12\x1e\n" +
+	"\n" +
+	"quantities\x18\x02 \x03(\x05R\n" +
+	"quantities\x12\x1f\n" +
+	"\vttl_seconds\x18\x03 \x01(\x03R\n" +
+	"ttlSeconds\"~\n" +
+	"\x14ReserveStockResponse\x12%\n" +
+	"\x0ereservation_id\x18\x01 \x01(\tR\rreservationId\x12 \n" +
+	"\fout_of_stock\x18\x02 \x03(\tR\n" +
+	"outOfStock\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"A\n" +
+	"\x18CommitReservationRequest\x12%\n" +
+	"\x0ereservation_id\x18\x01 \x01(\tR\rreservationId\"\x1b\n" +
+	"\x19CommitReservationResponse\"B\n" +
+	"\x19ReleaseReservationRequest\x12%\n" +
+	"\x0ereservation_id\x18\x01 \x01(\tR\rreservationId\"\x1c\n" +
+	"\x1aReleaseReservationResponse2\x83\x03\n" +
 	"\x10InventoryService\x12@\n" +
 	"\vUpdateStock\x12\x16.pb.UpdateStockRequest\x1a\x17.pb.UpdateStockResponse\"\x00\x12=\n" +
 	"\n" +
-	"CheckStock\x12\x15.pb.CheckStockRequest\x1a\x16.pb.CheckStockResponse\"\x00B\x04Z\x02./b\x06proto3"
+	"CheckStock\x12\x15.pb.CheckStockRequest\x1a\x16.pb.CheckStockResponse\"\x00\x12C\n" +
+	"\fReserveStock\x12\x17.pb.ReserveStockRequest\x1a\x18.pb.ReserveStockResponse\"\x00\x12R\n" +
+	"\x11CommitReservation\x12\x1c.pb.CommitReservationRequest\x1a\x1d.pb.CommitReservationResponse\"\x00\x12U\n" +
+	"\x12ReleaseReservation\x12\x1d.pb.ReleaseReservationRequest\x1a\x1e.pb.ReleaseReservationResponse\"\x00B\x04Z\x02./b\x06proto3"
 
 var (
 	file_inventory_proto_rawDescOnce sync.Once
@@ -237,20 +550,32 @@ func file_inventory_proto_rawDescGZIP() []byte {
 	return file_inventory_proto_rawDescData
 }
 
-var file_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_inventory_proto_goTypes = []any{
-	(*UpdateStockRequest)(nil),  // 0: pb.UpdateStockRequest
-	(*UpdateStockResponse)(nil), // 1: pb.UpdateStockResponse
-	(*CheckStockRequest)(nil),   // 2: pb.CheckStockRequest
-	(*CheckStockResponse)(nil),  // 3: pb.CheckStockResponse
+	(*UpdateStockRequest)(nil),         // 0: pb.UpdateStockRequest
+	(*UpdateStockResponse)(nil),        // 1: pb.UpdateStockResponse
+	(*CheckStockRequest)(nil),          // 2: pb.CheckStockRequest
+	(*CheckStockResponse)(nil),         // 3: pb.CheckStockResponse
+	(*ReserveStockRequest)(nil),        // 4: pb.ReserveStockRequest
+	(*ReserveStockResponse)(nil),       // 5: pb.ReserveStockResponse
+	(*CommitReservationRequest)(nil),   // 6: pb.CommitReservationRequest
+	(*CommitReservationResponse)(nil),  // 7: pb.CommitReservationResponse
+	(*ReleaseReservationRequest)(nil),  // 8: pb.ReleaseReservationRequest
+	(*ReleaseReservationResponse)(nil), // 9: pb.ReleaseReservationResponse
 }
 var file_inventory_proto_depIdxs = []int32{
 	0, // 0: pb.InventoryService.UpdateStock:input_type -> pb.UpdateStockRequest
 	2, // 1: pb.InventoryService.CheckStock:input_type -> pb.CheckStockRequest
-	1, // 2: pb.InventoryService.UpdateStock:output_type -> pb.UpdateStockResponse
-	3, // 3: pb.InventoryService.CheckStock:output_type -> pb.CheckStockResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
+	4, // 2: pb.InventoryService.ReserveStock:input_type -> pb.ReserveStockRequest
+	6, // 3: pb.InventoryService.CommitReservation:input_type -> pb.CommitReservationRequest
+	8, // 4: pb.InventoryService.ReleaseReservation:input_type -> pb.ReleaseReservationRequest
+	1, // 5: pb.InventoryService.UpdateStock:output_type -> pb.UpdateStockResponse
+	3, // 6: pb.InventoryService.CheckStock:output_type -> pb.CheckStockResponse
+	5, // 7: pb.InventoryService.ReserveStock:output_type -> pb.ReserveStockResponse
+	7, // 8: pb.InventoryService.CommitReservation:output_type -> pb.CommitReservationResponse
+	9, // 9: pb.InventoryService.ReleaseReservation:output_type -> pb.ReleaseReservationResponse
+	5, // [5:10] is the sub-list for method output_type
+	0, // [0:5] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -267,7 +592,7 @@ func file_inventory_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_inventory_proto_rawDesc), len(file_inventory_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,24 @@
+-- KEYS[1]: reservation, KEYS[2]: reservation expiry index, then the reserved
+-- key of every reserved product.
+-- ARGV[1]: reservation id, then the id of every reserved product, in the
+-- order of KEYS.
+-- Returns held quantities to available stock. Used both for explicit
+-- releases and for reclaiming expired reservations.
+local reservation = KEYS[1]
+
+-- The products were read before the script ran; the reservation must not
+-- have been replaced since.
+if redis.call("HLEN", reservation) ~= #ARGV - 1 then
+    return redis.error_reply("reservation changed while it was released")
+end
+
+for i = 2, #ARGV do
+    local quantity = tonumber(redis.call("HGET", reservation, ARGV[i]))
+    if not quantity then
+        return redis.error_reply("reservation changed while it was released")
+    end
+    redis.call("DECRBY", KEYS[i + 1], quantity)
+end
+redis.call("DEL", reservation)
+
+return redis.call("ZREM", KEYS[2], ARGV[1])
@@ -3,25 +3,53 @@ package inventory
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
+var (
+	ErrReservationNotFound  = errors.New("reservation not found or expired")
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+)
+
 type Repository interface {
 	Close()
+	Ping(ctx context.Context) error
 	UpdateStock(ctx context.Context, requests []Stock) ([]string, error)
+	UpdateStockOnce(ctx context.Context, key, fingerprint string, requests []Stock, ttl time.Duration) ([]string, error)
 	CheckStock(ctx context.Context, pids []string) ([]int32, error)
+	ReserveStock(ctx context.Context, reservationID string, requests []Stock, expiresAt time.Time) ([]string, error)
+	CommitReservation(ctx context.Context, reservationID string) error
+	ReleaseReservation(ctx context.Context, reservationID string) error
+	ReclaimExpiredReservations(ctx context.Context) (int, error)
 }
 
+// expiryKey indexes reservations by the unix millisecond they expire at.
+const expiryKey = "reservations:expiry"
+
+func stockKey(pid string) string       { return "inventory:" + pid }
+func reservedKey(pid string) string    { return "reserved:" + pid }
+func reservationKey(id string) string  { return "reservation:" + id }
+func idempotencyKey(key string) string { return "idempotency:" + key }
+
 type redisRepository struct {
-	client *redis.Client
-	script *redis.Script
+	client        *redis.Client
+	script        *redis.Script
+	reserveScript *redis.Script
+	commitScript  *redis.Script
+	releaseScript *redis.Script
 }
 
+// NewRepository returns a repository on the Redis at redisURL, which must be
+// a single node: the scripts update the keys of several products at once,
+// which Redis Cluster refuses unless they hash to the same slot.
 func NewRepository(redisURL string) (Repository, error) {
 	script := redis.NewScript(script)
 	if script == nil {
@@ -48,9 +76,16 @@ func NewRepository(redisURL string) (Repository, error) {
 		},
 	)
 
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		return nil, fmt.Errorf("couldn't instrument redis client: %w", err)
+	}
+
 	return &redisRepository{
-		client: client,
-		script: script,
+		client:        client,
+		script:        script,
+		reserveScript: redis.NewScript(reserveScript),
+		commitScript:  redis.NewScript(commitScript),
+		releaseScript: redis.NewScript(releaseScript),
 	}, nil
 }
 
@@ -58,65 +93,225 @@ func (r *redisRepository) Close() {
 	r.client.Close()
 }
 
+func (r *redisRepository) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// run runs script, recording how long it took under name.
+func (r *redisRepository) run(ctx context.Context, name string, script *redis.Script, keys []string, args ...any) *redis.Cmd {
+	start := time.Now()
+	cmd := script.Run(ctx, r.client, keys, args...)
+	scriptDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	return cmd
+}
+
 func (r *redisRepository) UpdateStock(ctx context.Context, requests []Stock) ([]string, error) {
-	var keys []string
-	var args []int32
+	keys, args := updateStockArgs(requests)
+
+	res, err := r.run(ctx, "update_stock", r.script, keys, args...).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	return trimInventoryKeys(res), nil
+}
+
+// UpdateStockOnce applies the deltas unless key was already used, in which
+// case the out of stock products of the first attempt are returned again.
+func (r *redisRepository) UpdateStockOnce(ctx context.Context, key, fingerprint string, requests []Stock, ttl time.Duration) ([]string, error) {
+	keys, args := updateStockArgs(requests)
+	keys = append(keys, idempotencyKey(key))
+	args = append(args, fingerprint, ttl.Milliseconds())
+
+	res, err := r.run(ctx, "update_stock", r.script, keys, args...).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 || res[0] != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	return trimInventoryKeys(res[1:]), nil
+}
+
+func updateStockArgs(requests []Stock) ([]string, []interface{}) {
+	keys := make([]string, 0, 2*len(requests)+1)
+	args := make([]interface{}, 0, len(requests)+2)
 	for _, s := range requests {
-		key := fmt.Sprintf("inventory:%s", s.Product_id)
-		keys = append(keys, key)
+		keys = append(keys, stockKey(s.Product_id))
 		args = append(args, s.Delta)
 	}
+	for _, s := range requests {
+		keys = append(keys, reservedKey(s.Product_id))
+	}
+	return keys, args
+}
+
+func trimInventoryKeys(keys []string) []string {
+	outOfStock := make([]string, 0, len(keys))
+	for _, key := range keys {
+		outOfStock = append(outOfStock, strings.TrimPrefix(key, stockKey("")))
+	}
+	return outOfStock
+}
+
+// CheckStock reports the quantity available to new orders, i.e. stock on
+// hand minus whatever is held by open reservations.
+func (r *redisRepository) CheckStock(ctx context.Context, pids []string) ([]int32, error) {
+	inStock := []int32{}
+	if len(pids) == 0 {
+		return inStock, nil
+	}
 
-	interfaceArgs := make([]interface{}, len(args))
-	for i, v := range args {
-		interfaceArgs[i] = v
+	keys := make([]string, 0, 2*len(pids))
+	for _, id := range pids {
+		keys = append(keys, stockKey(id))
+	}
+	for _, id := range pids {
+		keys = append(keys, reservedKey(id))
 	}
 
-	res, err := r.script.Run(
-		ctx,
-		r.client,
-		keys,
-		interfaceArgs...,
-	).Result()
+	values, err := r.client.MGet(ctx, keys...).Result()
 	if err != nil {
 		return nil, err
 	}
 
-	switch v := res.(type) {
-	case string:
-		return nil, nil
-	case []any:
-		out_of_stock := make([]string, 0, len(v))
-		for _, x := range v {
-			out_of_stock = append(out_of_stock, strings.TrimPrefix(x.(string), "inventory:"))
+	for i := range pids {
+		available := parseCount(values[i]) - parseCount(values[len(pids)+i])
+		if available < 0 {
+			available = 0
 		}
-		return out_of_stock, nil
-	default:
+		inStock = append(inStock, available)
 	}
 
-	return nil, nil
+	return inStock, nil
 }
 
-func (r *redisRepository) CheckStock(ctx context.Context, pids []string) ([]int32, error) {
-	inStock := []int32{}
-	base := "inventory:"
-	for _, id := range pids {
-		q, err := r.client.Get(ctx, base+id).Result()
+func parseCount(v any) int32 {
+	s, ok := v.(string)
+	if !ok {
+		return 0 // missing key means out of stock
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return int32(n)
+}
+
+func (r *redisRepository) ReserveStock(ctx context.Context, reservationID string, requests []Stock, expiresAt time.Time) ([]string, error) {
+	keys := make([]string, 0, 2*len(requests)+2)
+	args := []interface{}{reservationID, expiresAt.UnixMilli()}
+	for _, s := range requests {
+		keys = append(keys, stockKey(s.Product_id))
+		args = append(args, s.Delta)
+	}
+	for _, s := range requests {
+		keys = append(keys, reservedKey(s.Product_id))
+		args = append(args, s.Product_id)
+	}
+	keys = append(keys, reservationKey(reservationID), expiryKey)
+
+	res, err := r.run(ctx, "reserve", r.reserveScript, keys, args...).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	return trimInventoryKeys(res), nil
+}
+
+// reservedProducts returns the products held by a reservation, as the
+// scripts must be given their keys up front.
+func (r *redisRepository) reservedProducts(ctx context.Context, reservationID string) ([]string, error) {
+	return r.client.HKeys(ctx, reservationKey(reservationID)).Result()
+}
+
+func (r *redisRepository) CommitReservation(ctx context.Context, reservationID string) error {
+	pids, err := r.reservedProducts(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	keys := []string{reservationKey(reservationID), expiryKey}
+	args := []interface{}{reservationID, time.Now().UnixMilli()}
+	for _, pid := range pids {
+		keys = append(keys, stockKey(pid), reservedKey(pid))
+		args = append(args, pid)
+	}
+
+	ok, err := r.run(ctx, "commit", r.commitScript, keys, args...).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrReservationNotFound
+	}
+	return nil
+}
+
+func (r *redisRepository) ReleaseReservation(ctx context.Context, reservationID string) error {
+	released, err := r.release(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrReservationNotFound
+	}
+	return nil
+}
+
+// release returns the stock held by a reservation and reports whether it was
+// still open.
+func (r *redisRepository) release(ctx context.Context, reservationID string) (bool, error) {
+	pids, err := r.reservedProducts(ctx, reservationID)
+	if err != nil {
+		return false, err
+	}
+	keys := []string{reservationKey(reservationID), expiryKey}
+	args := []interface{}{reservationID}
+	for _, pid := range pids {
+		keys = append(keys, reservedKey(pid))
+		args = append(args, pid)
+	}
+
+	n, err := r.run(ctx, "release", r.releaseScript, keys, args...).Int()
+	return n == 1, err
+}
+
+// ReclaimExpiredReservations releases every reservation whose TTL has passed
+// and returns how many were reclaimed.
+func (r *redisRepository) ReclaimExpiredReservations(ctx context.Context) (int, error) {
+	ids, err := r.client.ZRangeByScore(ctx, expiryKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	reclaimed := 0
+	for _, id := range ids {
+		released, err := r.release(ctx, id)
 		if err != nil {
-			inStock = append(inStock, 0) // return out of stock
+			return reclaimed, err
 		}
-		q_int, err := strconv.Atoi(q)
-		if err != nil {
-			inStock = append(inStock, 0) // return out of stock
+		if released {
+			reclaimed++
 		}
-		inStock = append(inStock, int32(q_int))
 	}
-
-	return inStock, nil
+	return reclaimed, nil
 }
 
 //go:embed script.lua
 var script string
 
+//go:embed reserve.lua
+var reserveScript string
+
+//go:embed commit.lua
+var commitScript string
+
+//go:embed release.lua
+var releaseScript string
+
 // reference embed to satisfy linters that don't detect go:embed usage
 var _ embed.FS
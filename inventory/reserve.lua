@@ -0,0 +1,34 @@
+-- KEYS: the inventory key of every reserved product, then the reserved key
+-- of every product, then the reservation and the reservation expiry index.
+-- ARGV[1]: reservation id, ARGV[2]: expiry (unix ms), then one quantity per
+-- product, then the id of every product.
+local n = (#KEYS - 2) / 2
+local reservation = KEYS[#KEYS - 1]
+local expiry = KEYS[#KEYS]
+local outOfStock = {}
+
+if redis.call("EXISTS", reservation) == 1 then
+    return redis.error_reply("reservation already exists")
+end
+
+for i = 1, n do
+    local onHand = tonumber(redis.call("GET", KEYS[i]) or "0")
+    local reserved = tonumber(redis.call("GET", KEYS[n + i]) or "0")
+    local quantity = tonumber(ARGV[i + 2])
+    if onHand - reserved < quantity then
+        table.insert(outOfStock, KEYS[i])
+    end
+end
+
+if #outOfStock > 0 then
+    return outOfStock
+end
+
+for i = 1, n do
+    local quantity = tonumber(ARGV[i + 2])
+    redis.call("HINCRBY", reservation, ARGV[n + i + 2], quantity)
+    redis.call("INCRBY", KEYS[n + i], quantity)
+end
+redis.call("ZADD", expiry, ARGV[2], ARGV[1])
+
+return outOfStock
@@ -1,24 +1,50 @@
+-- KEYS: the inventory key of every product, then the reserved key of every
+-- product, optionally followed by an idempotency record.
+-- ARGV: one delta per product, followed by the request fingerprint and the
+-- record TTL in milliseconds when a record is given.
+local n = math.floor(#KEYS / 2)
+local record = nil
+if #KEYS % 2 == 1 then
+    record = KEYS[#KEYS]
+end
+
+-- A retried request gets the outcome of its first attempt: the fingerprint it
+-- was recorded with, followed by the keys that were out of stock.
+if record then
+    local previous = redis.call("LRANGE", record, 0, -1)
+    if #previous > 0 then
+        return previous
+    end
+end
+
 local outOfStock = {}
 
-for i, key in ipairs(KEYS) do
+for i = 1, n do
+    local key = KEYS[i]
     local currentStock = tonumber(redis.call("GET", key) or "0")
+    local reserved = tonumber(redis.call("GET", KEYS[n + i]) or "0")
     local delta = tonumber(ARGV[i])
-    if delta < 0 and currentStock < -delta then
+    if delta < 0 and currentStock - reserved < -delta then
         table.insert(outOfStock, key)
     end
 end
 
-if #outOfStock > 0 then 
-    return outOfStock
+if #outOfStock == 0 then
+    for i = 1, n do
+        local key = KEYS[i]
+        local delta = tonumber(ARGV[i])
+        if delta < 0 then
+            redis.call("DECRBY", key, -delta)
+        else
+            redis.call("INCRBY", key, delta)
+        end
+    end
 end
 
-for i, key in ipairs(KEYS) do
-    local delta = tonumber(ARGV[i])
-    if delta < 0 then
-        redis.call("DECRBY", key, -delta)
-    else
-        redis.call("INCRBY", key, delta)
-    end
+if record then
+    redis.call("RPUSH", record, ARGV[n + 1], unpack(outOfStock))
+    redis.call("PEXPIRE", record, ARGV[n + 2])
+    table.insert(outOfStock, 1, ARGV[n + 1])
 end
 
-return outOfStock
\ No newline at end of file
+return outOfStock
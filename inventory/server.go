@@ -2,11 +2,21 @@ package inventory
 
 import (
 	"context"
-	"fmt"
-	"net"
+	"sync"
+	"time"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/errcode"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/health"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/idempotency"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/metrics"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/middleware"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/serve"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tlsconfig"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -15,20 +25,69 @@ type grpcServer struct {
 	service Service
 }
 
-func ListenGRPC(s Service, port int) error {
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+// methodRoles are the roles required by the methods that change stock.
+// Reading it is open to everyone.
+var methodRoles = map[string]auth.Role{
+	pb.InventoryService_UpdateStock_FullMethodName:        auth.RoleMerchant,
+	pb.InventoryService_ReserveStock_FullMethodName:       auth.RoleMerchant,
+	pb.InventoryService_CommitReservation_FullMethodName:  auth.RoleMerchant,
+	pb.InventoryService_ReleaseReservation_FullMethodName: auth.RoleMerchant,
+}
+
+var errorCodes = errcode.Codes{
+	ErrInvalidInput:          codes.InvalidArgument,
+	ErrInvalidIdempotencyKey: codes.InvalidArgument,
+	ErrIdempotencyKeyReused:  codes.InvalidArgument,
+	ErrReservationNotFound:   codes.NotFound,
+}
+
+// DefaultPort is the port ListenGRPC listens on unless told otherwise.
+const DefaultPort = 8084
+
+// StockWriters only lets the mTLS peers whose certificate was issued to one
+// of names, such as the gateway and the order service, call UpdateStock.
+func StockWriters(names ...string) serve.Option {
+	return serve.WithServerOptions(grpc.ChainUnaryInterceptor(
+		tlsconfig.UnaryPeerInterceptor(map[string][]string{
+			pb.InventoryService_UpdateStock_FullMethodName: names,
+		}),
+	))
+}
+
+// ListenGRPC serves s until ctx is done, then drains the calls in flight.
+// tokens verifies the access tokens callers authenticate with.
+func ListenGRPC(ctx context.Context, s Service, tokens *auth.Signer, opts ...serve.Option) error {
+	cfg := serve.NewConfig(DefaultPort, opts...)
+	lis, err := cfg.Listen()
 	if err != nil {
 		return err
 	}
 
-	srv := grpc.NewServer()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wg.Go(func() { runReclaimer(ctx, s) })
+
+	srv := grpc.NewServer(append([]grpc.ServerOption{
+		tracing.ServerOption(),
+		metrics.ServerOption(),
+		middleware.ServerOption(),
+		grpc.ChainUnaryInterceptor(
+			errcode.UnaryServerInterceptor(errorCodes),
+			auth.UnaryServerInterceptor(tokens, methodRoles),
+		),
+	}, cfg.ServerOptions...)...)
 	pb.RegisterInventoryServiceServer(srv, &grpcServer{service: s})
+	health.Serve(ctx, srv, map[string]health.Check{
+		"redis": s.Ping,
+	})
 	reflection.Register(srv)
-	return srv.Serve(lis)
+	return serve.GRPC(ctx, srv, lis, cfg.DrainTimeout)
 }
 
 func (s *grpcServer) UpdateStock(ctx context.Context, r *pb.UpdateStockRequest) (*pb.UpdateStockResponse, error) {
-	res, err := s.service.UpdateStock(ctx, r.Pids, r.Deltas)
+	res, err := s.service.UpdateStock(ctx, r.Pids, r.Deltas, idempotency.KeyFromContext(ctx, r.IdempotencyKey))
 	if err != nil {
 		return nil, err
 	}
@@ -44,3 +103,32 @@ func (s *grpcServer) CheckStock(ctx context.Context, r *pb.CheckStockRequest) (*
 
 	return &pb.CheckStockResponse{InStock: res}, nil
 }
+
+func (s *grpcServer) ReserveStock(ctx context.Context, r *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	res, outOfStock, err := s.service.ReserveStock(ctx, r.Pids, r.Quantities, time.Duration(r.TtlSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if len(outOfStock) != 0 {
+		return &pb.ReserveStockResponse{OutOfStock: outOfStock}, nil
+	}
+
+	return &pb.ReserveStockResponse{
+		ReservationId: res.ID,
+		ExpiresAt:     res.ExpiresAt.Unix(),
+	}, nil
+}
+
+func (s *grpcServer) CommitReservation(ctx context.Context, r *pb.CommitReservationRequest) (*pb.CommitReservationResponse, error) {
+	if err := s.service.CommitReservation(ctx, r.ReservationId); err != nil {
+		return nil, err
+	}
+	return &pb.CommitReservationResponse{}, nil
+}
+
+func (s *grpcServer) ReleaseReservation(ctx context.Context, r *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+	if err := s.service.ReleaseReservation(ctx, r.ReservationId); err != nil {
+		return nil, err
+	}
+	return &pb.ReleaseReservationResponse{}, nil
+}
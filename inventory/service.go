@@ -2,7 +2,27 @@ package inventory
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/idempotency"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	DefaultReservationTTL = 15 * time.Minute
+	MaxReservationTTL     = 24 * time.Hour
+	// reclaimInterval is how often expired reservations are returned to stock.
+	reclaimInterval = 10 * time.Second
+	// IdempotencyKeyTTL is how long the outcome of an UpdateStock call is kept for retries.
+	IdempotencyKeyTTL = 24 * time.Hour
+)
+
+var (
+	ErrInvalidInput          = errors.New("invalid input")
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
 )
 
 type Stock struct {
@@ -10,9 +30,20 @@ type Stock struct {
 	Delta      int32
 }
 
+type Reservation struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
 type Service interface {
-	UpdateStock(ctx context.Context, pids []string, deltas []int32) ([]string, error)
+	// Ping reports whether the store behind the service can be reached.
+	Ping(ctx context.Context) error
+	UpdateStock(ctx context.Context, pids []string, deltas []int32, idempotencyKey string) ([]string, error)
 	CheckStock(ctx context.Context, pids []string) ([]int32, error)
+	ReserveStock(ctx context.Context, pids []string, quantities []int32, ttl time.Duration) (*Reservation, []string, error)
+	CommitReservation(ctx context.Context, reservationID string) error
+	ReleaseReservation(ctx context.Context, reservationID string) error
+	ReclaimExpiredReservations(ctx context.Context) (int, error)
 }
 
 type inventoryService struct {
@@ -23,9 +54,14 @@ func NewService(repo Repository) Service {
 	return &inventoryService{repo}
 }
 
-func (s *inventoryService) UpdateStock(ctx context.Context, pids []string, deltas []int32) ([]string, error) {
+// UpdateStock applies the deltas, or only returns the earlier outcome if a
+// non-empty idempotencyKey has been used before.
+func (s *inventoryService) UpdateStock(ctx context.Context, pids []string, deltas []int32, idempotencyKey string) ([]string, error) {
 	if len(pids) == 0 || len(pids) != len(deltas) {
-		return nil, fmt.Errorf("invalid input: pids:%d, deltas:%d", len(pids), len(deltas))
+		return nil, fmt.Errorf("%w: pids:%d, deltas:%d", ErrInvalidInput, len(pids), len(deltas))
+	}
+	if len(idempotencyKey) > idempotency.MaxKeyLength {
+		return nil, ErrInvalidIdempotencyKey
 	}
 
 	var requests []Stock
@@ -41,7 +77,13 @@ func (s *inventoryService) UpdateStock(ctx context.Context, pids []string, delta
 			},
 		)
 	}
-	res, err := s.repo.UpdateStock(ctx, requests)
+	var res []string
+	var err error
+	if idempotencyKey != "" {
+		res, err = s.repo.UpdateStockOnce(ctx, idempotencyKey, idempotency.Fingerprint(pids, deltas), requests, IdempotencyKeyTTL)
+	} else {
+		res, err = s.repo.UpdateStock(ctx, requests)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -55,3 +97,84 @@ func (s *inventoryService) UpdateStock(ctx context.Context, pids []string, delta
 func (s *inventoryService) CheckStock(ctx context.Context, pids []string) ([]int32, error) {
 	return s.repo.CheckStock(ctx, pids)
 }
+
+// ReserveStock holds the given quantities for ttl. If any product cannot be
+// covered nothing is held and the out of stock product ids are returned.
+func (s *inventoryService) ReserveStock(ctx context.Context, pids []string, quantities []int32, ttl time.Duration) (*Reservation, []string, error) {
+	if len(pids) == 0 || len(pids) != len(quantities) {
+		return nil, nil, fmt.Errorf("%w: pids:%d, quantities:%d", ErrInvalidInput, len(pids), len(quantities))
+	}
+	if ttl <= 0 {
+		ttl = DefaultReservationTTL
+	}
+	if ttl > MaxReservationTTL {
+		ttl = MaxReservationTTL
+	}
+
+	// Merge repeated products so availability is checked against the total.
+	index := map[string]int{}
+	var requests []Stock
+	for i, pid := range pids {
+		if quantities[i] <= 0 {
+			return nil, nil, fmt.Errorf("%w: quantity for %s: %d", ErrInvalidInput, pid, quantities[i])
+		}
+		if j, ok := index[pid]; ok {
+			requests[j].Delta += quantities[i]
+			continue
+		}
+		index[pid] = len(requests)
+		requests = append(requests, Stock{Product_id: pid, Delta: quantities[i]})
+	}
+
+	r := &Reservation{
+		ID:        ksuid.New().String(),
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}
+	outOfStock, err := s.repo.ReserveStock(ctx, r.ID, requests, r.ExpiresAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(outOfStock) != 0 {
+		return nil, outOfStock, nil
+	}
+
+	return r, nil, nil
+}
+
+func (s *inventoryService) CommitReservation(ctx context.Context, reservationID string) error {
+	return s.repo.CommitReservation(ctx, reservationID)
+}
+
+func (s *inventoryService) ReleaseReservation(ctx context.Context, reservationID string) error {
+	return s.repo.ReleaseReservation(ctx, reservationID)
+}
+
+func (s *inventoryService) ReclaimExpiredReservations(ctx context.Context) (int, error) {
+	return s.repo.ReclaimExpiredReservations(ctx)
+}
+
+// runReclaimer periodically returns the stock of expired reservations.
+func runReclaimer(ctx context.Context, s Service) {
+	ticker := time.NewTicker(reclaimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		n, err := s.ReclaimExpiredReservations(ctx)
+		if err != nil {
+			log.Println("reclaiming reservations:", err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("reclaimed %d expired reservation(s)", n)
+		}
+	}
+}
+
+func (s *inventoryService) Ping(ctx context.Context) error {
+	return s.repo.Ping(ctx)
+}
@@ -0,0 +1,77 @@
+// Package metrics exposes Prometheus metrics from the gateway and the
+// services: RPC counts and latencies, connection pool statistics and the
+// metrics each package registers on its own.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/serve"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	rpcHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "RPCs completed by the server, by method and status code.",
+	}, []string{"method", "code"})
+	rpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Time taken to serve an RPC, by method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+)
+
+// ServerOption records the count and latency of every call a gRPC server
+// serves.
+func ServerOption() grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(UnaryServerInterceptor())
+}
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		code := status.Code(err).String()
+		rpcHandled.WithLabelValues(info.FullMethod, code).Inc()
+		rpcDuration.WithLabelValues(info.FullMethod, code).Observe(time.Since(start).Seconds())
+		return res, err
+	}
+}
+
+// RegisterDB exports the connection pool statistics of db, labelled with
+// name. A pool already registered under name is left in place.
+func RegisterDB(db *sql.DB, name string) error {
+	err := prometheus.Register(collectors.NewDBStatsCollector(db, name))
+	if are := (prometheus.AlreadyRegisteredError{}); errors.As(err, &are) {
+		return nil
+	}
+	return err
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ListenAndServe serves Handler on /metrics at port until ctx is done.
+func ListenAndServe(ctx context.Context, port int) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	return serve.HTTP(ctx, &http.Server{Handler: mux}, lis, time.Second)
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.Test/Get"}
+	notFound := rpcHandled.WithLabelValues(info.FullMethod, codes.NotFound.String())
+	before := testutil.ToFloat64(notFound)
+
+	interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	})
+
+	if got := testutil.ToFloat64(notFound) - before; got != 1 {
+		t.Errorf("expected one NotFound call to be counted, got %v", got)
+	}
+	if n := testutil.CollectAndCount(rpcDuration, "grpc_server_handling_seconds"); n == 0 {
+		t.Error("expected the call's latency to be observed")
+	}
+}
+
+func TestRegisterDB(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := RegisterDB(db, "test"); err != nil {
+		t.Fatal(err)
+	}
+	// Repositories created again, as in tests, don't fail.
+	if err := RegisterDB(db, "test"); err != nil {
+		t.Errorf("expected registering twice to succeed, got %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), `go_sql_open_connections{db_name="test"}`) {
+		t.Error("expected the pool statistics to be exported")
+	}
+}
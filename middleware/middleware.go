@@ -0,0 +1,173 @@
+// Package middleware holds the gRPC interceptors shared by every service and
+// client: request IDs, request logging, panic recovery and default deadlines.
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// RequestIDKey is the gRPC metadata key and, canonicalised, the HTTP
+	// header a request ID travels in.
+	RequestIDKey = "x-request-id"
+	// DefaultTimeout bounds calls that arrive without a deadline.
+	DefaultTimeout = 10 * time.Second
+
+	healthServicePrefix = "/grpc.health.v1.Health/"
+)
+
+// ServerOption chains the interceptors every service runs its calls
+// through. It should come before any other interceptor so that those run
+// with a request ID and deadline, and their panics are recovered.
+func ServerOption() grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(
+		RequestIDServerInterceptor(),
+		LoggingServerInterceptor(slog.Default()),
+		RecoveryServerInterceptor(),
+		DeadlineServerInterceptor(DefaultTimeout),
+	)
+}
+
+// DialOption chains the interceptors every client makes its calls through.
+func DialOption() grpc.DialOption {
+	return grpc.WithChainUnaryInterceptor(RequestIDClientInterceptor())
+}
+
+type requestIDKey struct{}
+
+func NewRequestIDContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the ID of the request ctx belongs to, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+func newRequestID() string {
+	return ksuid.New().String()
+}
+
+// RequestIDServerInterceptor stores the request ID sent by the caller in
+// the context, generating one if there is none, and returns it in the
+// response headers.
+func RequestIDServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		id := newRequestID()
+		if values := md.Get(RequestIDKey); len(values) != 0 && values[0] != "" {
+			id = values[0]
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, id))
+		return handler(NewRequestIDContext(ctx, id), req)
+	}
+}
+
+// RequestIDClientInterceptor sends the request ID of the context, if any,
+// with every call, so it follows a request from the gateway down to the
+// last service.
+func RequestIDClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id, ok := RequestIDFromContext(ctx); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, RequestIDKey, id)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// RequestIDHandler is RequestIDServerInterceptor for HTTP requests.
+func RequestIDHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDKey)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDKey, id)
+		next.ServeHTTP(w, r.WithContext(NewRequestIDContext(r.Context(), id)))
+	})
+}
+
+// LoggingServerInterceptor logs the method, outcome and duration of every
+// call but health checks, which probes make every few seconds.
+func LoggingServerInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		res, err := handler(ctx, req)
+		code := status.Code(err)
+
+		level := slog.LevelInfo
+		if serverFault(code) {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if id, ok := RequestIDFromContext(ctx); ok {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		}
+		logger.LogAttrs(ctx, level, "rpc", attrs...)
+		return res, err
+	}
+}
+
+// serverFault reports whether code means the service, rather than the
+// caller, is at fault.
+func serverFault(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.Unimplemented:
+		return true
+	}
+	return false
+}
+
+// RecoveryServerInterceptor turns a panicking call into a codes.Internal
+// error instead of letting it bring the whole process down.
+func RecoveryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				slog.ErrorContext(ctx, "panic serving rpc",
+					slog.String("method", info.FullMethod),
+					slog.Any("panic", p),
+					slog.String("stack", string(debug.Stack())),
+				)
+				res, err = nil, status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// DeadlineServerInterceptor gives calls that arrive without a deadline one
+// of d, so a stuck backend can't hold them forever.
+func DeadlineServerInterceptor(d time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
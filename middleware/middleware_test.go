@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testInfo = &grpc.UnaryServerInfo{FullMethod: "/pb.CatalogService/GetProduct"}
+
+func TestRequestIDServerInterceptor(t *testing.T) {
+	interceptor := RequestIDServerInterceptor()
+	handler := func(ctx context.Context, _ any) (any, error) {
+		id, _ := RequestIDFromContext(ctx)
+		return id, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDKey, "req-1"))
+	got, _ := interceptor(ctx, nil, testInfo, handler)
+	if got != "req-1" {
+		t.Errorf("expected the caller's request ID, got %q", got)
+	}
+
+	got, _ = interceptor(context.Background(), nil, testInfo, handler)
+	if got == "" {
+		t.Error("expected a request ID to be generated")
+	}
+}
+
+func TestRequestIDClientInterceptor(t *testing.T) {
+	interceptor := RequestIDClientInterceptor()
+
+	var sent []string
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		sent = md.Get(RequestIDKey)
+		return nil
+	}
+
+	interceptor(context.Background(), "/m", nil, nil, nil, invoker)
+	if len(sent) != 0 {
+		t.Errorf("expected no request ID without one in the context, got %v", sent)
+	}
+
+	interceptor(NewRequestIDContext(context.Background(), "req-1"), "/m", nil, nil, nil, invoker)
+	if len(sent) != 1 || sent[0] != "req-1" {
+		t.Errorf("expected the request ID to be forwarded, got %v", sent)
+	}
+}
+
+func TestRequestIDHandler(t *testing.T) {
+	var got string
+	h := RequestIDHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = RequestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	req.Header.Set(RequestIDKey, "req-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got != "req-1" || rec.Header().Get(RequestIDKey) != "req-1" {
+		t.Errorf("expected req-1 in context and response, got %q and %q", got, rec.Header().Get(RequestIDKey))
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", nil))
+	if got == "" || got == "req-1" || rec.Header().Get(RequestIDKey) != got {
+		t.Errorf("expected a new request ID, got %q", got)
+	}
+}
+
+func TestRecoveryServerInterceptor(t *testing.T) {
+	interceptor := RecoveryServerInterceptor()
+
+	_, err := interceptor(context.Background(), nil, testInfo, func(context.Context, any) (any, error) {
+		var q []int32
+		return q[0], nil
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal, got %v", err)
+	}
+
+	res, err := interceptor(context.Background(), nil, testInfo, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	if res != "ok" || err != nil {
+		t.Errorf("expected the handler's result, got %v, %v", res, err)
+	}
+}
+
+func TestDeadlineServerInterceptor(t *testing.T) {
+	interceptor := DeadlineServerInterceptor(time.Minute)
+	remaining := func(ctx context.Context, _ any) (any, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return time.Duration(0), nil
+		}
+		return time.Until(deadline), nil
+	}
+
+	got, _ := interceptor(context.Background(), nil, testInfo, remaining)
+	if d := got.(time.Duration); d <= 0 || d > time.Minute {
+		t.Errorf("expected a default deadline within a minute, got %v", d)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	got, _ = interceptor(ctx, nil, testInfo, remaining)
+	if d := got.(time.Duration); d <= time.Minute {
+		t.Errorf("expected the caller's deadline to be kept, got %v", d)
+	}
+}
+
+func TestLoggingServerInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	interceptor := LoggingServerInterceptor(slog.New(slog.NewTextHandler(&buf, nil)))
+	ctx := NewRequestIDContext(context.Background(), "req-1")
+
+	interceptor(ctx, nil, testInfo, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.NotFound, "product not found")
+	})
+	line := buf.String()
+	for _, want := range []string{"level=INFO", "method=/pb.CatalogService/GetProduct", "code=NotFound", "request_id=req-1", `error="product not found"`, "duration="} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in %q", want, line)
+		}
+	}
+
+	buf.Reset()
+	interceptor(ctx, nil, testInfo, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.Internal, "boom")
+	})
+	if !strings.Contains(buf.String(), "level=ERROR") {
+		t.Errorf("expected server faults to be logged as errors, got %q", buf.String())
+	}
+
+	buf.Reset()
+	interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+	if buf.Len() != 0 {
+		t.Errorf("expected health checks not to be logged, got %q", buf.String())
+	}
+}
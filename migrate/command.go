@@ -0,0 +1,129 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	_ "github.com/lib/pq"
+)
+
+var ErrUsage = errors.New("usage: migrate [up | status | rollback [steps]]")
+
+// memoryURL selects the in-memory repository of a service, which has no
+// schema.
+const memoryURL = "memory://"
+
+// Main runs "<service> migrate args...", which only needs DATABASE_URL, or
+// defaultURL when it is unset. It exits if the command fails.
+func Main(args []string, defaultURL string, newMigrator func(*sql.DB) (*Migrator, error)) {
+	var cfg struct {
+		DatabaseURL string `envconfig:"DATABASE_URL"`
+	}
+	if err := envconfig.Process("", &cfg); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.DatabaseURL == "" {
+		cfg.DatabaseURL = defaultURL
+	}
+	if cfg.DatabaseURL == memoryURL {
+		log.Fatal("the in-memory repository has no schema to migrate")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := Run(ctx, cfg.DatabaseURL, newMigrator, args, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Run runs the migrate command args on the database at url, with the
+// migrator newMigrator returns for it.
+func Run(ctx context.Context, url string, newMigrator func(*sql.DB) (*Migrator, error), args []string, w io.Writer) error {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+	return m.Command(ctx, args, w)
+}
+
+// Command runs the migrate subcommand of a service binary with args:
+//
+//	up                apply the pending migrations (the default)
+//	status            list the migrations and whether they were applied
+//	rollback [steps]  undo the last steps migrations, 1 by default
+//
+// It reports what it did to w.
+func (m *Migrator) Command(ctx context.Context, args []string, w io.Writer) error {
+	cmd := "up"
+	if len(args) != 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch {
+	case cmd == "up" && len(args) == 0:
+		done, err := m.Up(ctx)
+		for _, mig := range done {
+			fmt.Fprintln(w, "applied", mig)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Fprintln(w, "no pending migrations")
+		}
+		return err
+
+	case cmd == "rollback" && len(args) <= 1:
+		steps := 1
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return ErrUsage
+			}
+			steps = n
+		}
+		done, err := m.Rollback(ctx, steps)
+		for _, mig := range done {
+			fmt.Fprintln(w, "rolled back", mig)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Fprintln(w, "no applied migrations")
+		}
+		return err
+
+	case cmd == "status" && len(args) == 0:
+		states, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "MIGRATION\tAPPLIED")
+		for _, st := range states {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Format(time.RFC3339)
+			}
+			if st.Unknown {
+				applied += " (unknown to this release)"
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", st.Migration, applied)
+		}
+		return tw.Flush()
+	}
+	return ErrUsage
+}
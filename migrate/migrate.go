@@ -0,0 +1,281 @@
+// Package migrate evolves the schema of a Postgres database with numbered
+// migrations, embedded in the binary of the service that owns it. Applied
+// migrations are recorded in the schema_migrations table, and a Postgres
+// advisory lock keeps replicas starting together from applying them twice.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidMigration = errors.New("invalid migration")
+	ErrNoDownMigration  = errors.New("migration can't be rolled back")
+)
+
+// Migration is a numbered change to the schema. Down, if not empty, undoes
+// what Up did.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// fileName matches the files migrations are loaded from, such as
+// "0003_order_status.up.sql".
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load reads the migrations in the root of fsys, ordered by version. Each
+// needs an up file; the down file is optional.
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, name := range names {
+		match := fileName.FindStringSubmatch(path.Base(name))
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s is not named like 0001_name.up.sql", ErrInvalidMigration, name)
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidMigration, name, err)
+		}
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("%w: version %d is used by %s and %s", ErrInvalidMigration, version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%w: %s has no up file", ErrInvalidMigration, m)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies and rolls back the migrations of a database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	lockID     int64
+}
+
+// New loads the migrations of the service called name from fsys.
+func New(db *sql.DB, fsys fs.FS, name string) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	h := fnv.New64a()
+	h.Write([]byte("migrate:" + name))
+	return &Migrator{db: db, migrations: migrations, lockID: int64(h.Sum64())}, nil
+}
+
+// NewFromDir is New for the migrations in the dir directory of fsys, such as
+// the embedded migrations directory of a service.
+func NewFromDir(db *sql.DB, fsys fs.FS, dir, name string) (*Migrator, error) {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return New(db, sub, name)
+}
+
+// Migrations returns the migrations of m, ordered by version.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// State is a migration and when it was applied to the database, if it was.
+type State struct {
+	Migration
+	AppliedAt *time.Time
+	// Unknown is set for versions applied to the database but missing from
+	// this binary, e.g. by a newer release.
+	Unknown bool
+}
+
+// withLock runs fn on a connection holding the advisory lock of m, with
+// the schema_migrations table created.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, m.lockID); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer func() {
+		// The lock is released with the session anyway if this fails.
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, m.lockID); err != nil {
+			log.Println("migrate: releasing lock:", err)
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+func applied(ctx context.Context, conn *sql.Conn) (map[int64]State, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := map[int64]State{}
+	for rows.Next() {
+		var st State
+		var at time.Time
+		if err := rows.Scan(&st.Version, &st.Name, &at); err != nil {
+			return nil, err
+		}
+		st.AppliedAt = &at
+		states[st.Version] = st
+	}
+	return states, rows.Err()
+}
+
+// run executes the SQL of a migration and records the change in a single
+// transaction.
+func run(ctx context.Context, conn *sql.Conn, query, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Up applies the migrations not applied yet, oldest first, and returns
+// them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		states, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := states[mig.Version]; ok {
+				continue
+			}
+			err := run(ctx, conn, mig.Up,
+				`INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+				mig.Version, mig.Name, time.Now().UTC())
+			if err != nil {
+				return fmt.Errorf("applying %s: %w", mig, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Rollback undoes the last steps applied migrations, newest first, and
+// returns them.
+func (m *Migrator) Rollback(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		states, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := states[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("%w: %s", ErrNoDownMigration, mig)
+			}
+			err := run(ctx, conn, mig.Down, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+			if err != nil {
+				return fmt.Errorf("rolling back %s: %w", mig, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status returns every migration, known or applied, by version.
+func (m *Migrator) Status(ctx context.Context) ([]State, error) {
+	var states []State
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			st := State{Migration: mig}
+			if a, ok := applied[mig.Version]; ok {
+				st.AppliedAt = a.AppliedAt
+				delete(applied, mig.Version)
+			}
+			states = append(states, st)
+		}
+		for _, a := range applied {
+			a.Unknown = true
+			states = append(states, a)
+		}
+		return nil
+	})
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states, err
+}
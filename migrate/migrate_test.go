@@ -0,0 +1,180 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var testFiles = fstest.MapFS{
+	"0002_add_email.up.sql":       {Data: []byte("ALTER TABLE accounts ADD COLUMN email TEXT;")},
+	"0002_add_email.down.sql":     {Data: []byte("ALTER TABLE accounts DROP COLUMN email;")},
+	"0001_create_accounts.up.sql": {Data: []byte("CREATE TABLE accounts (id TEXT);")},
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].String() != "0001_create_accounts" || migrations[1].String() != "0002_add_email" {
+		t.Fatalf("expected both migrations by version, got %v", migrations)
+	}
+	if migrations[0].Down != "" || migrations[1].Down == "" {
+		t.Errorf("expected only the second migration to have a down file, got %+v", migrations)
+	}
+}
+
+func TestNewFromDir(t *testing.T) {
+	files := fstest.MapFS{}
+	for name, f := range testFiles {
+		files["migrations/"+name] = f
+	}
+	m, err := NewFromDir(nil, files, "migrations", "account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Migrations()) != 2 {
+		t.Fatalf("expected the migrations of the directory, got %v", m.Migrations())
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"bad name":     {"create_accounts.up.sql": {}},
+		"no up file":   {"0001_create_accounts.down.sql": {}},
+		"same version": {"0001_a.up.sql": {}, "0001_b.up.sql": {}},
+	} {
+		if _, err := Load(fsys); !errors.Is(err, ErrInvalidMigration) {
+			t.Errorf("%s: expected ErrInvalidMigration, got %v", name, err)
+		}
+	}
+}
+
+// newMock returns a Migrator of testFiles on a mock database expecting the
+// lock to be taken and the schema_migrations table created.
+func newMock(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := New(db, testFiles, "account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(m.lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	return m, mock
+}
+
+func expectApplied(mock sqlmock.Sqlmock, versions ...int64) {
+	rows := sqlmock.NewRows([]string{"version", "name", "applied_at"})
+	for _, v := range versions {
+		rows.AddRow(v, "migration", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	mock.ExpectQuery(`SELECT version, name, applied_at FROM schema_migrations`).WillReturnRows(rows)
+}
+
+func expectUnlock(mock sqlmock.Sqlmock, m *Migrator) {
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(m.lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestMigrator_Up(t *testing.T) {
+	m, mock := newMock(t)
+	expectApplied(mock, 1)
+	mock.ExpectBegin()
+	mock.ExpectExec(`ALTER TABLE accounts ADD COLUMN email TEXT;`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs(int64(2), "add_email", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock, m)
+
+	done, err := m.Up(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 || done[0].Version != 2 {
+		t.Errorf("expected only the pending migration to be applied, got %v", done)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMigrator_Up_Failure(t *testing.T) {
+	m, mock := newMock(t)
+	expectApplied(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE accounts`).WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	expectUnlock(mock, m)
+
+	done, err := m.Up(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "0001_create_accounts") {
+		t.Errorf("expected the failing migration to be named, got %v", err)
+	}
+	if len(done) != 0 {
+		t.Errorf("expected nothing to be applied, got %v", done)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMigrator_Rollback(t *testing.T) {
+	m, mock := newMock(t)
+	expectApplied(mock, 1, 2)
+	mock.ExpectBegin()
+	mock.ExpectExec(`ALTER TABLE accounts DROP COLUMN email;`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM schema_migrations WHERE version = \$1`).WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock, m)
+
+	// The first migration has no down file, so rolling back stops there.
+	done, err := m.Rollback(context.Background(), 2)
+	if !errors.Is(err, ErrNoDownMigration) {
+		t.Errorf("expected ErrNoDownMigration, got %v", err)
+	}
+	if len(done) != 1 || done[0].Version != 2 {
+		t.Errorf("expected the second migration to be rolled back, got %v", done)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCommand_Status(t *testing.T) {
+	m, mock := newMock(t)
+	expectApplied(mock, 1, 7)
+	expectUnlock(mock, m)
+
+	var out bytes.Buffer
+	if err := m.Command(context.Background(), []string{"status"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	want := `MIGRATION             APPLIED
+0001_create_accounts  2026-01-01T00:00:00Z
+0002_add_email        pending
+0007_migration        2026-01-01T00:00:00Z (unknown to this release)
+`
+	if out.String() != want {
+		t.Errorf("unexpected status:\n%s", out.String())
+	}
+}
+
+func TestCommand_Usage(t *testing.T) {
+	m, _ := newMock(t)
+	for _, args := range [][]string{{"sideways"}, {"up", "now"}, {"rollback", "0"}, {"rollback", "x"}} {
+		if err := m.Command(context.Background(), args, &bytes.Buffer{}); !errors.Is(err, ErrUsage) {
+			t.Errorf("%v: expected ErrUsage, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+package money
+
+//go:generate protoc -I.. --go_out=. --go_opt=module=github.com/RathodViraj/go-microservice-graphql-grpc/money money/money.proto
@@ -0,0 +1,157 @@
+// Package money represents monetary amounts exactly, as an integer number of
+// minor units (e.g. cents) of an ISO 4217 currency.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used for amounts that were stored before currencies were recorded.
+const DefaultCurrency = "USD"
+
+var (
+	ErrInvalidCurrency  = errors.New("invalid currency code")
+	ErrInvalidAmount    = errors.New("invalid money amount")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("money amount overflows")
+)
+
+// exponents lists the currencies that do not use two decimal places.
+var exponents = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"IQD": 3,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"VND": 0,
+}
+
+type Money struct {
+	// Amount is in minor units of Currency, e.g. cents for USD.
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Exponent returns the number of decimal places used by currency.
+func Exponent(currency string) int {
+	if e, ok := exponents[currency]; ok {
+		return e
+	}
+	return 2
+}
+
+func ValidCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse reads a decimal amount such as "12.34" in the given currency. It
+// fails rather than rounds if the amount has more decimal places than the
+// currency allows.
+func Parse(amount, currency string) (Money, error) {
+	if !ValidCurrency(currency) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+
+	s := amount
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, frac, _ := strings.Cut(s, ".")
+
+	exp := Exponent(currency)
+	if whole == "" || len(frac) > exp || strings.ContainsAny(whole+frac, "+-") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+
+	digits := whole + frac + strings.Repeat("0", exp-len(frac))
+	v, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	if neg {
+		v = -v
+	}
+
+	return Money{Amount: v, Currency: currency}, nil
+}
+
+// FromFloat converts a legacy floating point amount, rounding to the nearest
+// minor unit. It must only be used for data written before amounts were exact.
+func FromFloat(amount float64, currency string) Money {
+	scale := math.Pow10(Exponent(currency))
+	return Money{Amount: int64(math.Round(amount * scale)), Currency: currency}
+}
+
+// Decimal formats the amount without its currency, e.g. "12.34".
+func (m Money) Decimal() string {
+	exp := Exponent(m.Currency)
+	v := m.Amount
+	sign := ""
+	if v < 0 {
+		sign = "-"
+	}
+	s := strconv.FormatUint(absUint(v), 10)
+	if exp == 0 {
+		return sign + s
+	}
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+func (m Money) Mul(n int64) (Money, error) {
+	if n == 0 || m.Amount == 0 {
+		return Money{Amount: 0, Currency: m.Currency}, nil
+	}
+	product := m.Amount * n
+	if product/n != m.Amount || (m.Amount == -1 && n == math.MinInt64) || (n == -1 && m.Amount == math.MinInt64) {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
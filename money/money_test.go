@@ -0,0 +1,106 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		in       string
+		currency string
+		want     int64
+		wantErr  error
+	}{
+		{in: "12.34", currency: "USD", want: 1234},
+		{in: "12.3", currency: "USD", want: 1230},
+		{in: "12", currency: "USD", want: 1200},
+		{in: "0.07", currency: "USD", want: 7},
+		{in: "-1.5", currency: "EUR", want: -150},
+		{in: "500", currency: "JPY", want: 500},
+		{in: "1.234", currency: "KWD", want: 1234},
+		{in: "12.345", currency: "USD", wantErr: ErrInvalidAmount},
+		{in: "1.5", currency: "JPY", wantErr: ErrInvalidAmount},
+		{in: "abc", currency: "USD", wantErr: ErrInvalidAmount},
+		{in: ".5", currency: "USD", wantErr: ErrInvalidAmount},
+		{in: "1.-5", currency: "USD", wantErr: ErrInvalidAmount},
+		{in: "1", currency: "usd", wantErr: ErrInvalidCurrency},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.in+" "+tc.currency, func(t *testing.T) {
+			m, err := Parse(tc.in, tc.currency)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Amount != tc.want || m.Currency != tc.currency {
+				t.Errorf("expected %d %s, got %+v", tc.want, tc.currency, m)
+			}
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	testCases := []struct {
+		m    Money
+		want string
+	}{
+		{New(1234, "USD"), "12.34"},
+		{New(5, "USD"), "0.05"},
+		{New(0, "USD"), "0.00"},
+		{New(-150, "EUR"), "-1.50"},
+		{New(500, "JPY"), "500"},
+		{New(1, "KWD"), "0.001"},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.m.Decimal(); got != tc.want {
+			t.Errorf("%+v: expected %s, got %s", tc.m, tc.want, got)
+		}
+		back, err := Parse(tc.m.Decimal(), tc.m.Currency)
+		if err != nil || back != tc.m {
+			t.Errorf("%+v did not round trip: %+v, %v", tc.m, back, err)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	total := New(0, "USD")
+	for i := 0; i < 10; i++ {
+		line, err := New(10, "USD").Mul(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total, err = total.Add(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if total.Amount != 300 {
+		t.Errorf("expected 300, got %d", total.Amount)
+	}
+
+	if _, err := New(1, "USD").Add(New(1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err := New(1<<62, "USD").Mul(4); !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+	if _, err := New(1<<62, "USD").Add(New(1<<62, "USD")); !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	if m := FromFloat(0.1+0.2, "USD"); m.Amount != 30 {
+		t.Errorf("expected 30, got %d", m.Amount)
+	}
+	if m := FromFloat(1200, "JPY"); m.Amount != 1200 {
+		t.Errorf("expected 1200, got %d", m.Amount)
+	}
+}
@@ -0,0 +1,12 @@
+package money
+
+import "github.com/RathodViraj/go-microservice-graphql-grpc/money/pb"
+
+func (m Money) Proto() *pb.Money {
+	return &pb.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+// FromProto converts a wire amount. A missing amount is the zero Money.
+func FromProto(p *pb.Money) Money {
+	return New(p.GetAmount(), p.GetCurrency())
+}
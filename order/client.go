@@ -5,7 +5,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/health"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/middleware"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/order/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/resilience"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -15,8 +20,12 @@ type Client struct {
 	service pb.OrderServiceClient
 }
 
-func NewClient(url string) (*Client, error) {
-	conn, err := grpc.NewClient(url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// NewClient connects to the service at url. opts are added to the default
+// dial options, e.g. to authenticate calls. Connections are plaintext
+// unless opts set transport credentials, such as tlsconfig's.
+func NewClient(url string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DialOption(), tracing.DialOption(), resilience.DialOption()}, opts...)
+	conn, err := grpc.NewClient(url, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create order client: %w", err)
 	}
@@ -29,7 +38,14 @@ func (c *Client) Close() {
 	c.conn.Close()
 }
 
-func (c *Client) PostOrder(ctx context.Context, accountID string, products []OrderedProduct) (*Order, error) {
+// Ping reports whether the order service is serving.
+func (c *Client) Ping(ctx context.Context) error {
+	return health.Conn(c.conn)(ctx)
+}
+
+// PostOrder places an order. Retrying with the same non-empty idempotencyKey
+// returns the order placed by the first call instead of a new one.
+func (c *Client) PostOrder(ctx context.Context, accountID string, products []OrderedProduct, idempotencyKey string) (*Order, error) {
 	protoProducts := []*pb.PostOrderRequest_OrderProduct{}
 	for _, p := range products {
 		protoProducts = append(protoProducts, &pb.PostOrderRequest_OrderProduct{
@@ -41,8 +57,9 @@ func (c *Client) PostOrder(ctx context.Context, accountID string, products []Ord
 	res, err := c.service.PostOrder(
 		ctx,
 		&pb.PostOrderRequest{
-			AccountId: accountID,
-			Products:  protoProducts,
+			AccountId:      accountID,
+			Products:       protoProducts,
+			IdempotencyKey: idempotencyKey,
 		},
 	)
 
@@ -57,41 +74,147 @@ func (c *Client) PostOrder(ctx context.Context, accountID string, products []Ord
 		ID:         newOrder.Id,
 		CreatedAt:  newOrderCreatedAt,
 		AccountID:  newOrder.AccountId,
-		TotalPrice: newOrder.TotalPrice,
+		TotalPrice: money.FromProto(newOrder.TotalPrice),
+		Status:     OrderStatus(newOrder.Status),
 		Products:   products,
 	}, nil
 }
 
+func (c *Client) GetOrder(ctx context.Context, id string) (*Order, error) {
+	res, err := c.service.GetOrder(ctx, &pb.GetOrderRequest{Id: id}, resilience.Idempotent())
+	if err != nil {
+		return nil, err
+	}
+
+	o := orderFromProto(res.Order)
+	return &o, nil
+}
+
+// ListOrders returns a page of the orders matching filter, newest first.
+// Pass the EndCursor of a page as after to get the next one.
+func (c *Client) ListOrders(ctx context.Context, filter OrderFilter, first int, after string) (*OrderPage, error) {
+	req := &pb.ListOrdersRequest{
+		AccountId: filter.AccountID,
+		First:     uint32(max(first, 0)),
+		After:     after,
+	}
+	if !filter.CreatedAfter.IsZero() {
+		req.CreatedAfter, _ = filter.CreatedAfter.MarshalBinary()
+	}
+	if !filter.CreatedBefore.IsZero() {
+		req.CreatedBefore, _ = filter.CreatedBefore.MarshalBinary()
+	}
+	for _, st := range filter.Statuses {
+		req.Statuses = append(req.Statuses, string(st))
+	}
+	if filter.MinTotal != nil {
+		req.MinTotal = filter.MinTotal.Proto()
+	}
+
+	res, err := c.service.ListOrders(ctx, req, resilience.Idempotent())
+	if err != nil {
+		return nil, err
+	}
+
+	page := &OrderPage{
+		Orders:      []Order{},
+		EndCursor:   res.EndCursor,
+		HasNextPage: res.HasNextPage,
+	}
+	for _, orderProto := range res.Orders {
+		page.Orders = append(page.Orders, orderFromProto(orderProto))
+	}
+
+	return page, nil
+}
+
 func (c *Client) GetOrdersForAccount(ctx context.Context, accountId string) ([]Order, error) {
-	res, err := c.service.GetOrdersForAccount(ctx, &pb.GetOrdersForAccountRequest{AccountId: accountId})
+	res, err := c.service.GetOrdersForAccount(ctx, &pb.GetOrdersForAccountRequest{AccountId: accountId}, resilience.Idempotent())
 	if err != nil {
 		return nil, err
 	}
 
 	orders := []Order{}
 	for _, orderProto := range res.Orders {
-		o := Order{
-			ID:         orderProto.Id,
-			TotalPrice: orderProto.TotalPrice,
-			AccountID:  orderProto.AccountId,
-		}
-		o.CreatedAt = time.Time{}
-		o.CreatedAt.UnmarshalBinary(orderProto.CreatedAt)
-
-		products := []OrderedProduct{}
-		for _, opProto := range orderProto.Products {
-			products = append(products, OrderedProduct{
-				ID:          opProto.Id,
-				Name:        opProto.Name,
-				Description: opProto.Description,
-				Quantity:    opProto.Quantity,
-				Price:       opProto.Price,
-			})
+		orders = append(orders, orderFromProto(orderProto))
+	}
+
+	return orders, nil
+}
+
+// GetOrdersForAccounts returns the orders of every account in accountIDs,
+// keyed by account ID, in a single call.
+func (c *Client) GetOrdersForAccounts(ctx context.Context, accountIDs []string) (map[string][]Order, error) {
+	res, err := c.service.GetOrdersForAccounts(ctx, &pb.GetOrdersForAccountsRequest{AccountIds: accountIDs}, resilience.Idempotent())
+	if err != nil {
+		return nil, err
+	}
+
+	byAccount := map[string][]Order{}
+	for _, a := range res.Accounts {
+		orders := []Order{}
+		for _, orderProto := range a.Orders {
+			orders = append(orders, orderFromProto(orderProto))
 		}
+		byAccount[a.AccountId] = orders
+	}
+
+	return byAccount, nil
+}
 
-		o.Products = products
-		orders = append(orders, o)
+func orderFromProto(orderProto *pb.Order) Order {
+	o := Order{
+		ID:         orderProto.Id,
+		TotalPrice: money.FromProto(orderProto.TotalPrice),
+		AccountID:  orderProto.AccountId,
+		Status:     OrderStatus(orderProto.Status),
 	}
+	o.CreatedAt = time.Time{}
+	o.CreatedAt.UnmarshalBinary(orderProto.CreatedAt)
 
-	return orders, nil
+	products := []OrderedProduct{}
+	for _, opProto := range orderProto.Products {
+		products = append(products, OrderedProduct{
+			ID:          opProto.Id,
+			Name:        opProto.Name,
+			Description: opProto.Description,
+			Quantity:    opProto.Quantity,
+			Price:       money.FromProto(opProto.Price),
+		})
+	}
+	o.Products = products
+
+	return o
+}
+
+func (c *Client) UpdateOrderStatus(ctx context.Context, id string, status OrderStatus) (*StatusChange, error) {
+	res, err := c.service.UpdateOrderStatus(ctx, &pb.UpdateOrderStatusRequest{Id: id, Status: string(status)})
+	if err != nil {
+		return nil, err
+	}
+
+	change := &StatusChange{
+		OrderID: res.Id,
+		From:    OrderStatus(res.PreviousStatus),
+		To:      OrderStatus(res.Status),
+	}
+	change.ChangedAt.UnmarshalBinary(res.ChangedAt)
+
+	return change, nil
+}
+
+func (c *Client) CancelOrder(ctx context.Context, id string) (*StatusChange, error) {
+	res, err := c.service.CancelOrder(ctx, &pb.CancelOrderRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	change := &StatusChange{
+		OrderID: res.Id,
+		From:    OrderStatus(res.PreviousStatus),
+		To:      OrderStatus(res.Status),
+	}
+	change.ChangedAt.UnmarshalBinary(res.ChangedAt)
+
+	return change, nil
 }
@@ -6,6 +6,9 @@ import (
 	"testing"
 	"time"
 
+	moneypb "github.com/RathodViraj/go-microservice-graphql-grpc/money/pb"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/order/pb"
 	"go.uber.org/mock/gomock"
 )
@@ -22,27 +25,27 @@ func TestClient_PostOrder_Success(t *testing.T) {
 		Order: &pb.Order{
 			Id:         "o1",
 			AccountId:  "acc1",
-			TotalPrice: 20,
+			TotalPrice: &moneypb.Money{Amount: 2000, Currency: "USD"},
 			CreatedAt:  createdBytes,
 			Products: []*pb.Order_OrderProduct{{
 				Id:          "p1",
 				Name:        "prod",
 				Description: "desc",
-				Price:       10,
+				Price:       &moneypb.Money{Amount: 1000, Currency: "USD"},
 				Quantity:    2,
 			}},
 		},
 	}, nil)
 
 	client := &Client{service: mockSvc}
-	order, err := client.PostOrder(context.Background(), "acc1", []OrderedProduct{{ID: "p1", Quantity: 2, Price: 10, Name: "prod", Description: "desc"}})
+	order, err := client.PostOrder(context.Background(), "acc1", []OrderedProduct{{ID: "p1", Quantity: 2, Price: money.New(1000, "USD"), Name: "prod", Description: "desc"}}, "")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 	if order.ID != "o1" || order.AccountID != "acc1" {
 		t.Fatalf("unexpected order: %#v", order)
 	}
-	if order.TotalPrice != 20 {
+	if order.TotalPrice != money.New(2000, "USD") {
 		t.Fatalf("expected total 20, got %v", order.TotalPrice)
 	}
 	if len(order.Products) != 1 || order.Products[0].Quantity != 2 {
@@ -58,7 +61,7 @@ func TestClient_PostOrder_Error(t *testing.T) {
 	mockSvc.EXPECT().PostOrder(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("boom"))
 
 	client := &Client{service: mockSvc}
-	_, err := client.PostOrder(context.Background(), "acc1", []OrderedProduct{{ID: "p1", Quantity: 1}})
+	_, err := client.PostOrder(context.Background(), "acc1", []OrderedProduct{{ID: "p1", Quantity: 1}}, "")
 	if err == nil || err.Error() != "boom" {
 		t.Fatalf("expected boom error, got %v", err)
 	}
@@ -76,13 +79,13 @@ func TestClient_GetOrdersForAccount_Success(t *testing.T) {
 		Orders: []*pb.Order{{
 			Id:         "o1",
 			AccountId:  "acc1",
-			TotalPrice: 30,
+			TotalPrice: &moneypb.Money{Amount: 3000, Currency: "USD"},
 			CreatedAt:  createdBytes,
 			Products: []*pb.Order_OrderProduct{{
 				Id:          "p1",
 				Name:        "prod",
 				Description: "desc",
-				Price:       10,
+				Price:       &moneypb.Money{Amount: 1000, Currency: "USD"},
 				Quantity:    3,
 			}},
 		}},
@@ -96,10 +99,49 @@ func TestClient_GetOrdersForAccount_Success(t *testing.T) {
 	if len(orders) != 1 {
 		t.Fatalf("expected 1 order, got %d", len(orders))
 	}
-	if orders[0].ID != "o1" || orders[0].TotalPrice != 30 {
+	if orders[0].ID != "o1" || orders[0].TotalPrice != money.New(3000, "USD") {
 		t.Fatalf("unexpected order: %#v", orders[0])
 	}
 	if len(orders[0].Products) != 1 || orders[0].Products[0].Quantity != 3 {
 		t.Fatalf("unexpected products: %#v", orders[0].Products)
 	}
 }
+
+func TestClient_ListOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockOrderServiceClient(ctrl)
+	after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	minTotal := money.New(1000, "USD")
+
+	mockSvc.EXPECT().ListOrders(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, r *pb.ListOrdersRequest, opts ...any) (*pb.ListOrdersResponse, error) {
+			var createdAfter time.Time
+			createdAfter.UnmarshalBinary(r.CreatedAfter)
+			if r.AccountId != "acc1" || !createdAfter.Equal(after) || len(r.CreatedBefore) != 0 {
+				t.Errorf("unexpected filter %+v", r)
+			}
+			if len(r.Statuses) != 1 || r.Statuses[0] != "paid" || r.MinTotal.GetAmount() != 1000 {
+				t.Errorf("unexpected filter %+v", r)
+			}
+			if r.First != 5 || r.After != "c1" {
+				t.Errorf("unexpected paging %d %q", r.First, r.After)
+			}
+			return &pb.ListOrdersResponse{
+				Orders:      []*pb.Order{{Id: "o2", AccountId: "acc1", Status: "paid"}},
+				EndCursor:   "c2",
+				HasNextPage: true,
+			}, nil
+		})
+
+	client := &Client{service: mockSvc}
+	filter := OrderFilter{AccountID: "acc1", CreatedAfter: after, Statuses: []OrderStatus{StatusPaid}, MinTotal: &minTotal}
+	page, err := client.ListOrders(context.Background(), filter, 5, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Orders) != 1 || page.Orders[0].ID != "o2" || page.EndCursor != "c2" || !page.HasNextPage {
+		t.Fatalf("unexpected page %+v", page)
+	}
+}
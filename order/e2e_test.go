@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/order/pb"
 )
 
@@ -19,7 +20,7 @@ func TestE2E_PostAndGetOrder(t *testing.T) {
 		t.Fatalf("failed to create account: %v", err)
 	}
 
-	prod, err := integrationCatalogClient.PostProduct(ctx, "E2E Laptop", "High-end laptop", 1500.00)
+	prod, err := integrationCatalogClient.PostProduct(ctx, "E2E Laptop", "High-end laptop", money.New(150000, "USD"), nil)
 	if err != nil {
 		t.Fatalf("failed to create product: %v", err)
 	}
@@ -37,7 +38,7 @@ func TestE2E_PostAndGetOrder(t *testing.T) {
 	if postRes.Order.Id == "" {
 		t.Fatal("expected order id")
 	}
-	if postRes.Order.TotalPrice != 3000.00 {
+	if postRes.Order.TotalPrice.GetAmount() != 300000 {
 		t.Errorf("expected total price 3000, got %v", postRes.Order.TotalPrice)
 	}
 	if len(postRes.Order.Products) != 1 {
@@ -61,7 +62,7 @@ func TestE2E_PostAndGetOrder(t *testing.T) {
 	for _, order := range getRes.Orders {
 		if order.Id == postRes.Order.Id {
 			found = true
-			if order.TotalPrice != 3000.00 {
+			if order.TotalPrice.GetAmount() != 300000 {
 				t.Errorf("expected total 3000, got %v", order.TotalPrice)
 			}
 			break
@@ -83,12 +84,12 @@ func TestE2E_PostOrderWithMultipleProducts(t *testing.T) {
 		t.Fatalf("failed to create account: %v", err)
 	}
 
-	prod1, err := integrationCatalogClient.PostProduct(ctx, "Mouse", "Gaming mouse", 50.00)
+	prod1, err := integrationCatalogClient.PostProduct(ctx, "Mouse", "Gaming mouse", money.New(5000, "USD"), nil)
 	if err != nil {
 		t.Fatalf("failed to create product 1: %v", err)
 	}
 
-	prod2, err := integrationCatalogClient.PostProduct(ctx, "Keyboard", "Mechanical keyboard", 150.00)
+	prod2, err := integrationCatalogClient.PostProduct(ctx, "Keyboard", "Mechanical keyboard", money.New(15000, "USD"), nil)
 	if err != nil {
 		t.Fatalf("failed to create product 2: %v", err)
 	}
@@ -104,9 +105,9 @@ func TestE2E_PostOrderWithMultipleProducts(t *testing.T) {
 		t.Fatalf("failed to post order: %v", err)
 	}
 
-	expectedTotal := 50.00*3 + 150.00
-	if postRes.Order.TotalPrice != expectedTotal {
-		t.Errorf("expected total %v, got %v", expectedTotal, postRes.Order.TotalPrice)
+	expectedTotal := int64(5000*3 + 15000)
+	if postRes.Order.TotalPrice.GetAmount() != expectedTotal {
+		t.Errorf("expected total %v, got %v", expectedTotal, postRes.Order.TotalPrice.GetAmount())
 	}
 	if len(postRes.Order.Products) != 2 {
 		t.Errorf("expected 2 products, got %d", len(postRes.Order.Products))
@@ -0,0 +1,60 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	// IdempotencyKeyTTL is how long a PostOrder idempotency key is remembered.
+	IdempotencyKeyTTL = 24 * time.Hour
+	// idempotencyLease is how long a request may hold a key without finishing
+	// before a retry is allowed to take it over.
+	idempotencyLease = 30 * time.Second
+	// idempotencyPurgeInterval is how often expired keys are deleted.
+	idempotencyPurgeInterval = time.Hour
+)
+
+var (
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyInUse   = errors.New("a request with this idempotency key is still in progress")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used for a different request")
+)
+
+// IdempotencyRecord is the stored state of a PostOrder idempotency key.
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	// Token identifies the request currently allowed to place the order.
+	Token string
+	// OrderID is set once the order has been placed.
+	OrderID     string
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
+
+// IdempotencyClaim is held by the one request allowed to place the order for a key.
+type IdempotencyClaim struct {
+	Key   string
+	Token string
+}
+
+// runIdempotencyPurge deletes expired keys every idempotencyPurgeInterval
+// until ctx is done.
+func runIdempotencyPurge(ctx context.Context, s Service) {
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PurgeIdempotencyKeys(ctx); err != nil {
+			log.Println("idempotency key purge:", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package order
+
+import (
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+)
+
+const (
+	// DefaultPageSize is the number of orders ListOrders returns when no size is given.
+	DefaultPageSize = 20
+	// MaxPageSize caps the number of orders returned by a single ListOrders call.
+	MaxPageSize = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// OrderFilter narrows down ListOrders. Zero fields match every order.
+type OrderFilter struct {
+	AccountID string
+	// CreatedAfter and CreatedBefore bound the creation time, inclusive and
+	// exclusive respectively.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Statuses matches orders in any of the given statuses.
+	Statuses []OrderStatus
+	// MinTotal matches orders in its currency totalling at least its amount.
+	MinTotal *money.Money
+}
+
+// OrderPage is one page of ListOrders, newest order first.
+type OrderPage struct {
+	Orders []Order
+	// EndCursor is passed as after to fetch the next page. It is empty if
+	// the page has no orders.
+	EndCursor   string
+	HasNextPage bool
+}
+
+// Cursor returns the cursor pointing just past the order with the given ID.
+func Cursor(orderID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(orderID))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	id, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(id) == 0 {
+		return "", ErrInvalidCursor
+	}
+	return string(id), nil
+}
@@ -0,0 +1,289 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+var errDuplicateOrder = errors.New("order already exists")
+
+// memoryRepository keeps orders in memory, for development and tests without
+// Postgres. Each method holds the lock for its whole run, which stands in
+// for the transactions of the Postgres repository. Everything is lost when
+// the process exits.
+type memoryRepository struct {
+	mu        sync.Mutex
+	orders    map[string]*Order
+	restocked map[string]bool
+	keys      map[string]IdempotencyRecord
+	sagas     map[string]Saga
+}
+
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		orders:    map[string]*Order{},
+		restocked: map[string]bool{},
+		keys:      map[string]IdempotencyRecord{},
+		sagas:     map[string]Saga{},
+	}
+}
+
+func (r *memoryRepository) Close() {}
+
+func (r *memoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// copyOrder returns o with lines of its own, so that callers can't change
+// the stored order.
+func copyOrder(o Order) Order {
+	o.Products = slices.Clone(o.Products)
+	if o.Products == nil {
+		o.Products = []OrderedProduct{}
+	}
+	return o
+}
+
+// PutOrder stores o. If claim is set the order is also linked to its
+// idempotency key, which fails with ErrIdempotencyKeyInUse if another request
+// has taken the key over. A saga with the ID of o is completed along with
+// it, which fails with ErrSagaNotPending unless the saga is pending.
+func (r *memoryRepository) PutOrder(ctx context.Context, o Order, claim *IdempotencyClaim) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.orders[o.ID]; ok {
+		return errDuplicateOrder
+	}
+	if saga, ok := r.sagas[o.ID]; ok && saga.Status != SagaPending {
+		return ErrSagaNotPending
+	}
+	if claim != nil {
+		rec, ok := r.keys[claim.Key]
+		if !ok || rec.Token != claim.Token || rec.OrderID != "" {
+			return ErrIdempotencyKeyInUse
+		}
+		rec.OrderID = o.ID
+		r.keys[claim.Key] = rec
+	}
+
+	stored := copyOrder(o)
+	for i := range stored.Products {
+		// Lines are stored in the currency of the order, with their snapshot.
+		stored.Products[i].Price.Currency = o.TotalPrice.Currency
+		stored.Products[i].Snapshot = true
+	}
+	r.orders[o.ID] = &stored
+	if saga, ok := r.sagas[o.ID]; ok {
+		saga.Status, saga.UpdatedAt = SagaCompleted, o.CreatedAt
+		r.sagas[o.ID] = saga
+	}
+	return nil
+}
+
+// find returns the orders matching keep, sorted by ID. Like the join of the
+// Postgres repository, orders without lines are left out.
+func (r *memoryRepository) find(keep func(o *Order) bool, desc bool) []Order {
+	orders := []Order{}
+	for _, o := range r.orders {
+		if len(o.Products) > 0 && keep(o) {
+			orders = append(orders, copyOrder(*o))
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if desc {
+			return orders[i].ID > orders[j].ID
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders
+}
+
+func (r *memoryRepository) GetOrder(ctx context.Context, id string) (*Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	orders := r.find(func(o *Order) bool { return o.ID == id }, false)
+	if len(orders) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	return &orders[0], nil
+}
+
+func (r *memoryRepository) GetOrderForAccount(ctx context.Context, accountID string) ([]Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.find(func(o *Order) bool { return o.AccountID == accountID }, false), nil
+}
+
+func (r *memoryRepository) GetOrdersForAccounts(ctx context.Context, accountIDs []string) ([]Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.find(func(o *Order) bool { return slices.Contains(accountIDs, o.AccountID) }, false), nil
+}
+
+// ListOrders returns up to limit orders matching filter, newest first,
+// starting after the order with ID afterID if set.
+func (r *memoryRepository) ListOrders(ctx context.Context, filter OrderFilter, afterID string, limit int) ([]Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	orders := r.find(func(o *Order) bool {
+		switch {
+		case afterID != "" && o.ID >= afterID,
+			filter.AccountID != "" && o.AccountID != filter.AccountID,
+			!filter.CreatedAfter.IsZero() && o.CreatedAt.Before(filter.CreatedAfter),
+			!filter.CreatedBefore.IsZero() && !o.CreatedAt.Before(filter.CreatedBefore),
+			len(filter.Statuses) != 0 && !slices.Contains(filter.Statuses, o.Status),
+			filter.MinTotal != nil && (o.TotalPrice.Currency != filter.MinTotal.Currency || o.TotalPrice.Amount < filter.MinTotal.Amount):
+			return false
+		}
+		return true
+	}, true)
+	if limit < len(orders) {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
+
+func (r *memoryRepository) GetOrderStatus(ctx context.Context, id string) (OrderStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, ok := r.orders[id]
+	if !ok {
+		return "", ErrOrderNotFound
+	}
+	return o.Status, nil
+}
+
+// UpdateOrderStatus moves an order from c.From to c.To. It fails with
+// ErrStatusConflict if the order is no longer in c.From.
+func (r *memoryRepository) UpdateOrderStatus(ctx context.Context, c StatusChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, ok := r.orders[c.OrderID]
+	if !ok || o.Status != c.From {
+		return ErrStatusConflict
+	}
+	o.Status = c.To
+	return nil
+}
+
+// ClaimRestock flags a cancelled order's stock as returned and returns its
+// lines. Only the first caller gets the lines.
+func (r *memoryRepository) ClaimRestock(ctx context.Context, id string) ([]OrderedProduct, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	products := []OrderedProduct{}
+	o, ok := r.orders[id]
+	if !ok || o.Status != StatusCancelled || r.restocked[id] {
+		return products, nil
+	}
+	r.restocked[id] = true
+	for _, p := range o.Products {
+		products = append(products, OrderedProduct{ID: p.ID, Quantity: p.Quantity})
+	}
+	return products, nil
+}
+
+func (r *memoryRepository) ReleaseRestock(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.restocked, id)
+	return nil
+}
+
+// ClaimIdempotencyKey stores rec unless its key is already held, following
+// the same takeover rules as the Postgres repository. The record stored
+// after the attempt is returned.
+func (r *memoryRepository) ClaimIdempotencyKey(ctx context.Context, rec IdempotencyRecord) (*IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	stored, ok := r.keys[rec.Key]
+	if !ok || stored.ExpiresAt.Before(now) ||
+		(stored.OrderID == "" && stored.LockedUntil.Before(now) && stored.Fingerprint == rec.Fingerprint) {
+		stored = rec
+		stored.OrderID = ""
+		r.keys[rec.Key] = stored
+	}
+	return &stored, nil
+}
+
+// ReleaseIdempotencyKey gives up a claim that did not lead to an order.
+func (r *memoryRepository) ReleaseIdempotencyKey(ctx context.Context, claim IdempotencyClaim) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rec, ok := r.keys[claim.Key]; ok && rec.Token == claim.Token && rec.OrderID == "" {
+		delete(r.keys, claim.Key)
+	}
+	return nil
+}
+
+func (r *memoryRepository) PurgeIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for key, rec := range r.keys {
+		if rec.ExpiresAt.Before(expiredBefore) {
+			delete(r.keys, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func copySteps(steps []SagaStep) []SagaStep {
+	copied := make([]SagaStep, len(steps))
+	for i, step := range steps {
+		step.ProductIDs = slices.Clone(step.ProductIDs)
+		step.Deltas = slices.Clone(step.Deltas)
+		copied[i] = step
+	}
+	return copied
+}
+
+// PutSaga stores s, keeping the account and creation time of an existing
+// saga with the same ID.
+func (r *memoryRepository) PutSaga(ctx context.Context, s Saga) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.sagas[s.ID]; ok {
+		if !existing.Status.canBecome(s.Status) {
+			return ErrSagaNotPending
+		}
+		s.AccountID, s.CreatedAt = existing.AccountID, existing.CreatedAt
+	}
+	s.Steps = copySteps(s.Steps)
+	r.sagas[s.ID] = s
+	return nil
+}
+
+func (r *memoryRepository) ListPendingSagas(ctx context.Context, updatedBefore time.Time) ([]Saga, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sagas := []Saga{}
+	for _, s := range r.sagas {
+		if (s.Status == SagaPending || s.Status == SagaCompensating) && s.UpdatedAt.Before(updatedBefore) {
+			s.Steps = copySteps(s.Steps)
+			sagas = append(sagas, s)
+		}
+	}
+	sort.Slice(sagas, func(i, j int) bool { return sagas[i].CreatedAt.Before(sagas[j].CreatedAt) })
+	return sagas, nil
+}
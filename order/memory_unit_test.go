@@ -0,0 +1,160 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+)
+
+func memoryOrder(id, accountID string, status OrderStatus, total int64) Order {
+	return Order{
+		ID:         id,
+		CreatedAt:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		AccountID:  accountID,
+		TotalPrice: money.New(total, "USD"),
+		Status:     status,
+		Products:   []OrderedProduct{{ID: "p1", Name: "prod 1", Price: money.New(total, "USD"), Quantity: 1}},
+	}
+}
+
+func orderIDs(orders []Order) []string {
+	ids := make([]string, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID
+	}
+	return ids
+}
+
+func TestMemoryRepository_Orders(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	for _, o := range []Order{
+		memoryOrder("o2", "a1", StatusPaid, 500),
+		memoryOrder("o1", "a1", StatusPending, 100),
+		memoryOrder("o3", "a2", StatusPaid, 900),
+		memoryOrder("o4", "a1", StatusPaid, 1000),
+	} {
+		if err := r.PutOrder(ctx, o, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orders, _ := r.GetOrderForAccount(ctx, "a1")
+	if ids := orderIDs(orders); len(ids) != 3 || ids[0] != "o1" || ids[2] != "o4" {
+		t.Errorf("expected the account's orders by ID, got %v", ids)
+	}
+	if !orders[0].Products[0].Snapshot {
+		t.Error("expected stored lines to be snapshots")
+	}
+
+	filter := OrderFilter{AccountID: "a1", Statuses: []OrderStatus{StatusPaid}, MinTotal: &money.Money{Amount: 200, Currency: "USD"}}
+	orders, _ = r.ListOrders(ctx, filter, "", 1)
+	if ids := orderIDs(orders); len(ids) != 1 || ids[0] != "o4" {
+		t.Errorf("expected the newest matching order, got %v", ids)
+	}
+	orders, _ = r.ListOrders(ctx, filter, "o4", 10)
+	if ids := orderIDs(orders); len(ids) != 1 || ids[0] != "o2" {
+		t.Errorf("expected the next page to hold the older order, got %v", ids)
+	}
+
+	if _, err := r.GetOrder(ctx, "o9"); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound, got %v", err)
+	}
+}
+
+func TestMemoryRepository_StatusAndRestock(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	r.PutOrder(ctx, memoryOrder("o1", "a1", StatusPending, 100), nil)
+
+	if products, _ := r.ClaimRestock(ctx, "o1"); len(products) != 0 {
+		t.Errorf("expected only cancelled orders to be restocked, got %v", products)
+	}
+	if err := r.UpdateOrderStatus(ctx, StatusChange{OrderID: "o1", From: StatusPaid, To: StatusCancelled}); !errors.Is(err, ErrStatusConflict) {
+		t.Errorf("expected ErrStatusConflict, got %v", err)
+	}
+	if err := r.UpdateOrderStatus(ctx, StatusChange{OrderID: "o1", From: StatusPending, To: StatusCancelled}); err != nil {
+		t.Fatal(err)
+	}
+
+	products, _ := r.ClaimRestock(ctx, "o1")
+	if len(products) != 1 || products[0].ID != "p1" || products[0].Quantity != 1 {
+		t.Errorf("expected the order's lines, got %v", products)
+	}
+	if products, _ := r.ClaimRestock(ctx, "o1"); len(products) != 0 {
+		t.Errorf("expected the restock to be claimed once, got %v", products)
+	}
+	r.ReleaseRestock(ctx, "o1")
+	if products, _ := r.ClaimRestock(ctx, "o1"); len(products) != 1 {
+		t.Errorf("expected a released restock to be claimable again, got %v", products)
+	}
+}
+
+func TestMemoryRepository_IdempotencyKeys(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	now := time.Now()
+	rec := IdempotencyRecord{Key: "k1", Fingerprint: "f1", Token: "t1", LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour)}
+
+	stored, _ := r.ClaimIdempotencyKey(ctx, rec)
+	if stored.Token != "t1" {
+		t.Fatalf("expected the key to be claimed, got %+v", stored)
+	}
+	other := rec
+	other.Token = "t2"
+	if stored, _ := r.ClaimIdempotencyKey(ctx, other); stored.Token != "t1" {
+		t.Errorf("expected a locked key to stay with its holder, got %+v", stored)
+	}
+
+	if err := r.PutOrder(ctx, memoryOrder("o1", "a1", StatusPending, 100), &IdempotencyClaim{Key: "k1", Token: "t2"}); !errors.Is(err, ErrIdempotencyKeyInUse) {
+		t.Errorf("expected ErrIdempotencyKeyInUse, got %v", err)
+	}
+	if _, err := r.GetOrder(ctx, "o1"); !errors.Is(err, ErrOrderNotFound) {
+		t.Error("expected the order not to be stored without the key")
+	}
+	if err := r.PutOrder(ctx, memoryOrder("o1", "a1", StatusPending, 100), &IdempotencyClaim{Key: "k1", Token: "t1"}); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := r.ClaimIdempotencyKey(ctx, other); stored.OrderID != "o1" {
+		t.Errorf("expected the key to point at the order, got %+v", stored)
+	}
+
+	if n, _ := r.PurgeIdempotencyKeys(ctx, now.Add(2*time.Hour)); n != 1 {
+		t.Errorf("expected the expired key to be purged, got %d", n)
+	}
+}
+
+func TestMemoryRepository_Sagas(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+	now := time.Now()
+	r.PutSaga(ctx, Saga{ID: "s2", AccountID: "a1", Status: SagaPending, CreatedAt: now.Add(-time.Minute), UpdatedAt: now.Add(-time.Minute)})
+	r.PutSaga(ctx, Saga{ID: "s1", AccountID: "a1", Status: SagaPending, CreatedAt: now.Add(-time.Hour), UpdatedAt: now.Add(-time.Hour)})
+	r.PutSaga(ctx, Saga{ID: "s3", AccountID: "a1", Status: SagaPending, CreatedAt: now, UpdatedAt: now})
+	r.PutSaga(ctx, Saga{ID: "s2", Status: SagaCompleted, UpdatedAt: now})
+
+	sagas, _ := r.ListPendingSagas(ctx, now)
+	if len(sagas) != 1 || sagas[0].ID != "s1" {
+		t.Errorf("expected only the stale pending saga, got %+v", sagas)
+	}
+
+	if err := r.PutSaga(ctx, Saga{ID: "s2", Status: SagaPending, UpdatedAt: now}); !errors.Is(err, ErrSagaNotPending) {
+		t.Errorf("expected a completed saga to stay completed, got %v", err)
+	}
+	r.PutSaga(ctx, Saga{ID: "s1", Status: SagaCompensating, UpdatedAt: now.Add(-time.Hour)})
+	if err := r.PutSaga(ctx, Saga{ID: "s1", Status: SagaPending, UpdatedAt: now}); !errors.Is(err, ErrSagaNotPending) {
+		t.Errorf("expected a compensating saga not to go back to pending, got %v", err)
+	}
+	if sagas, _ := r.ListPendingSagas(ctx, now); len(sagas) != 1 || sagas[0].Status != SagaCompensating {
+		t.Errorf("expected the interrupted compensation to be listed, got %+v", sagas)
+	}
+	if err := r.PutOrder(ctx, memoryOrder("s1", "a1", StatusPending, 100), nil); !errors.Is(err, ErrSagaNotPending) {
+		t.Errorf("expected no order for a compensating saga, got %v", err)
+	}
+	if err := r.PutOrder(ctx, memoryOrder("s3", "a1", StatusPending, 100), nil); err != nil {
+		t.Fatal(err)
+	}
+}
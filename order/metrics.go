@@ -0,0 +1,30 @@
+package order
+
+import (
+	"math"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	ordersPlaced = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "orders_placed_total",
+		Help: "Orders placed. Replays of an idempotent request aren't counted again.",
+	})
+	outOfStockRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "orders_out_of_stock_rejections_total",
+		Help: "Orders rejected because some of their products were out of stock.",
+	})
+	revenue = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "orders_revenue_total",
+		Help: "Total price of the orders placed, in major units of currency.",
+	}, []string{"currency"})
+)
+
+func recordOrderPlaced(total money.Money) {
+	ordersPlaced.Inc()
+	major := float64(total.Amount) / math.Pow10(money.Exponent(total.Currency))
+	revenue.WithLabelValues(total.Currency).Add(major)
+}
@@ -0,0 +1,29 @@
+package order
+
+import (
+	"math"
+	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordOrderPlaced(t *testing.T) {
+	placed := testutil.ToFloat64(ordersPlaced)
+	usd := testutil.ToFloat64(revenue.WithLabelValues("USD"))
+	jpy := testutil.ToFloat64(revenue.WithLabelValues("JPY"))
+
+	recordOrderPlaced(money.New(1999, "USD"))
+	recordOrderPlaced(money.New(500, "JPY"))
+
+	if got := testutil.ToFloat64(ordersPlaced) - placed; got != 2 {
+		t.Errorf("expected 2 orders counted, got %v", got)
+	}
+	// Other tests place orders too, so the sum may not be exact.
+	if got := testutil.ToFloat64(revenue.WithLabelValues("USD")) - usd; math.Abs(got-19.99) > 1e-6 {
+		t.Errorf("expected 19.99 USD of revenue, got %v", got)
+	}
+	if got := testutil.ToFloat64(revenue.WithLabelValues("JPY")) - jpy; got != 500 {
+		t.Errorf("expected 500 JPY of revenue, got %v", got)
+	}
+}
@@ -0,0 +1,16 @@
+package order
+
+import (
+	"database/sql"
+	"embed"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// NewMigrator returns the migrator of the order database behind db.
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	return migrate.NewFromDir(db, migrations, "migrations", "order")
+}
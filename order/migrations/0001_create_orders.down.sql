@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS orders_products;
+DROP TABLE IF EXISTS orders;
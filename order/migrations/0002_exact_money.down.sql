@@ -0,0 +1,6 @@
+-- Amounts are taken to have two decimals, like MONEY, whatever their currency.
+ALTER TABLE orders ADD COLUMN total_price MONEY;
+UPDATE orders SET total_price = (total_amount / 100.0)::numeric::money;
+ALTER TABLE orders ALTER COLUMN total_price SET NOT NULL;
+ALTER TABLE orders DROP COLUMN currency;
+ALTER TABLE orders DROP COLUMN total_amount;
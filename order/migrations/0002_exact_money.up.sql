@@ -0,0 +1,8 @@
+-- Moves order totals from MONEY to integer minor units with a currency. All
+-- existing amounts are taken to be in USD.
+ALTER TABLE orders ADD COLUMN total_amount BIGINT;
+ALTER TABLE orders ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE orders SET total_amount = ROUND(total_price::numeric * 100);
+ALTER TABLE orders ALTER COLUMN total_amount SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN currency DROP DEFAULT;
+ALTER TABLE orders DROP COLUMN total_price;
@@ -0,0 +1,3 @@
+ALTER TABLE orders_products DROP COLUMN IF EXISTS price_amount;
+ALTER TABLE orders_products DROP COLUMN IF EXISTS description;
+ALTER TABLE orders_products DROP COLUMN IF EXISTS name;
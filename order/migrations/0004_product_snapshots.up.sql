@@ -0,0 +1,5 @@
+-- The name, description and price of a product when it was ordered. Lines
+-- ordered before they were kept have none and are filled in from the catalog.
+ALTER TABLE orders_products ADD COLUMN IF NOT EXISTS name TEXT;
+ALTER TABLE orders_products ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE orders_products ADD COLUMN IF NOT EXISTS price_amount BIGINT;
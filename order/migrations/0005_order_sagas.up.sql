@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS order_sagas (
+    id CHAR(27) PRIMARY KEY,
+    account_id CHAR(27) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    steps JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_sagas_pending_idx ON order_sagas (updated_at) WHERE status = 'pending';
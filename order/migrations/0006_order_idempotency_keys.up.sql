@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS order_idempotency_keys (
+    idempotency_key VARCHAR(255) PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    token CHAR(27) NOT NULL,
+    order_id CHAR(27) REFERENCES orders (id) ON DELETE CASCADE,
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_idempotency_keys_expiry_idx ON order_idempotency_keys (expires_at);
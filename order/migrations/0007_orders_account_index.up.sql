@@ -0,0 +1,2 @@
+-- Serves the pages of an account's orders, which are ordered by ID.
+CREATE INDEX IF NOT EXISTS orders_account_idx ON orders (account_id, id);
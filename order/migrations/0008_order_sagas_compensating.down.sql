@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS order_sagas_open_idx;
+CREATE INDEX IF NOT EXISTS order_sagas_pending_idx ON order_sagas (updated_at) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+-- Recovery also lists the sagas whose compensation was interrupted.
+DROP INDEX IF EXISTS order_sagas_pending_idx;
+CREATE INDEX IF NOT EXISTS order_sagas_open_idx ON order_sagas (updated_at) WHERE status IN ('pending', 'compensating');
@@ -0,0 +1,40 @@
+package order
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewMigrator(t *testing.T) {
+	m, err := NewMigrator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrations := m.Migrations()
+	if len(migrations) == 0 {
+		t.Fatal("expected embedded migrations")
+	}
+	for i, mig := range migrations {
+		if mig.Version != int64(i+1) {
+			t.Errorf("expected version %d, got %s", i+1, mig)
+		}
+		if mig.Down == "" {
+			t.Errorf("expected %s to be reversible", mig)
+		}
+	}
+}
+
+// Databases created before migrations have the schema of the first one, so
+// it must stay as it was; later changes go in migrations of their own.
+func TestNewMigrator_StartsFromTheBaselineSchema(t *testing.T) {
+	m, err := NewMigrator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseline := m.Migrations()[0].Up
+	if !strings.Contains(baseline, "total_price MONEY") || strings.Contains(baseline, "total_amount") {
+		t.Errorf("expected the first migration to create the baseline schema, got:\n%s", baseline)
+	}
+}
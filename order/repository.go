@@ -3,15 +3,35 @@ package order
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/metrics"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+	"github.com/XSAM/otelsql"
 	"github.com/lib/pq"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
 type Repository interface {
 	Close()
-	PutOrder(ctx context.Context, o Order) error
+	Ping(ctx context.Context) error
+	PutOrder(ctx context.Context, o Order, claim *IdempotencyClaim) error
+	GetOrder(ctx context.Context, id string) (*Order, error)
 	GetOrderForAccount(ctx context.Context, accountID string) ([]Order, error)
+	GetOrdersForAccounts(ctx context.Context, accountIDs []string) ([]Order, error)
+	ListOrders(ctx context.Context, filter OrderFilter, afterID string, limit int) ([]Order, error)
+	GetOrderStatus(ctx context.Context, id string) (OrderStatus, error)
+	UpdateOrderStatus(ctx context.Context, c StatusChange) error
+	ClaimRestock(ctx context.Context, id string) ([]OrderedProduct, error)
+	ReleaseRestock(ctx context.Context, id string) error
+	ClaimIdempotencyKey(ctx context.Context, rec IdempotencyRecord) (*IdempotencyRecord, error)
+	ReleaseIdempotencyKey(ctx context.Context, claim IdempotencyClaim) error
+	PurgeIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error)
+	SagaLog
 }
 
 type postgresRepository struct {
@@ -19,7 +39,7 @@ type postgresRepository struct {
 }
 
 func NewPostgresRepository(url string) (Repository, error) {
-	db, err := sql.Open("postgres", url)
+	db, err := otelsql.Open("postgres", url, otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL))
 	if err != nil {
 		return nil, err
 	}
@@ -28,6 +48,9 @@ func NewPostgresRepository(url string) (Repository, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := metrics.RegisterDB(db, "order"); err != nil {
+		return nil, err
+	}
 
 	return &postgresRepository{db}, nil
 }
@@ -36,8 +59,17 @@ func (r *postgresRepository) Close() {
 	r.db.Close()
 }
 
-func (r *postgresRepository) PutOrder(ctx context.Context, o Order) (err error) {
-	query := `INSERT INTO orders (id, created_at, account_id, total_price) VALUES ($1, $2, $3, $4)`
+func (r *postgresRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// PutOrder stores o. If claim is set the order is also linked to its
+// idempotency key, which fails with ErrIdempotencyKeyInUse if another request
+// has taken the key over. A saga with the ID of o is completed in the same
+// transaction, which fails with ErrSagaNotPending unless the saga is pending,
+// so recovery never compensates a placed order.
+func (r *postgresRepository) PutOrder(ctx context.Context, o Order, claim *IdempotencyClaim) (err error) {
+	query := `INSERT INTO orders (id, created_at, account_id, total_amount, currency, status) VALUES ($1, $2, $3, $4, $5, $6)`
 
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -57,20 +89,82 @@ func (r *postgresRepository) PutOrder(ctx context.Context, o Order) (err error)
 		o.ID,
 		o.CreatedAt,
 		o.AccountID,
-		o.TotalPrice,
+		o.TotalPrice.Amount,
+		o.TotalPrice.Currency,
+		string(o.Status),
 	)
 	if err != nil {
 		return
 	}
 
-	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("orders_products", "order_id", "product_id", "quantity"))
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO order_status_history (order_id, status, changed_at) VALUES ($1, $2, $3)`,
+		o.ID,
+		string(o.Status),
+		o.CreatedAt,
+	)
+	if err != nil {
+		return
+	}
+
+	if claim != nil {
+		var res sql.Result
+		res, err = tx.ExecContext(
+			ctx,
+			`UPDATE order_idempotency_keys SET order_id = $1 WHERE idempotency_key = $2 AND token = $3 AND order_id IS NULL`,
+			o.ID,
+			claim.Key,
+			claim.Token,
+		)
+		if err != nil {
+			return
+		}
+		var n int64
+		if n, err = res.RowsAffected(); err != nil {
+			return
+		}
+		if n == 0 {
+			return ErrIdempotencyKeyInUse
+		}
+	}
+
+	var res sql.Result
+	res, err = tx.ExecContext(
+		ctx,
+		`UPDATE order_sagas SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4`,
+		string(SagaCompleted),
+		o.CreatedAt,
+		o.ID,
+		string(SagaPending),
+	)
+	if err != nil {
+		return
+	}
+	var n int64
+	if n, err = res.RowsAffected(); err != nil {
+		return
+	}
+	if n != 1 {
+		// Orders placed without a saga have no row to complete.
+		var exists bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM order_sagas WHERE id = $1)`, o.ID).Scan(&exists)
+		if err != nil {
+			return
+		}
+		if exists {
+			return ErrSagaNotPending
+		}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("orders_products", "order_id", "product_id", "quantity", "name", "description", "price_amount"))
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
 	for _, p := range o.Products {
-		_, err = stmt.ExecContext(ctx, o.ID, p.ID, p.Quantity)
+		_, err = stmt.ExecContext(ctx, o.ID, p.ID, p.Quantity, p.Name, p.Description, p.Price.Amount)
 		if err != nil {
 			return
 		}
@@ -83,20 +177,92 @@ func (r *postgresRepository) PutOrder(ctx context.Context, o Order) (err error)
 	return
 }
 
+func (r *postgresRepository) GetOrder(ctx context.Context, id string) (*Order, error) {
+	orders, err := r.queryOrders(ctx, `o.id = $1`, `o.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrOrderNotFound
+	}
+
+	return &orders[0], nil
+}
+
 func (r *postgresRepository) GetOrderForAccount(ctx context.Context, accountID string) ([]Order, error) {
+	return r.queryOrders(ctx, `o.account_id = $1`, `o.id`, accountID)
+}
+
+func (r *postgresRepository) GetOrdersForAccounts(ctx context.Context, accountIDs []string) ([]Order, error) {
+	return r.queryOrders(ctx, `o.account_id = ANY($1)`, `o.id`, pq.Array(accountIDs))
+}
+
+// ListOrders returns up to limit orders matching filter, newest first,
+// starting after the order with ID afterID if set. Order IDs are KSUIDs, so
+// sorting by ID sorts by creation time while staying unique.
+func (r *postgresRepository) ListOrders(ctx context.Context, filter OrderFilter, afterID string, limit int) ([]Order, error) {
+	conds := []string{}
+	args := []any{}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if afterID != "" {
+		add(`id < $%d`, afterID)
+	}
+	if filter.AccountID != "" {
+		add(`account_id = $%d`, filter.AccountID)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		add(`created_at >= $%d`, filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		add(`created_at < $%d`, filter.CreatedBefore)
+	}
+	if len(filter.Statuses) != 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, st := range filter.Statuses {
+			statuses[i] = string(st)
+		}
+		add(`status = ANY($%d)`, pq.Array(statuses))
+	}
+	if filter.MinTotal != nil {
+		add(`currency = $%d`, filter.MinTotal.Currency)
+		add(`total_amount >= $%d`, filter.MinTotal.Amount)
+	}
+
+	where := ""
+	if len(conds) != 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, limit)
+	page := fmt.Sprintf(`o.id IN (SELECT id FROM orders %s ORDER BY id DESC LIMIT $%d)`, where, len(args))
+
+	return r.queryOrders(ctx, page, `o.id DESC`, args...)
+}
+
+// queryOrders loads the orders matching where, together with their lines,
+// sorted by orderBy.
+func (r *postgresRepository) queryOrders(ctx context.Context, where, orderBy string, args ...any) ([]Order, error) {
 	query := `
 		SELECT o.id,
 		       o.created_at,
 		       o.account_id,
-		       o.total_price::numeric::float8,
+		       o.total_amount,
+		       o.currency,
+		       o.status,
 		       op.product_id,
-		       op.quantity
+		       op.quantity,
+		       op.name,
+		       op.description,
+		       op.price_amount
 		FROM orders o
 		JOIN orders_products op ON (o.id = op.order_id)
-		WHERE o.account_id = $1
-		ORDER BY o.id
+		WHERE ` + where + `
+		ORDER BY ` + orderBy + `
 	`
-	rows, err := r.db.QueryContext(ctx, query, accountID)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -106,12 +272,13 @@ func (r *postgresRepository) GetOrderForAccount(ctx context.Context, accountID s
 	orderIDs := []string{}
 
 	for rows.Next() {
-		var id, accID, productID string
+		var id, accID, currency, status, productID string
 		var createdAt pq.NullTime
-		var totalPrice float64
-		var quantity int64
+		var totalAmount, quantity int64
+		var name, description sql.NullString
+		var price sql.NullInt64
 
-		if err := rows.Scan(&id, &createdAt, &accID, &totalPrice, &productID, &quantity); err != nil {
+		if err := rows.Scan(&id, &createdAt, &accID, &totalAmount, &currency, &status, &productID, &quantity, &name, &description, &price); err != nil {
 			return nil, err
 		}
 
@@ -121,7 +288,8 @@ func (r *postgresRepository) GetOrderForAccount(ctx context.Context, accountID s
 				ID:         id,
 				CreatedAt:  createdAt.Time,
 				AccountID:  accID,
-				TotalPrice: totalPrice,
+				TotalPrice: money.New(totalAmount, currency),
+				Status:     OrderStatus(status),
 				Products:   []OrderedProduct{},
 			}
 			ordersMap[id] = ord
@@ -129,8 +297,12 @@ func (r *postgresRepository) GetOrderForAccount(ctx context.Context, accountID s
 		}
 
 		ord.Products = append(ord.Products, OrderedProduct{
-			ID:       productID,
-			Quantity: uint32(quantity),
+			ID:          productID,
+			Name:        name.String,
+			Description: description.String,
+			Price:       money.New(price.Int64, currency),
+			Quantity:    uint32(quantity),
+			Snapshot:    name.Valid,
 		})
 	}
 
@@ -147,3 +319,245 @@ func (r *postgresRepository) GetOrderForAccount(ctx context.Context, accountID s
 
 	return orders, nil
 }
+
+func (r *postgresRepository) GetOrderStatus(ctx context.Context, id string) (OrderStatus, error) {
+	var status string
+	err := r.db.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = $1`, id).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrOrderNotFound
+		}
+		return "", err
+	}
+
+	return OrderStatus(status), nil
+}
+
+// UpdateOrderStatus moves an order from c.From to c.To and appends the change
+// to its history. It fails with ErrStatusConflict if the order is no longer in c.From.
+func (r *postgresRepository) UpdateOrderStatus(ctx context.Context, c StatusChange) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE orders SET status = $1 WHERE id = $2 AND status = $3`,
+		string(c.To),
+		c.OrderID,
+		string(c.From),
+	)
+	if err != nil {
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		return ErrStatusConflict
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO order_status_history (order_id, status, changed_at) VALUES ($1, $2, $3)`,
+		c.OrderID,
+		string(c.To),
+		c.ChangedAt,
+	)
+	return
+}
+
+// ClaimRestock flags a cancelled order's stock as returned and returns its
+// lines. Only the first caller gets the lines, which keeps restocking from
+// being applied twice.
+func (r *postgresRepository) ClaimRestock(ctx context.Context, id string) (products []OrderedProduct, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE orders SET restocked_at = $1 WHERE id = $2 AND status = $3 AND restocked_at IS NULL`,
+		time.Now().UTC(),
+		id,
+		string(StatusCancelled),
+	)
+	if err != nil {
+		return nil, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return []OrderedProduct{}, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT product_id, quantity FROM orders_products WHERE order_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products = []OrderedProduct{}
+	for rows.Next() {
+		var p OrderedProduct
+		var quantity int64
+		if err = rows.Scan(&p.ID, &quantity); err != nil {
+			return nil, err
+		}
+		p.Quantity = uint32(quantity)
+		products = append(products, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *postgresRepository) ReleaseRestock(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE orders SET restocked_at = NULL WHERE id = $1`, id)
+	return err
+}
+
+// ClaimIdempotencyKey stores rec unless its key is already held. A key can be
+// taken over once it has expired, or if the request holding it stopped
+// before placing an order. The record stored after the attempt is returned;
+// its Token tells whether the claim succeeded.
+func (r *postgresRepository) ClaimIdempotencyKey(ctx context.Context, rec IdempotencyRecord) (*IdempotencyRecord, error) {
+	query := `
+		INSERT INTO order_idempotency_keys (idempotency_key, fingerprint, token, locked_until, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (idempotency_key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+		    token = EXCLUDED.token,
+		    order_id = NULL,
+		    locked_until = EXCLUDED.locked_until,
+		    expires_at = EXCLUDED.expires_at
+		WHERE order_idempotency_keys.expires_at < $6
+		   OR (order_idempotency_keys.order_id IS NULL
+		       AND order_idempotency_keys.locked_until < $6
+		       AND order_idempotency_keys.fingerprint = EXCLUDED.fingerprint)
+		RETURNING fingerprint, token, order_id, locked_until, expires_at
+	`
+	stored := IdempotencyRecord{Key: rec.Key}
+	var orderID sql.NullString
+	err := r.db.QueryRowContext(ctx, query, rec.Key, rec.Fingerprint, rec.Token, rec.LockedUntil, rec.ExpiresAt, time.Now().UTC()).
+		Scan(&stored.Fingerprint, &stored.Token, &orderID, &stored.LockedUntil, &stored.ExpiresAt)
+	if err == sql.ErrNoRows {
+		err = r.db.QueryRowContext(
+			ctx,
+			`SELECT fingerprint, token, order_id, locked_until, expires_at FROM order_idempotency_keys WHERE idempotency_key = $1`,
+			rec.Key,
+		).Scan(&stored.Fingerprint, &stored.Token, &orderID, &stored.LockedUntil, &stored.ExpiresAt)
+	}
+	if err != nil {
+		return nil, err
+	}
+	stored.OrderID = orderID.String
+
+	return &stored, nil
+}
+
+// ReleaseIdempotencyKey gives up a claim that did not lead to an order, so
+// that a retry does not have to wait for the lease to run out.
+func (r *postgresRepository) ReleaseIdempotencyKey(ctx context.Context, claim IdempotencyClaim) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`DELETE FROM order_idempotency_keys WHERE idempotency_key = $1 AND token = $2 AND order_id IS NULL`,
+		claim.Key,
+		claim.Token,
+	)
+	return err
+}
+
+func (r *postgresRepository) PurgeIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM order_idempotency_keys WHERE expires_at < $1`, expiredBefore)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *postgresRepository) PutSaga(ctx context.Context, s Saga) error {
+	query := `
+		INSERT INTO order_sagas (id, account_id, status, steps, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE
+		SET status = EXCLUDED.status,
+		    steps = EXCLUDED.steps,
+		    updated_at = EXCLUDED.updated_at
+		WHERE order_sagas.status = $7
+		   OR (order_sagas.status = $8 AND EXCLUDED.status <> $7)
+	`
+	steps, err := json.Marshal(s.Steps)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, query, s.ID, s.AccountID, string(s.Status), steps, s.CreatedAt, s.UpdatedAt, string(SagaPending), string(SagaCompensating))
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSagaNotPending
+	}
+	return nil
+}
+
+func (r *postgresRepository) ListPendingSagas(ctx context.Context, updatedBefore time.Time) ([]Saga, error) {
+	query := `
+		SELECT id, account_id, status, steps, created_at, updated_at
+		FROM order_sagas
+		WHERE status IN ($1, $2) AND updated_at < $3
+		ORDER BY created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, string(SagaPending), string(SagaCompensating), updatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sagas := []Saga{}
+	for rows.Next() {
+		var s Saga
+		var status string
+		var steps []byte
+		if err := rows.Scan(&s.ID, &s.AccountID, &status, &steps, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		s.Status = SagaStatus(status)
+		if err := json.Unmarshal(steps, &s.Steps); err != nil {
+			return nil, err
+		}
+		sagas = append(sagas, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sagas, nil
+}
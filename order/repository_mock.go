@@ -12,6 +12,7 @@ package order
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,6 +41,36 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClaimIdempotencyKey mocks base method.
+func (m *MockRepository) ClaimIdempotencyKey(ctx context.Context, rec IdempotencyRecord) (*IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimIdempotencyKey", ctx, rec)
+	ret0, _ := ret[0].(*IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimIdempotencyKey indicates an expected call of ClaimIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) ClaimIdempotencyKey(ctx, rec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).ClaimIdempotencyKey), ctx, rec)
+}
+
+// ClaimRestock mocks base method.
+func (m *MockRepository) ClaimRestock(ctx context.Context, id string) ([]OrderedProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimRestock", ctx, id)
+	ret0, _ := ret[0].([]OrderedProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimRestock indicates an expected call of ClaimRestock.
+func (mr *MockRepositoryMockRecorder) ClaimRestock(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimRestock", reflect.TypeOf((*MockRepository)(nil).ClaimRestock), ctx, id)
+}
+
 // Close mocks base method.
 func (m *MockRepository) Close() {
 	m.ctrl.T.Helper()
@@ -52,6 +83,21 @@ func (mr *MockRepositoryMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockRepository)(nil).Close))
 }
 
+// GetOrder mocks base method.
+func (m *MockRepository) GetOrder(ctx context.Context, id string) (*Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, id)
+	ret0, _ := ret[0].(*Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockRepositoryMockRecorder) GetOrder(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockRepository)(nil).GetOrder), ctx, id)
+}
+
 // GetOrderForAccount mocks base method.
 func (m *MockRepository) GetOrderForAccount(ctx context.Context, accountID string) ([]Order, error) {
 	m.ctrl.T.Helper()
@@ -67,16 +113,161 @@ func (mr *MockRepositoryMockRecorder) GetOrderForAccount(ctx, accountID any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderForAccount", reflect.TypeOf((*MockRepository)(nil).GetOrderForAccount), ctx, accountID)
 }
 
+// GetOrderStatus mocks base method.
+func (m *MockRepository) GetOrderStatus(ctx context.Context, id string) (OrderStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderStatus", ctx, id)
+	ret0, _ := ret[0].(OrderStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderStatus indicates an expected call of GetOrderStatus.
+func (mr *MockRepositoryMockRecorder) GetOrderStatus(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderStatus", reflect.TypeOf((*MockRepository)(nil).GetOrderStatus), ctx, id)
+}
+
+// GetOrdersForAccounts mocks base method.
+func (m *MockRepository) GetOrdersForAccounts(ctx context.Context, accountIDs []string) ([]Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersForAccounts", ctx, accountIDs)
+	ret0, _ := ret[0].([]Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersForAccounts indicates an expected call of GetOrdersForAccounts.
+func (mr *MockRepositoryMockRecorder) GetOrdersForAccounts(ctx, accountIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersForAccounts", reflect.TypeOf((*MockRepository)(nil).GetOrdersForAccounts), ctx, accountIDs)
+}
+
+// ListOrders mocks base method.
+func (m *MockRepository) ListOrders(ctx context.Context, filter OrderFilter, afterID string, limit int) ([]Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, filter, afterID, limit)
+	ret0, _ := ret[0].([]Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockRepositoryMockRecorder) ListOrders(ctx, filter, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockRepository)(nil).ListOrders), ctx, filter, afterID, limit)
+}
+
+// ListPendingSagas mocks base method.
+func (m *MockRepository) ListPendingSagas(ctx context.Context, updatedBefore time.Time) ([]Saga, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingSagas", ctx, updatedBefore)
+	ret0, _ := ret[0].([]Saga)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingSagas indicates an expected call of ListPendingSagas.
+func (mr *MockRepositoryMockRecorder) ListPendingSagas(ctx, updatedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingSagas", reflect.TypeOf((*MockRepository)(nil).ListPendingSagas), ctx, updatedBefore)
+}
+
+// Ping mocks base method.
+func (m *MockRepository) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockRepositoryMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepository)(nil).Ping), ctx)
+}
+
+// PurgeIdempotencyKeys mocks base method.
+func (m *MockRepository) PurgeIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeIdempotencyKeys", ctx, expiredBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeIdempotencyKeys indicates an expected call of PurgeIdempotencyKeys.
+func (mr *MockRepositoryMockRecorder) PurgeIdempotencyKeys(ctx, expiredBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeIdempotencyKeys", reflect.TypeOf((*MockRepository)(nil).PurgeIdempotencyKeys), ctx, expiredBefore)
+}
+
 // PutOrder mocks base method.
-func (m *MockRepository) PutOrder(ctx context.Context, o Order) error {
+func (m *MockRepository) PutOrder(ctx context.Context, o Order, claim *IdempotencyClaim) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PutOrder", ctx, o)
+	ret := m.ctrl.Call(m, "PutOrder", ctx, o, claim)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PutOrder indicates an expected call of PutOrder.
-func (mr *MockRepositoryMockRecorder) PutOrder(ctx, o any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) PutOrder(ctx, o, claim any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutOrder", reflect.TypeOf((*MockRepository)(nil).PutOrder), ctx, o, claim)
+}
+
+// PutSaga mocks base method.
+func (m *MockRepository) PutSaga(ctx context.Context, s Saga) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutSaga", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutSaga indicates an expected call of PutSaga.
+func (mr *MockRepositoryMockRecorder) PutSaga(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutSaga", reflect.TypeOf((*MockRepository)(nil).PutSaga), ctx, s)
+}
+
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockRepository) ReleaseIdempotencyKey(ctx context.Context, claim IdempotencyClaim) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", ctx, claim)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) ReleaseIdempotencyKey(ctx, claim any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).ReleaseIdempotencyKey), ctx, claim)
+}
+
+// ReleaseRestock mocks base method.
+func (m *MockRepository) ReleaseRestock(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseRestock", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseRestock indicates an expected call of ReleaseRestock.
+func (mr *MockRepositoryMockRecorder) ReleaseRestock(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseRestock", reflect.TypeOf((*MockRepository)(nil).ReleaseRestock), ctx, id)
+}
+
+// UpdateOrderStatus mocks base method.
+func (m *MockRepository) UpdateOrderStatus(ctx context.Context, c StatusChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStatus", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrderStatus indicates an expected call of UpdateOrderStatus.
+func (mr *MockRepositoryMockRecorder) UpdateOrderStatus(ctx, c any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutOrder", reflect.TypeOf((*MockRepository)(nil).PutOrder), ctx, o)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockRepository)(nil).UpdateOrderStatus), ctx, c)
 }
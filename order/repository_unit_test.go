@@ -2,11 +2,13 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 )
 
 func newMockRepo(t *testing.T) (*postgresRepository, sqlmock.Sqlmock, func()) {
@@ -32,30 +34,40 @@ func TestRepoUnit_PutOrder_Success(t *testing.T) {
 		ID:         "o1",
 		CreatedAt:  time.Now(),
 		AccountID:  "a1",
-		TotalPrice: 100,
+		TotalPrice: money.New(10000, "USD"),
+		Status:     StatusPending,
 		Products: []OrderedProduct{
-			{ID: "p1", Quantity: 2},
-			{ID: "p2", Quantity: 1},
+			{ID: "p1", Name: "prod 1", Description: "desc 1", Price: money.New(3000, "USD"), Quantity: 2},
+			{ID: "p2", Name: "prod 2", Description: "desc 2", Price: money.New(4000, "USD"), Quantity: 1},
 		},
 	}
 
 	mock.ExpectBegin()
 
 	mock.ExpectExec(`INSERT INTO orders`).
-		WithArgs(o.ID, o.CreatedAt, o.AccountID, o.TotalPrice).
+		WithArgs(o.ID, o.CreatedAt, o.AccountID, o.TotalPrice.Amount, o.TotalPrice.Currency, "pending").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	mock.ExpectExec(`INSERT INTO order_status_history`).
+		WithArgs(o.ID, "pending", o.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// the saga placing the order is completed with it
+	mock.ExpectExec(`UPDATE order_sagas SET status`).
+		WithArgs("completed", o.CreatedAt, o.ID, "pending").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
 	// pq.CopyIn creates a COPY statement internally
 	mock.ExpectPrepare(`COPY "orders_products"`).
 		WillBeClosed()
 
 	// one exec per product row
 	mock.ExpectExec(`COPY "orders_products"`).
-		WithArgs(o.ID, "p1", int64(2)).
+		WithArgs(o.ID, "p1", int64(2), "prod 1", "desc 1", int64(3000)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	mock.ExpectExec(`COPY "orders_products"`).
-		WithArgs(o.ID, "p2", int64(1)).
+		WithArgs(o.ID, "p2", int64(1), "prod 2", "desc 2", int64(4000)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	// final flush call: Exec() with no args
@@ -64,7 +76,7 @@ func TestRepoUnit_PutOrder_Success(t *testing.T) {
 
 	mock.ExpectCommit()
 
-	err := repo.PutOrder(context.Background(), o)
+	err := repo.PutOrder(context.Background(), o, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -79,24 +91,36 @@ func TestRepoUnit_PutProduct_RolsBackOnError(t *testing.T) {
 	defer cleanup()
 
 	o := Order{
-		ID: "o1", Products: []OrderedProduct{{ID: "p1", Quantity: 1}},
+		ID: "o1", Status: StatusPending, Products: []OrderedProduct{{ID: "p1", Quantity: 1}},
 	}
 
 	mock.ExpectBegin()
 
 	mock.ExpectExec(`INSERT INTO orders`).
-		WithArgs(o.ID, o.CreatedAt, o.AccountID, o.TotalPrice).
+		WithArgs(o.ID, o.CreatedAt, o.AccountID, o.TotalPrice.Amount, o.TotalPrice.Currency, "pending").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(`INSERT INTO order_status_history`).
+		WithArgs(o.ID, "pending", o.CreatedAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	mock.ExpectExec(`UPDATE order_sagas SET status`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// the order was placed without a saga
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(o.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
 	mock.ExpectPrepare(`COPY orders_products`)
 
 	mock.ExpectExec(`COPY orders_products`).
-		WithArgs(o.ID, "p1", int64(1)).
+		WithArgs(o.ID, "p1", int64(1), "", "", int64(0)).
 		WillReturnError(fmt.Errorf("copy failed"))
 
 	mock.ExpectRollback()
 
-	err := repo.PutOrder(context.Background(), o)
+	err := repo.PutOrder(context.Background(), o, nil)
 	if err == nil {
 		t.Fatal("Expected error; got nil")
 	}
@@ -107,11 +131,11 @@ func TestRepoUnit_GetOrderForAccounts(t *testing.T) {
 	defer cleanup()
 
 	rows := sqlmock.NewRows([]string{
-		"id", "created_at", "account_id", "total_price", "product_id", "quantity",
+		"id", "created_at", "account_id", "total_amount", "currency", "status", "product_id", "quantity", "name", "description", "price",
 	}).
-		AddRow("o1", time.Now(), "a1", 50.0, "p1", int64(2)).
-		AddRow("o1", time.Now(), "a1", 50.0, "p2", int64(1)).
-		AddRow("o2", time.Now(), "a1", 20.0, "p3", int64(1))
+		AddRow("o1", time.Now(), "a1", int64(5000), "USD", "paid", "p1", int64(2), "prod 1", "desc 1", int64(2000)).
+		AddRow("o1", time.Now(), "a1", int64(5000), "USD", "paid", "p2", int64(1), "prod 2", "desc 2", int64(1000)).
+		AddRow("o2", time.Now(), "a1", int64(2000), "USD", "pending", "p3", int64(1), nil, nil, nil)
 
 	mock.ExpectQuery(`FROM orders o`).
 		WithArgs("a1").
@@ -129,4 +153,283 @@ func TestRepoUnit_GetOrderForAccounts(t *testing.T) {
 	if len(orders[0].Products) != 2 {
 		t.Errorf("expected 2 products in o1, got %d", len(orders[0].Products))
 	}
+
+	if orders[0].Status != StatusPaid || orders[1].Status != StatusPending {
+		t.Errorf("unexpected statuses: %s, %s", orders[0].Status, orders[1].Status)
+	}
+
+	p := orders[0].Products[0]
+	if !p.Snapshot || p.Name != "prod 1" || p.Description != "desc 1" || p.Price != money.New(2000, "USD") {
+		t.Errorf("expected stored line details, got %+v", p)
+	}
+	if orders[1].Products[0].Snapshot {
+		t.Errorf("expected line without stored details, got %+v", orders[1].Products[0])
+	}
+}
+
+func TestRepoUnit_UpdateOrderStatus_Success(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	c := StatusChange{OrderID: "o1", From: StatusPending, To: StatusPaid, ChangedAt: time.Now()}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE orders SET status`).
+		WithArgs("paid", "o1", "pending").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO order_status_history`).
+		WithArgs("o1", "paid", c.ChangedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.UpdateOrderStatus(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRepoUnit_UpdateOrderStatus_Conflict(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	c := StatusChange{OrderID: "o1", From: StatusPending, To: StatusPaid, ChangedAt: time.Now()}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE orders SET status`).
+		WithArgs("paid", "o1", "pending").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if err := repo.UpdateOrderStatus(context.Background(), c); err != ErrStatusConflict {
+		t.Fatalf("expected ErrStatusConflict, got %v", err)
+	}
+}
+
+func TestRepoUnit_PutSaga(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	s := Saga{
+		ID:        "s1",
+		AccountID: "a1",
+		Status:    SagaPending,
+		Steps:     []SagaStep{{Action: StepUpdateStock, ProductIDs: []string{"p1"}, Deltas: []int32{-2}}},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	mock.ExpectExec(`INSERT INTO order_sagas`).
+		WithArgs(s.ID, s.AccountID, "pending", []byte(`[{"action":"update_stock","productIds":["p1"],"deltas":[-2]}]`), s.CreatedAt, s.UpdatedAt, "pending", "compensating").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.PutSaga(context.Background(), s); err != nil {
+		t.Fatal(err)
+	}
+
+	// the stored saga was settled, so the upsert changes nothing
+	mock.ExpectExec(`INSERT INTO order_sagas`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.PutSaga(context.Background(), s); !errors.Is(err, ErrSagaNotPending) {
+		t.Fatalf("expected ErrSagaNotPending, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRepoUnit_ListPendingSagas(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	cutoff := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "account_id", "status", "steps", "created_at", "updated_at"}).
+		AddRow("s1", "a1", "pending", []byte(`[{"action":"update_stock","productIds":["p1"],"deltas":[-2]}]`), time.Now(), time.Now())
+
+	mock.ExpectQuery(`FROM order_sagas`).
+		WithArgs("pending", "compensating", cutoff).
+		WillReturnRows(rows)
+
+	sagas, err := repo.ListPendingSagas(context.Background(), cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sagas) != 1 || len(sagas[0].Steps) != 1 || sagas[0].Steps[0].Deltas[0] != -2 {
+		t.Fatalf("unexpected sagas: %+v", sagas)
+	}
+}
+
+func TestRepoUnit_ClaimRestock(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE orders SET restocked_at`).
+		WithArgs(sqlmock.AnyArg(), "o1", "cancelled").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM orders_products`).
+		WithArgs("o1").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "quantity"}).
+			AddRow("p1", int64(2)).
+			AddRow("p2", int64(1)))
+	mock.ExpectCommit()
+
+	products, err := repo.ClaimRestock(context.Background(), "o1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 2 || products[0].ID != "p1" || products[0].Quantity != 2 {
+		t.Fatalf("unexpected products: %+v", products)
+	}
+
+	// A second claim finds the order already restocked.
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE orders SET restocked_at`).
+		WithArgs(sqlmock.AnyArg(), "o1", "cancelled").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	products, err = repo.ClaimRestock(context.Background(), "o1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 0 {
+		t.Fatalf("expected no products on second claim, got %+v", products)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRepoUnit_PutOrder_IdempotencyKeyTakenOver(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	o := Order{
+		ID: "o1", Status: StatusPending, Products: []OrderedProduct{{ID: "p1", Quantity: 1}},
+	}
+
+	mock.ExpectBegin()
+
+	mock.ExpectExec(`INSERT INTO orders`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(`INSERT INTO order_status_history`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(`UPDATE order_idempotency_keys SET order_id`).
+		WithArgs(o.ID, "k1", "t1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectRollback()
+
+	err := repo.PutOrder(context.Background(), o, &IdempotencyClaim{Key: "k1", Token: "t1"})
+	if !errors.Is(err, ErrIdempotencyKeyInUse) {
+		t.Fatalf("expected ErrIdempotencyKeyInUse, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRepoUnit_PutOrder_SagaCompensated(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	o := Order{
+		ID: "s1", Status: StatusPending, Products: []OrderedProduct{{ID: "p1", Quantity: 1}},
+	}
+
+	mock.ExpectBegin()
+
+	mock.ExpectExec(`INSERT INTO orders`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(`INSERT INTO order_status_history`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(`UPDATE order_sagas SET status`).
+		WithArgs("completed", o.CreatedAt, o.ID, "pending").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(o.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	mock.ExpectRollback()
+
+	err := repo.PutOrder(context.Background(), o, nil)
+	if !errors.Is(err, ErrSagaNotPending) {
+		t.Fatalf("expected ErrSagaNotPending, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRepoUnit_ListOrders(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	minTotal := money.New(1000, "USD")
+	filter := OrderFilter{
+		AccountID:    "a1",
+		CreatedAfter: after,
+		Statuses:     []OrderStatus{StatusPaid, StatusShipped},
+		MinTotal:     &minTotal,
+	}
+
+	rows := sqlmock.NewRows([]string{
+		"id", "created_at", "account_id", "total_amount", "currency", "status", "product_id", "quantity", "name", "description", "price",
+	}).
+		AddRow("o2", time.Now(), "a1", int64(2000), "USD", "paid", "p1", int64(1), "prod 1", "desc 1", int64(2000)).
+		AddRow("o1", time.Now(), "a1", int64(5000), "USD", "shipped", "p2", int64(1), "prod 2", "desc 2", int64(5000))
+
+	mock.ExpectQuery(`o\.id IN \(SELECT id FROM orders WHERE id < \$1 AND account_id = \$2 AND created_at >= \$3 AND status = ANY\(\$4\) AND currency = \$5 AND total_amount >= \$6 ORDER BY id DESC LIMIT \$7\)\s+ORDER BY o\.id DESC`).
+		WithArgs("o3", "a1", after, sqlmock.AnyArg(), "USD", int64(1000), 3).
+		WillReturnRows(rows)
+
+	orders, err := repo.ListOrders(context.Background(), filter, "o3", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 2 || orders[0].ID != "o2" || orders[1].ID != "o1" {
+		t.Fatalf("expected o2 then o1, got %+v", orders)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRepoUnit_GetOrdersForAccounts(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	rows := sqlmock.NewRows([]string{
+		"id", "created_at", "account_id", "total_amount", "currency", "status", "product_id", "quantity", "name", "description", "price",
+	}).
+		AddRow("o1", time.Now(), "a1", int64(2000), "USD", "paid", "p1", int64(1), "prod 1", "desc 1", int64(2000)).
+		AddRow("o2", time.Now(), "a2", int64(1000), "USD", "pending", "p2", int64(1), "prod 2", "desc 2", int64(1000))
+
+	mock.ExpectQuery(`WHERE o\.account_id = ANY\(\$1\)`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(rows)
+
+	orders, err := repo.GetOrdersForAccounts(context.Background(), []string{"a1", "a2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 2 || orders[0].AccountID != "a1" || orders[1].AccountID != "a2" {
+		t.Fatalf("unexpected orders %+v", orders)
+	}
 }
@@ -0,0 +1,220 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
+	"github.com/segmentio/ksuid"
+)
+
+type SagaStatus string
+
+const (
+	SagaPending SagaStatus = "pending"
+	// SagaCompensating is set before the steps of a saga are undone, so that
+	// its order can no longer be placed.
+	SagaCompensating SagaStatus = "compensating"
+	SagaCompleted    SagaStatus = "completed"
+	SagaCompensated  SagaStatus = "compensated"
+)
+
+// ErrSagaNotPending is returned for changes a saga no longer accepts: its
+// order can only be placed while it is pending, and it can't go back to
+// pending once compensation began, nor change at all once settled.
+var ErrSagaNotPending = errors.New("order saga is no longer pending")
+
+// canBecome reports whether a saga in status from may be saved with status to.
+func (from SagaStatus) canBecome(to SagaStatus) bool {
+	return from == SagaPending || from == SagaCompensating && to != SagaPending
+}
+
+// StepUpdateStock is recorded before inventory is asked to apply the stock
+// deltas of an order, under the idempotency key of the step.
+const StepUpdateStock = "update_stock"
+
+const (
+	// sagaRecoveryAge is how long a saga must be idle before recovery treats it as abandoned.
+	sagaRecoveryAge = time.Minute
+	// sagaRecoveryInterval is how often pending sagas are checked for compensation.
+	sagaRecoveryInterval = time.Minute
+	// compensationTimeout bounds each compensation independently of the request deadline.
+	compensationTimeout = 5 * time.Second
+)
+
+type SagaStep struct {
+	Action string `json:"action"`
+	// Key is the idempotency key the step is taken with. It is empty for
+	// steps logged by older versions, which were logged once taken.
+	Key        string   `json:"key,omitempty"`
+	ProductIDs []string `json:"productIds"`
+	Deltas     []int32  `json:"deltas"`
+}
+
+type Saga struct {
+	ID        string
+	AccountID string
+	Status    SagaStatus
+	Steps     []SagaStep
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SagaLog persists sagas so that an order interrupted by a crash can be
+// compensated when the service comes back up.
+type SagaLog interface {
+	// PutSaga stores s, failing with ErrSagaNotPending if the stored saga
+	// can't become s.Status.
+	PutSaga(ctx context.Context, s Saga) error
+	// ListPendingSagas also lists the sagas whose compensation was
+	// interrupted.
+	ListPendingSagas(ctx context.Context, updatedBefore time.Time) ([]Saga, error)
+}
+
+type sagaCoordinator struct {
+	log             SagaLog
+	inventoryClient *inventory.Client
+}
+
+func newSagaCoordinator(l SagaLog, inventoryClient *inventory.Client) *sagaCoordinator {
+	return &sagaCoordinator{log: l, inventoryClient: inventoryClient}
+}
+
+func (c *sagaCoordinator) begin(ctx context.Context, accountID string) (*Saga, error) {
+	now := time.Now().UTC()
+	s := &Saga{
+		ID:        ksuid.New().String(),
+		AccountID: accountID,
+		Status:    SagaPending,
+		Steps:     []SagaStep{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := c.log.PutSaga(ctx, *s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// step returns the step of s for action, keyed by the saga and the action.
+func (s *Saga) step(action string, productIDs []string, deltas []int32) SagaStep {
+	return SagaStep{Action: action, Key: "saga:" + s.ID + ":" + action, ProductIDs: productIDs, Deltas: deltas}
+}
+
+// record appends step before it is taken, so that a crash while it runs
+// leaves it for recovery to compensate. The step is kept only once it is
+// logged; it must not be taken otherwise.
+func (c *sagaCoordinator) record(ctx context.Context, s *Saga, step SagaStep) error {
+	s.Steps = append(s.Steps, step)
+	if err := c.save(ctx, s, SagaPending); err != nil {
+		s.Steps = s.Steps[:len(s.Steps)-1]
+		return err
+	}
+	return nil
+}
+
+// compensate undoes the recorded steps in reverse order. The saga is marked
+// compensating first, which fails if its order was placed in the meantime.
+// If any inverse action fails the saga is retried by recovery.
+func (c *sagaCoordinator) compensate(ctx context.Context, s *Saga) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+	defer cancel()
+
+	if err := c.save(ctx, s, SagaCompensating); err != nil {
+		if errors.Is(err, ErrSagaNotPending) {
+			log.Printf("saga %s: settled elsewhere, nothing to compensate", s.ID)
+			return nil
+		}
+		return err
+	}
+	for i := len(s.Steps) - 1; i >= 0; i-- {
+		if err := c.undo(ctx, fmt.Sprintf("saga:%s:undo:%d", s.ID, i), s.Steps[i]); err != nil {
+			log.Printf("saga %s: compensating %s failed: %v", s.ID, s.Steps[i].Action, err)
+			return err
+		}
+		s.Steps = s.Steps[:i]
+		if err := c.save(ctx, s, SagaCompensating); err != nil {
+			return err
+		}
+	}
+
+	return c.save(ctx, s, SagaCompensated)
+}
+
+// undo applies the inverse of step. key makes a retried undo of the same
+// step, e.g. by recovery, a no-op.
+func (c *sagaCoordinator) undo(ctx context.Context, key string, step SagaStep) error {
+	switch step.Action {
+	case StepUpdateStock:
+		// A step is logged before it is taken, so it may never have reached
+		// inventory. Repeating it under its key settles that: inventory
+		// replays the first outcome if the key was used, and applies the
+		// deltas now otherwise. Out of stock means nothing was taken.
+		if step.Key != "" {
+			outOfStock, err := c.inventoryClient.UpdateStock(ctx, step.ProductIDs, step.Deltas, step.Key)
+			if err != nil {
+				return err
+			}
+			if len(outOfStock) != 0 {
+				return nil
+			}
+		}
+		deltas := make([]int32, len(step.Deltas))
+		for i, d := range step.Deltas {
+			deltas[i] = -d
+		}
+		_, err := c.inventoryClient.UpdateStock(ctx, step.ProductIDs, deltas, key)
+		return err
+	default:
+		log.Printf("saga: no compensation for step %q", step.Action)
+		return nil
+	}
+}
+
+func (c *sagaCoordinator) save(ctx context.Context, s *Saga, status SagaStatus) error {
+	s.Status = status
+	s.UpdatedAt = time.Now().UTC()
+	return c.log.PutSaga(ctx, *s)
+}
+
+// recover compensates every pending saga that has been idle for longer than
+// sagaRecoveryAge, and finishes compensations that were interrupted. A
+// request may still be in flight past that age; it then fails to place its
+// order, as compensation marks the saga first. Sagas that placed an order
+// are completed along with it, so they aren't pending.
+func (c *sagaCoordinator) recover(ctx context.Context) error {
+	sagas, err := c.log.ListPendingSagas(ctx, time.Now().UTC().Add(-sagaRecoveryAge))
+	if err != nil {
+		return err
+	}
+
+	for i := range sagas {
+		s := &sagas[i]
+		log.Printf("saga %s: compensating %d step(s) left by an interrupted order", s.ID, len(s.Steps))
+		if err := c.compensate(ctx, s); err != nil {
+			log.Printf("saga %s: recovery failed: %v", s.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *sagaCoordinator) runRecovery(ctx context.Context) {
+	ticker := time.NewTicker(sagaRecoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.recover(ctx); err != nil {
+			log.Println("saga recovery:", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
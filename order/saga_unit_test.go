@@ -0,0 +1,184 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
+	inventorypb "github.com/RathodViraj/go-microservice-graphql-grpc/inventory/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUnitSaga_RecoverCompensatesPendingSagas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	invAddr, invFake, stopInv := startRecordingInventoryServer(t)
+	defer stopInv()
+	invClient, err := inventory.NewClient(invAddr)
+	if err != nil {
+		t.Fatalf("failed to create inventory client: %v", err)
+	}
+	defer invClient.Close()
+
+	pending := Saga{
+		ID:        "s1",
+		AccountID: "a1",
+		Status:    SagaPending,
+		Steps: []SagaStep{
+			{Action: StepUpdateStock, ProductIDs: []string{"p1", "p2"}, Deltas: []int32{-3, -1}},
+		},
+		CreatedAt: time.Now().Add(-time.Hour),
+	}
+
+	mockRepo := NewMockRepository(ctrl)
+	mockRepo.EXPECT().ListPendingSagas(gomock.Any(), gomock.Any()).Return([]Saga{pending}, nil)
+
+	var last Saga
+	mockRepo.EXPECT().PutSaga(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s Saga) error {
+		last = s
+		return nil
+	}).AnyTimes()
+
+	if err := newSagaCoordinator(mockRepo, invClient).recover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	updates := invFake.Updates()
+	if len(updates) != 1 {
+		t.Fatalf("expected 1 compensating update, got %d", len(updates))
+	}
+	if updates[0].Deltas[0] != 3 || updates[0].Deltas[1] != 1 {
+		t.Errorf("expected stock to be re-credited, got %v", updates[0].Deltas)
+	}
+	if last.ID != "s1" || last.Status != SagaCompensated {
+		t.Errorf("expected saga s1 to be compensated, got %+v", last)
+	}
+}
+
+// stockTaken sums the deltas of updates by product, negated.
+func stockTaken(updates []*inventorypb.UpdateStockRequest) map[string]int32 {
+	taken := map[string]int32{}
+	for _, u := range updates {
+		for i, pid := range u.Pids {
+			taken[pid] -= u.Deltas[i]
+		}
+	}
+	return taken
+}
+
+func TestUnitSaga_RecoverSettlesStepsThatMayNotHaveRun(t *testing.T) {
+	invAddr, invFake, stopInv := startRecordingInventoryServer(t)
+	defer stopInv()
+	invClient, err := inventory.NewClient(invAddr)
+	if err != nil {
+		t.Fatalf("failed to create inventory client: %v", err)
+	}
+	defer invClient.Close()
+
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	idle := time.Now().UTC().Add(-time.Hour)
+	// s1 crashed after its step reached inventory, s2 right after logging it.
+	for _, id := range []string{"s1", "s2"} {
+		s := Saga{ID: id, AccountID: "a1", Status: SagaPending, CreatedAt: idle, UpdatedAt: idle}
+		s.Steps = []SagaStep{s.step(StepUpdateStock, []string{"p1"}, []int32{-2})}
+		if err := repo.PutSaga(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+		if id == "s1" {
+			if _, err := invClient.UpdateStock(ctx, s.Steps[0].ProductIDs, s.Steps[0].Deltas, s.Steps[0].Key); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := newSagaCoordinator(repo, invClient).recover(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if taken := stockTaken(invFake.Updates()); taken["p1"] != 0 {
+		t.Errorf("expected all stock to be given back, %d units are still taken", taken["p1"])
+	}
+	if pending, _ := repo.ListPendingSagas(ctx, time.Now().UTC()); len(pending) != 0 {
+		t.Errorf("expected both sagas to be compensated, %d are pending", len(pending))
+	}
+}
+
+func TestUnitSaga_RecoverSkipsSagasThatPlacedAnOrder(t *testing.T) {
+	invAddr, invFake, stopInv := startRecordingInventoryServer(t)
+	defer stopInv()
+	invClient, err := inventory.NewClient(invAddr)
+	if err != nil {
+		t.Fatalf("failed to create inventory client: %v", err)
+	}
+	defer invClient.Close()
+
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	idle := time.Now().UTC().Add(-time.Hour)
+	s := Saga{ID: "s1", AccountID: "a1", Status: SagaPending, CreatedAt: idle, UpdatedAt: idle}
+	s.Steps = []SagaStep{s.step(StepUpdateStock, []string{"p1"}, []int32{-2})}
+	if err := repo.PutSaga(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := invClient.UpdateStock(ctx, s.Steps[0].ProductIDs, s.Steps[0].Deltas, s.Steps[0].Key); err != nil {
+		t.Fatal(err)
+	}
+
+	// The process stops right after the order is stored.
+	o, err := NewOrderService(repo).PostOrder(ctx, "a1", []OrderedProduct{{ID: "p1", Price: money.New(500, "USD"), Quantity: 2}}, nil, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.ID != s.ID {
+		t.Errorf("expected the order to take the ID of its saga, got %s", o.ID)
+	}
+
+	if err := newSagaCoordinator(repo, invClient).recover(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if taken := stockTaken(invFake.Updates()); taken["p1"] != 2 {
+		t.Errorf("expected the order to keep its stock, %d units are taken", taken["p1"])
+	}
+}
+
+func TestUnitSaga_OrderIsRefusedOnceRecoveryCompensated(t *testing.T) {
+	invAddr, invFake, stopInv := startRecordingInventoryServer(t)
+	defer stopInv()
+	invClient, err := inventory.NewClient(invAddr)
+	if err != nil {
+		t.Fatalf("failed to create inventory client: %v", err)
+	}
+	defer invClient.Close()
+
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	idle := time.Now().UTC().Add(-time.Hour)
+	s := Saga{ID: "s1", AccountID: "a1", Status: SagaPending, CreatedAt: idle, UpdatedAt: idle}
+	s.Steps = []SagaStep{s.step(StepUpdateStock, []string{"p1"}, []int32{-2})}
+	if err := repo.PutSaga(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := invClient.UpdateStock(ctx, s.Steps[0].ProductIDs, s.Steps[0].Deltas, s.Steps[0].Key); err != nil {
+		t.Fatal(err)
+	}
+
+	// The request is still running, past the recovery age.
+	if err := newSagaCoordinator(repo, invClient).recover(ctx); err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewOrderService(repo).PostOrder(ctx, "a1", []OrderedProduct{{ID: "p1", Price: money.New(500, "USD"), Quantity: 2}}, nil, s.ID)
+	if !errors.Is(err, ErrSagaNotPending) {
+		t.Fatalf("expected ErrSagaNotPending, got %v", err)
+	}
+	if _, err := repo.GetOrder(ctx, s.ID); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("expected no order without its stock, got %v", err)
+	}
+	if taken := stockTaken(invFake.Updates()); taken["p1"] != 0 {
+		t.Errorf("expected all stock to be given back, %d units are still taken", taken["p1"])
+	}
+}
@@ -5,14 +5,25 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"net"
+	"sync"
 
 	"github.com/RathodViraj/go-microservice-graphql-grpc/account"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/errcode"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/health"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/idempotency"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/metrics"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/middleware"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/order/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/serve"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/tracing"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type grpcServer struct {
@@ -21,43 +32,162 @@ type grpcServer struct {
 	accountClient   *account.Client
 	catalogClient   *catalog.Client
 	inventoryClient *inventory.Client
+	sagas           *sagaCoordinator
 }
 
-func ListenGRPC(s Service, accountURL, catalogURL, inventoryURL string, port int) error {
-	accountClient, err := account.NewClient(accountURL)
+// methodRoles are the roles required by the order methods. Every method
+// needs an authenticated caller, and those acting on an account's orders
+// also check the caller owns them.
+var methodRoles = map[string]auth.Role{
+	pb.OrderService_PostOrder_FullMethodName:            auth.RoleCustomer,
+	pb.OrderService_GetOrder_FullMethodName:             auth.RoleCustomer,
+	pb.OrderService_ListOrders_FullMethodName:           auth.RoleCustomer,
+	pb.OrderService_GetOrdersForAccount_FullMethodName:  auth.RoleCustomer,
+	pb.OrderService_GetOrdersForAccounts_FullMethodName: auth.RoleCustomer,
+	pb.OrderService_CancelOrder_FullMethodName:          auth.RoleCustomer,
+	pb.OrderService_UpdateOrderStatus_FullMethodName:    auth.RoleMerchant,
+}
+
+var errorCodes = errcode.Codes{
+	ErrOrderNotFound:          codes.NotFound,
+	ErrInvalidStatus:          codes.InvalidArgument,
+	ErrInvalidCursor:          codes.InvalidArgument,
+	ErrInvalidIdempotencyKey:  codes.InvalidArgument,
+	ErrIdempotencyKeyReused:   codes.InvalidArgument,
+	money.ErrInvalidAmount:    codes.InvalidArgument,
+	money.ErrInvalidCurrency:  codes.InvalidArgument,
+	money.ErrCurrencyMismatch: codes.InvalidArgument,
+	ErrInvalidTransition:      codes.FailedPrecondition,
+	ErrNotCancellable:         codes.FailedPrecondition,
+	ErrStatusConflict:         codes.Aborted,
+	ErrIdempotencyKeyInUse:    codes.Aborted,
+	ErrSagaNotPending:         codes.Aborted,
+}
+
+// DefaultPort is the port ListenGRPC listens on unless told otherwise.
+const DefaultPort = 8083
+
+// ListenGRPC serves s until ctx is done, then drains the calls in flight.
+// tokens verifies the access tokens callers authenticate with, and signs
+// the service tokens the order service calls the other services with.
+func ListenGRPC(ctx context.Context, s Service, tokens *auth.Signer, sagaLog SagaLog, accountURL, catalogURL, inventoryURL string, opts ...serve.Option) error {
+	cfg := serve.NewConfig(DefaultPort, opts...)
+	dialOpts := append([]grpc.DialOption{grpc.WithPerRPCCredentials(auth.ServiceCredentials(tokens, "order"))}, cfg.DialOptions...)
+	accountClient, err := account.NewClient(accountURL, dialOpts...)
 	if err != nil {
 		return err
 	}
-	catalogClient, err := catalog.NewClient(catalogURL)
+	defer accountClient.Close()
+	catalogClient, err := catalog.NewClient(catalogURL, dialOpts...)
 	if err != nil {
-		accountClient.Close()
 		return err
 	}
-	inventroryClient, err := inventory.NewClient(inventoryURL)
+	defer catalogClient.Close()
+	inventroryClient, err := inventory.NewClient(inventoryURL, dialOpts...)
 	if err != nil {
-		accountClient.Close()
 		return err
 	}
+	defer inventroryClient.Close()
 
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	lis, err := cfg.Listen()
 	if err != nil {
-		accountClient.Close()
-		catalogClient.Close()
 		return err
 	}
-	srv := grpc.NewServer()
-	pb.RegisterOrderServiceServer(srv, &grpcServer{service: s, accountClient: accountClient, catalogClient: catalogClient, inventoryClient: inventroryClient})
+
+	// The background jobs stop before the clients and the caller's
+	// repository are closed.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sagas := newSagaCoordinator(sagaLog, inventroryClient)
+	wg.Go(func() { sagas.runRecovery(ctx) })
+	wg.Go(func() { runIdempotencyPurge(ctx, s) })
+
+	srv := grpc.NewServer(append([]grpc.ServerOption{
+		tracing.ServerOption(),
+		metrics.ServerOption(),
+		middleware.ServerOption(),
+		grpc.ChainUnaryInterceptor(
+			errcode.UnaryServerInterceptor(errorCodes),
+			auth.UnaryServerInterceptor(tokens, methodRoles),
+		),
+	}, cfg.ServerOptions...)...)
+	pb.RegisterOrderServiceServer(srv, &grpcServer{service: s, accountClient: accountClient, catalogClient: catalogClient, inventoryClient: inventroryClient, sagas: sagas})
+	health.Serve(ctx, srv, map[string]health.Check{
+		"postgres":  s.Ping,
+		"account":   accountClient.Ping,
+		"catalog":   catalogClient.Ping,
+		"inventory": inventroryClient.Ping,
+	})
 	reflection.Register(srv)
-	return srv.Serve(lis)
+	return serve.GRPC(ctx, srv, lis, cfg.DrainTimeout)
 }
 
-func (s *grpcServer) PostOrder(ctx context.Context, r *pb.PostOrderRequest) (*pb.PostOrderResponse, error) {
-	_, err := s.accountClient.GetAccount(ctx, r.AccountId)
+// checkOwner fails unless the caller may act on the orders of accountID.
+// methodRoles makes sure every call carries an identity by the time it
+// gets here.
+func checkOwner(ctx context.Context, accountID string) error {
+	if id, ok := auth.FromContext(ctx); ok && !id.Owns(accountID) {
+		return status.Error(codes.PermissionDenied, "not allowed to access the orders of this account")
+	}
+	return nil
+}
+
+func (s *grpcServer) PostOrder(ctx context.Context, r *pb.PostOrderRequest) (res *pb.PostOrderResponse, err error) {
+	if err := checkOwner(ctx, r.AccountId); err != nil {
+		return nil, err
+	}
+	_, err = s.accountClient.GetAccount(ctx, r.AccountId)
 	if err != nil {
 		log.Println("Error getting account:", err)
-		return nil, errors.New("account not found")
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.NotFound, "account not found")
+		}
+		return nil, err
 	}
 
+	var claim *IdempotencyClaim
+	if key := idempotency.KeyFromContext(ctx, r.IdempotencyKey); key != "" {
+		var orderID string
+		claim, orderID, err = s.service.ClaimIdempotencyKey(ctx, key, postOrderFingerprint(r))
+		if err != nil {
+			log.Println("error claiming idempotency key: ", err)
+			return nil, err
+		}
+		if orderID != "" {
+			order, err := s.service.GetOrder(ctx, orderID)
+			if err != nil {
+				log.Println("error loading replayed order: ", err)
+				return nil, errors.New("could not post order")
+			}
+			return &pb.PostOrderResponse{Order: orderToProto(order)}, nil
+		}
+		// Let a retry in right away if this attempt fails.
+		defer func() {
+			if err != nil {
+				if rerr := s.service.ReleaseIdempotencyKey(context.WithoutCancel(ctx), *claim); rerr != nil {
+					log.Printf("failed to release idempotency key %q: %v", claim.Key, rerr)
+				}
+			}
+		}()
+	}
+
+	saga, err := s.sagas.begin(ctx, r.AccountId)
+	if err != nil {
+		log.Println("error starting order saga: ", err)
+		return nil, errors.New("could not post order")
+	}
+	// Every failure from here on must undo the steps already recorded. The
+	// saga is completed along with the order it places.
+	defer func() {
+		if err != nil {
+			if cerr := s.sagas.compensate(ctx, saga); cerr != nil {
+				log.Printf("saga %s left for recovery: %v", saga.ID, cerr)
+			}
+		}
+	}()
+
 	productIDs := []string{}
 	Quantities := []int32{}
 	for _, prd := range r.Products {
@@ -65,22 +195,31 @@ func (s *grpcServer) PostOrder(ctx context.Context, r *pb.PostOrderRequest) (*pb
 		Quantities = append(Quantities, -1*int32(prd.Quantity))
 	}
 
-	outOfStockProducts, err := s.inventoryClient.UpdateStock(ctx, productIDs, Quantities)
+	step := saga.step(StepUpdateStock, productIDs, Quantities)
+	if err := s.sagas.record(ctx, saga, step); err != nil {
+		log.Printf("saga %s: failed to record stock update: %v", saga.ID, err)
+		return nil, errors.New("could not post order")
+	}
+	outOfStockProducts, err := s.inventoryClient.UpdateStock(ctx, step.ProductIDs, step.Deltas, step.Key)
 	if err != nil {
 		log.Println("error checking stock: ", err)
-		return nil, errors.New("failed to update stocks")
+		return nil, err
 	}
 	if len(outOfStockProducts) != 0 {
-		return nil, fmt.Errorf("this product(s) out of stock: %v", outOfStockProducts)
+		outOfStockRejections.Inc()
+		return nil, errcode.OutOfStock(outOfStockProducts)
 	}
 
 	orderedProducts, err := s.catalogClient.GetProducts(ctx, 0, 0, productIDs, "")
 	if err != nil {
 		log.Println("Error getting products: ", err)
-		return nil, errors.New("products not found")
+		return nil, err
 	}
 	products := []OrderedProduct{}
 	for _, p := range orderedProducts {
+		if p.Product.Deleted {
+			return nil, status.Errorf(codes.NotFound, "product %s is no longer sold", p.Product.ID)
+		}
 		product := OrderedProduct{
 			ID:          p.Product.ID,
 			Quantity:    0,
@@ -100,91 +239,277 @@ func (s *grpcServer) PostOrder(ctx context.Context, r *pb.PostOrderRequest) (*pb
 	}
 
 	if len(products) == 0 {
-		return nil, errors.New("products not found")
+		return nil, status.Error(codes.NotFound, "products not found")
 	}
 
-	order, err := s.service.PostOrder(ctx, r.AccountId, products)
+	order, err := s.service.PostOrder(ctx, r.AccountId, products, claim, saga.ID)
 	if err != nil {
 		log.Println("errors posting err: ", err)
-		return nil, errors.New("could not post order")
+		return nil, fmt.Errorf("could not post order: %w", err)
 	}
+	recordOrderPlaced(order.TotalPrice)
 
-	orderProto := &pb.Order{
-		Id:         order.ID,
-		AccountId:  order.AccountID,
-		TotalPrice: order.TotalPrice,
-		Products:   []*pb.Order_OrderProduct{},
+	return &pb.PostOrderResponse{
+		Order: orderToProto(order),
+	}, nil
+}
+
+// postOrderFingerprint identifies what r asks for, independent of the
+// order its products are listed in.
+func postOrderFingerprint(r *pb.PostOrderRequest) string {
+	quantities := map[string]uint32{}
+	for _, p := range r.Products {
+		quantities[p.ProductId] += p.Quantity
 	}
+	return idempotency.Fingerprint(r.AccountId, quantities)
+}
 
-	orderProto.CreatedAt, _ = order.CreatedAt.MarshalBinary()
-	for _, p := range order.Products {
-		orderProto.Products = append(orderProto.Products, &pb.Order_OrderProduct{
+func orderToProto(o *Order) *pb.Order {
+	op := &pb.Order{
+		Id:         o.ID,
+		AccountId:  o.AccountID,
+		TotalPrice: o.TotalPrice.Proto(),
+		Status:     string(o.Status),
+		Products:   []*pb.Order_OrderProduct{},
+	}
+	op.CreatedAt, _ = o.CreatedAt.MarshalBinary()
+	for _, p := range o.Products {
+		op.Products = append(op.Products, &pb.Order_OrderProduct{
 			Id:          p.ID,
 			Name:        p.Name,
 			Description: p.Description,
-			Price:       p.Price,
+			Price:       p.Price.Proto(),
 			Quantity:    p.Quantity,
 		})
 	}
 
-	return &pb.PostOrderResponse{
-		Order: orderProto,
-	}, nil
+	return op
+}
+
+func (s *grpcServer) GetOrder(ctx context.Context, r *pb.GetOrderRequest) (*pb.GetOrderResponse, error) {
+	o, err := s.service.GetOrder(ctx, r.Id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if err := checkOwner(ctx, o.AccountID); err != nil {
+		return nil, err
+	}
+
+	orders := []Order{*o}
+	s.fillFromCatalog(ctx, orders)
+
+	return &pb.GetOrderResponse{Order: orderToProto(&orders[0])}, nil
+}
+
+func (s *grpcServer) ListOrders(ctx context.Context, r *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	filter := OrderFilter{AccountID: r.AccountId}
+	// Only admins may list the orders of every account.
+	if id, ok := auth.FromContext(ctx); ok && filter.AccountID == "" && !id.Role.Includes(auth.RoleAdmin) {
+		filter.AccountID = id.AccountID
+	}
+	if err := checkOwner(ctx, filter.AccountID); err != nil {
+		return nil, err
+	}
+	if len(r.CreatedAfter) != 0 {
+		if err := filter.CreatedAfter.UnmarshalBinary(r.CreatedAfter); err != nil {
+			return nil, err
+		}
+	}
+	if len(r.CreatedBefore) != 0 {
+		if err := filter.CreatedBefore.UnmarshalBinary(r.CreatedBefore); err != nil {
+			return nil, err
+		}
+	}
+	for _, st := range r.Statuses {
+		filter.Statuses = append(filter.Statuses, OrderStatus(st))
+	}
+	if r.MinTotal != nil {
+		minTotal := money.FromProto(r.MinTotal)
+		filter.MinTotal = &minTotal
+	}
+
+	page, err := s.service.ListOrders(ctx, filter, int(r.First), r.After)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	s.fillFromCatalog(ctx, page.Orders)
+
+	res := &pb.ListOrdersResponse{
+		Orders:      []*pb.Order{},
+		EndCursor:   page.EndCursor,
+		HasNextPage: page.HasNextPage,
+	}
+	for i := range page.Orders {
+		res.Orders = append(res.Orders, orderToProto(&page.Orders[i]))
+	}
+
+	return res, nil
 }
 
 func (s *grpcServer) GetOrdersForAccount(ctx context.Context, r *pb.GetOrdersForAccountRequest) (*pb.GetOrdersForAccountResponse, error) {
+	if err := checkOwner(ctx, r.AccountId); err != nil {
+		return nil, err
+	}
 	accountOrds, err := s.service.GetOrderForAccount(ctx, r.AccountId)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
+	s.fillFromCatalog(ctx, accountOrds)
 
+	orders := []*pb.Order{}
+	for i := range accountOrds {
+		orders = append(orders, orderToProto(&accountOrds[i]))
+	}
+
+	return &pb.GetOrdersForAccountResponse{Orders: orders}, nil
+}
+
+// GetOrdersForAccounts serves the orders of several accounts with one
+// database query and one catalog lookup.
+func (s *grpcServer) GetOrdersForAccounts(ctx context.Context, r *pb.GetOrdersForAccountsRequest) (*pb.GetOrdersForAccountsResponse, error) {
+	for _, id := range r.AccountIds {
+		if err := checkOwner(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	byAccount, err := s.service.GetOrdersForAccounts(ctx, r.AccountIds)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	all := []Order{}
+	for _, orders := range byAccount {
+		all = append(all, orders...)
+	}
+	// The copies share their lines with byAccount, so this completes both.
+	s.fillFromCatalog(ctx, all)
+
+	res := &pb.GetOrdersForAccountsResponse{Accounts: []*pb.AccountOrders{}}
+	for _, id := range r.AccountIds {
+		ao := &pb.AccountOrders{AccountId: id, Orders: []*pb.Order{}}
+		for i := range byAccount[id] {
+			ao.Orders = append(ao.Orders, orderToProto(&byAccount[id][i]))
+		}
+		res.Accounts = append(res.Accounts, ao)
+	}
+
+	return res, nil
+}
+
+// fillFromCatalog completes the lines of orders in place. Lines carry the
+// details stored when the order was placed. Only orders placed before that
+// are filled in from the catalog, and a catalog failure leaves them blank
+// rather than failing the request.
+func (s *grpcServer) fillFromCatalog(ctx context.Context, orders []Order) {
 	productIDmap := map[string]bool{}
-	for _, o := range accountOrds {
+	for _, o := range orders {
 		for _, p := range o.Products {
-			productIDmap[p.ID] = true
+			if !p.Snapshot {
+				productIDmap[p.ID] = true
+			}
 		}
 	}
+	if len(productIDmap) == 0 {
+		return
+	}
 
 	productIds := []string{}
 	for id := range productIDmap {
 		productIds = append(productIds, id)
 	}
 
-	peoducts, err := s.catalogClient.GetProducts(ctx, 0, 0, productIds, "")
+	res, err := s.catalogClient.GetProducts(ctx, 0, 0, productIds, "")
+	if err != nil {
+		log.Println("Error getting products: ", err)
+		return
+	}
+	catalogProducts := map[string]*catalog.Product{}
+	for _, p := range res {
+		catalogProducts[p.Product.ID] = p.Product
+	}
+
+	for _, o := range orders {
+		for i, product := range o.Products {
+			if p, ok := catalogProducts[product.ID]; ok && !product.Snapshot {
+				o.Products[i].Name = p.Name
+				o.Products[i].Description = p.Description
+				o.Products[i].Price = p.Price
+			}
+		}
+	}
+}
+
+func (s *grpcServer) UpdateOrderStatus(ctx context.Context, r *pb.UpdateOrderStatusRequest) (*pb.UpdateOrderStatusResponse, error) {
+	c, err := s.service.UpdateOrderStatus(ctx, r.Id, OrderStatus(r.Status))
 	if err != nil {
+		log.Println(err)
 		return nil, err
 	}
 
-	orders := []*pb.Order{}
-	for _, o := range accountOrds {
-		op := &pb.Order{
-			AccountId:  o.AccountID,
-			Id:         o.ID,
-			TotalPrice: o.TotalPrice,
-			Products:   []*pb.Order_OrderProduct{},
-		}
-		op.CreatedAt, _ = o.CreatedAt.MarshalBinary()
-
-		for _, product := range o.Products {
-			for _, p := range peoducts {
-				if p.Product.ID == product.ID {
-					product.Name = p.Product.Name
-					product.Description = p.Product.Description
-					product.Price = p.Product.Price
-					break
-				}
+	res := &pb.UpdateOrderStatusResponse{
+		Id:             c.OrderID,
+		PreviousStatus: string(c.From),
+		Status:         string(c.To),
+	}
+	res.ChangedAt, _ = c.ChangedAt.MarshalBinary()
+
+	return res, nil
+}
+
+// CancelOrder cancels an order and returns its quantities to inventory. The
+// restock is claimed in the order database first, so repeated cancellations
+// never credit the same stock twice.
+func (s *grpcServer) CancelOrder(ctx context.Context, r *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+	if id, ok := auth.FromContext(ctx); ok && !id.Role.Includes(auth.RoleAdmin) {
+		o, err := s.service.GetOrder(ctx, r.Id)
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		if err := checkOwner(ctx, o.AccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := s.service.CancelOrder(ctx, r.Id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	products, err := s.service.ClaimRestock(ctx, r.Id)
+	if err != nil {
+		log.Println("error claiming restock: ", err)
+		return nil, err
+	}
+
+	if len(products) != 0 {
+		productIDs := []string{}
+		deltas := []int32{}
+		for _, p := range products {
+			productIDs = append(productIDs, p.ID)
+			deltas = append(deltas, int32(p.Quantity))
+		}
+
+		if _, err := s.inventoryClient.UpdateStock(ctx, productIDs, deltas, "cancel:"+r.Id); err != nil {
+			log.Println("error restocking cancelled order: ", err)
+			if rerr := s.service.ReleaseRestock(context.WithoutCancel(ctx), r.Id); rerr != nil {
+				log.Printf("order %s: failed to release restock claim: %v", r.Id, rerr)
 			}
-			op.Products = append(op.Products, &pb.Order_OrderProduct{
-				Id:          product.ID,
-				Name:        product.Name,
-				Description: product.Description,
-				Price:       product.Price,
-				Quantity:    product.Quantity,
-			})
+			return nil, errors.New("failed to update stocks")
 		}
-		orders = append(orders, op)
 	}
 
-	return &pb.GetOrdersForAccountResponse{Orders: orders}, nil
+	res := &pb.CancelOrderResponse{
+		Id:             c.OrderID,
+		PreviousStatus: string(c.From),
+		Status:         string(c.To),
+	}
+	res.ChangedAt, _ = c.ChangedAt.MarshalBinary()
+
+	return res, nil
 }
@@ -42,6 +42,46 @@ func (m *MockOrderServiceClient) EXPECT() *MockOrderServiceClientMockRecorder {
 	return m.recorder
 }
 
+// CancelOrder mocks base method.
+func (m *MockOrderServiceClient) CancelOrder(ctx context.Context, in *pb.CancelOrderRequest, opts ...grpc.CallOption) (*pb.CancelOrderResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CancelOrder", varargs...)
+	ret0, _ := ret[0].(*pb.CancelOrderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockOrderServiceClientMockRecorder) CancelOrder(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockOrderServiceClient)(nil).CancelOrder), varargs...)
+}
+
+// GetOrder mocks base method.
+func (m *MockOrderServiceClient) GetOrder(ctx context.Context, in *pb.GetOrderRequest, opts ...grpc.CallOption) (*pb.GetOrderResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetOrder", varargs...)
+	ret0, _ := ret[0].(*pb.GetOrderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockOrderServiceClientMockRecorder) GetOrder(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderServiceClient)(nil).GetOrder), varargs...)
+}
+
 // GetOrdersForAccount mocks base method.
 func (m *MockOrderServiceClient) GetOrdersForAccount(ctx context.Context, in *pb.GetOrdersForAccountRequest, opts ...grpc.CallOption) (*pb.GetOrdersForAccountResponse, error) {
 	m.ctrl.T.Helper()
@@ -62,6 +102,46 @@ func (mr *MockOrderServiceClientMockRecorder) GetOrdersForAccount(ctx, in any, o
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersForAccount", reflect.TypeOf((*MockOrderServiceClient)(nil).GetOrdersForAccount), varargs...)
 }
 
+// GetOrdersForAccounts mocks base method.
+func (m *MockOrderServiceClient) GetOrdersForAccounts(ctx context.Context, in *pb.GetOrdersForAccountsRequest, opts ...grpc.CallOption) (*pb.GetOrdersForAccountsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetOrdersForAccounts", varargs...)
+	ret0, _ := ret[0].(*pb.GetOrdersForAccountsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersForAccounts indicates an expected call of GetOrdersForAccounts.
+func (mr *MockOrderServiceClientMockRecorder) GetOrdersForAccounts(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersForAccounts", reflect.TypeOf((*MockOrderServiceClient)(nil).GetOrdersForAccounts), varargs...)
+}
+
+// ListOrders mocks base method.
+func (m *MockOrderServiceClient) ListOrders(ctx context.Context, in *pb.ListOrdersRequest, opts ...grpc.CallOption) (*pb.ListOrdersResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListOrders", varargs...)
+	ret0, _ := ret[0].(*pb.ListOrdersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockOrderServiceClientMockRecorder) ListOrders(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockOrderServiceClient)(nil).ListOrders), varargs...)
+}
+
 // PostOrder mocks base method.
 func (m *MockOrderServiceClient) PostOrder(ctx context.Context, in *pb.PostOrderRequest, opts ...grpc.CallOption) (*pb.PostOrderResponse, error) {
 	m.ctrl.T.Helper()
@@ -81,3 +161,23 @@ func (mr *MockOrderServiceClientMockRecorder) PostOrder(ctx, in any, opts ...any
 	varargs := append([]any{ctx, in}, opts...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostOrder", reflect.TypeOf((*MockOrderServiceClient)(nil).PostOrder), varargs...)
 }
+
+// UpdateOrderStatus mocks base method.
+func (m *MockOrderServiceClient) UpdateOrderStatus(ctx context.Context, in *pb.UpdateOrderStatusRequest, opts ...grpc.CallOption) (*pb.UpdateOrderStatusResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateOrderStatus", varargs...)
+	ret0, _ := ret[0].(*pb.UpdateOrderStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOrderStatus indicates an expected call of UpdateOrderStatus.
+func (mr *MockOrderServiceClientMockRecorder) UpdateOrderStatus(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockOrderServiceClient)(nil).UpdateOrderStatus), varargs...)
+}
@@ -2,20 +2,32 @@ package order
 
 import (
 	"context"
+	"errors"
 	"net"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/idempotency"
+	moneypb "github.com/RathodViraj/go-microservice-graphql-grpc/money/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/resilience"
+
 	"github.com/RathodViraj/go-microservice-graphql-grpc/account"
 	accountpb "github.com/RathodViraj/go-microservice-graphql-grpc/account/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
 	catalogpb "github.com/RathodViraj/go-microservice-graphql-grpc/catalog/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/errcode"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
 	inventorypb "github.com/RathodViraj/go-microservice-graphql-grpc/inventory/pb"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"github.com/RathodViraj/go-microservice-graphql-grpc/order/pb"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -54,27 +66,69 @@ func startMockCatalogServer(t *testing.T, ctrl *gomock.Controller) (addr string,
 }
 
 func startFakeInventoryServer(t *testing.T) (addr string, stop func()) {
+	addr, _, stop = startRecordingInventoryServer(t)
+	return addr, stop
+}
+
+func startRecordingInventoryServer(t *testing.T) (addr string, fake *fakeInventoryServer, stop func()) {
 	t.Helper()
 	lis, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		t.Fatalf("failed to listen: %v", err)
 	}
+	fake = &fakeInventoryServer{}
 	srv := grpc.NewServer()
-	inventorypb.RegisterInventoryServiceServer(srv, &fakeInventoryServer{})
+	inventorypb.RegisterInventoryServiceServer(srv, fake)
 	go srv.Serve(lis)
 	stop = func() {
 		srv.Stop()
 		_ = lis.Close()
 	}
-	return lis.Addr().String(), stop
+	return lis.Addr().String(), fake, stop
 }
 
 type fakeInventoryServer struct {
 	inventorypb.UnimplementedInventoryServiceServer
+	mu sync.Mutex
+	// updates are the updates applied, in order.
+	updates []*inventorypb.UpdateStockRequest
+	// outOfStock is reported by every UpdateStock call.
+	outOfStock []string
+	// outcomes are the out of stock products of each idempotency key used,
+	// replayed instead of applying the update again like inventory does.
+	outcomes map[string][]string
 }
 
 func (s *fakeInventoryServer) UpdateStock(ctx context.Context, r *inventorypb.UpdateStockRequest) (*inventorypb.UpdateStockResponse, error) {
-	return &inventorypb.UpdateStockResponse{OutOfStock: []string{}}, nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if outOfStock, ok := s.outcomes[r.IdempotencyKey]; ok {
+		return &inventorypb.UpdateStockResponse{OutOfStock: outOfStock}, nil
+	}
+	outOfStock := append([]string{}, s.outOfStock...)
+	if len(outOfStock) == 0 {
+		s.updates = append(s.updates, r)
+	}
+	if r.IdempotencyKey != "" {
+		if s.outcomes == nil {
+			s.outcomes = map[string][]string{}
+		}
+		s.outcomes[r.IdempotencyKey] = outOfStock
+	}
+	return &inventorypb.UpdateStockResponse{OutOfStock: outOfStock}, nil
+}
+
+func (s *fakeInventoryServer) Updates() []*inventorypb.UpdateStockRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*inventorypb.UpdateStockRequest(nil), s.updates...)
+}
+
+// newTestSagas returns a coordinator whose log accepts every write.
+func newTestSagas(ctrl *gomock.Controller, invClient *inventory.Client) *sagaCoordinator {
+	repo := NewMockRepository(ctrl)
+	repo.EXPECT().PutSaga(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return newSagaCoordinator(repo, invClient)
 }
 
 func (s *fakeInventoryServer) CheckStock(ctx context.Context, r *inventorypb.CheckStockRequest) (*inventorypb.CheckStockResponse, error) {
@@ -123,7 +177,7 @@ func TestUnitServer_PostOrder_Success(t *testing.T) {
 				Id:          "p1",
 				Name:        "prod",
 				Description: "desc",
-				Price:       10,
+				Price:       &moneypb.Money{Amount: 1000, Currency: "USD"},
 			},
 			Quntity: 5,
 		}},
@@ -133,16 +187,16 @@ func TestUnitServer_PostOrder_Success(t *testing.T) {
 	defer ctrlService.Finish()
 	mockService := NewMockService(ctrlService)
 
-	expectedProducts := []OrderedProduct{{ID: "p1", Name: "prod", Description: "desc", Price: 10, Quantity: 2}}
-	mockService.EXPECT().PostOrder(gomock.Any(), "acc1", expectedProducts).Return(&Order{
+	expectedProducts := []OrderedProduct{{ID: "p1", Name: "prod", Description: "desc", Price: money.New(1000, "USD"), Quantity: 2}}
+	mockService.EXPECT().PostOrder(gomock.Any(), "acc1", expectedProducts, nil, gomock.Any()).Return(&Order{
 		ID:         "o1",
 		AccountID:  "acc1",
-		TotalPrice: 20,
+		TotalPrice: money.New(2000, "USD"),
 		Products:   expectedProducts,
 		CreatedAt:  time.Now(),
 	}, nil)
 
-	srv := grpcServer{service: mockService, accountClient: accountClient, catalogClient: catalogClient, inventoryClient: invClient}
+	srv := grpcServer{service: mockService, accountClient: accountClient, catalogClient: catalogClient, inventoryClient: invClient, sagas: newTestSagas(ctrl, invClient)}
 	req := &pb.PostOrderRequest{AccountId: "acc1", Products: []*pb.PostOrderRequest_OrderProduct{{ProductId: "p1", Quantity: 2}}}
 
 	resp, err := srv.PostOrder(context.Background(), req)
@@ -152,8 +206,8 @@ func TestUnitServer_PostOrder_Success(t *testing.T) {
 	if resp.GetOrder() == nil {
 		t.Fatalf("expected order in response")
 	}
-	if resp.GetOrder().TotalPrice != 20 {
-		t.Fatalf("expected total price 20, got %v", resp.GetOrder().TotalPrice)
+	if resp.GetOrder().TotalPrice.GetAmount() != 2000 {
+		t.Fatalf("expected total price 2000, got %v", resp.GetOrder().TotalPrice.GetAmount())
 	}
 	if len(resp.GetOrder().Products) != 1 || resp.GetOrder().Products[0].Quantity != 2 {
 		t.Fatalf("expected product quantity 2, got %#v", resp.GetOrder().Products)
@@ -194,11 +248,11 @@ func TestUnitServer_PostOrder_AccountNotFound(t *testing.T) {
 	defer ctrlService.Finish()
 	mockService := NewMockService(ctrlService)
 
-	srv := grpcServer{service: mockService, accountClient: accountClient, catalogClient: catalogClient, inventoryClient: invClient}
+	srv := grpcServer{service: mockService, accountClient: accountClient, catalogClient: catalogClient, inventoryClient: invClient, sagas: newTestSagas(ctrl, invClient)}
 	req := &pb.PostOrderRequest{AccountId: "missing", Products: []*pb.PostOrderRequest_OrderProduct{{ProductId: "p1", Quantity: 1}}}
 
 	_, err = srv.PostOrder(context.Background(), req)
-	if err == nil || err.Error() != "account not found" {
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound || st.Message() != "account not found" {
 		t.Fatalf("expected account not found error, got %v", err)
 	}
 
@@ -240,11 +294,541 @@ func TestUnitServer_PostOrder_ProductsNotFound(t *testing.T) {
 	defer ctrlService.Finish()
 	mockService := NewMockService(ctrlService)
 
-	srv := grpcServer{service: mockService, accountClient: accountClient, catalogClient: catalogClient, inventoryClient: invClient}
+	srv := grpcServer{service: mockService, accountClient: accountClient, catalogClient: catalogClient, inventoryClient: invClient, sagas: newTestSagas(ctrl, invClient)}
 	req := &pb.PostOrderRequest{AccountId: "acc1", Products: []*pb.PostOrderRequest_OrderProduct{{ProductId: "p1", Quantity: 1}}}
 
 	_, err = srv.PostOrder(context.Background(), req)
-	if err == nil || err.Error() != "products not found" {
+	if status.Code(err) != codes.NotFound {
 		t.Fatalf("expected products not found error, got %v", err)
 	}
 }
+
+func TestUnitServer_PostOrder_OutOfStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountAddr, accountMock, stopAccount := startMockAccountServer(t, ctrl)
+	defer stopAccount()
+	accountClient, err := account.NewClient(accountAddr)
+	if err != nil {
+		t.Fatalf("failed to create account client: %v", err)
+	}
+	defer accountClient.Close()
+
+	invAddr, invFake, stopInv := startRecordingInventoryServer(t)
+	defer stopInv()
+	invFake.outOfStock = []string{"p2"}
+	invClient, err := inventory.NewClient(invAddr)
+	if err != nil {
+		t.Fatalf("failed to create inventory client: %v", err)
+	}
+	defer invClient.Close()
+
+	accountMock.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Return(&accountpb.GetAccountResponse{Account: &accountpb.Account{Id: "acc1", Name: "Alice"}}, nil)
+
+	srv := grpcServer{service: NewMockService(ctrl), accountClient: accountClient, inventoryClient: invClient, sagas: newTestSagas(ctrl, invClient)}
+	req := &pb.PostOrderRequest{AccountId: "acc1", Products: []*pb.PostOrderRequest_OrderProduct{{ProductId: "p1", Quantity: 1}, {ProductId: "p2", Quantity: 5}}}
+	rejections := testutil.ToFloat64(outOfStockRejections)
+
+	_, err = srv.PostOrder(context.Background(), req)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	if ids, ok := errcode.OutOfStockProducts(err); !ok || len(ids) != 1 || ids[0] != "p2" {
+		t.Errorf("expected p2 to be listed as out of stock, got %v", ids)
+	}
+	if got := testutil.ToFloat64(outOfStockRejections) - rejections; got != 1 {
+		t.Errorf("expected the rejection to be counted, got %v", got)
+	}
+}
+
+func TestUnitServer_PostOrder_CompensatesStockOnFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountAddr, accountMock, stopAccount := startMockAccountServer(t, ctrl)
+	defer stopAccount()
+	accountClient, err := account.NewClient(accountAddr)
+	if err != nil {
+		t.Fatalf("failed to create account client: %v", err)
+	}
+	defer accountClient.Close()
+
+	catalogAddr, catalogMock, stopCatalog := startMockCatalogServer(t, ctrl)
+	defer stopCatalog()
+	catalogClient, err := catalog.NewClient(catalogAddr)
+	if err != nil {
+		t.Fatalf("failed to create catalog client: %v", err)
+	}
+	defer catalogClient.Close()
+
+	invAddr, invFake, stopInv := startRecordingInventoryServer(t)
+	defer stopInv()
+	invClient, err := inventory.NewClient(invAddr)
+	if err != nil {
+		t.Fatalf("failed to create inventory client: %v", err)
+	}
+	defer invClient.Close()
+
+	accountMock.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Return(&accountpb.GetAccountResponse{Account: &accountpb.Account{Id: "acc1", Name: "Alice"}}, nil)
+	catalogMock.EXPECT().GetProducts(gomock.Any(), gomock.Any()).Return(&catalogpb.GetProductsResponse{
+		Products: []*catalogpb.ProductInResponse{{Product: &catalogpb.Product{Id: "p1", Name: "prod", Price: &moneypb.Money{Amount: 1000, Currency: "USD"}}}},
+	}, nil)
+
+	mockService := NewMockService(ctrl)
+	mockService.EXPECT().PostOrder(gomock.Any(), "acc1", gomock.Any(), nil, gomock.Any()).Return(nil, errors.New("db down"))
+
+	var saved []Saga
+	sagaLog := NewMockRepository(ctrl)
+	sagaLog.EXPECT().PutSaga(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s Saga) error {
+		saved = append(saved, s)
+		return nil
+	}).AnyTimes()
+
+	srv := grpcServer{service: mockService, accountClient: accountClient, catalogClient: catalogClient, inventoryClient: invClient, sagas: newSagaCoordinator(sagaLog, invClient)}
+	req := &pb.PostOrderRequest{AccountId: "acc1", Products: []*pb.PostOrderRequest_OrderProduct{{ProductId: "p1", Quantity: 2}}}
+
+	_, err = srv.PostOrder(context.Background(), req)
+	if err == nil || !strings.HasPrefix(err.Error(), "could not post order") {
+		t.Fatalf("expected could not post order error, got %v", err)
+	}
+
+	updates := invFake.Updates()
+	if len(updates) != 2 {
+		t.Fatalf("expected decrement and compensation, got %d stock updates", len(updates))
+	}
+	if updates[0].Deltas[0] != -2 || updates[1].Deltas[0] != 2 || updates[1].Pids[0] != "p1" {
+		t.Errorf("unexpected stock updates: %v", updates)
+	}
+
+	last := saved[len(saved)-1]
+	if last.Status != SagaCompensated || len(last.Steps) != 0 {
+		t.Errorf("expected compensated saga without steps, got %+v", last)
+	}
+}
+
+func TestUnitServer_PostOrder_TakesNoStockUnlessTheStepIsLogged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountAddr, accountMock, stopAccount := startMockAccountServer(t, ctrl)
+	defer stopAccount()
+	accountClient, err := account.NewClient(accountAddr)
+	if err != nil {
+		t.Fatalf("failed to create account client: %v", err)
+	}
+	defer accountClient.Close()
+
+	invAddr, invFake, stopInv := startRecordingInventoryServer(t)
+	defer stopInv()
+	invClient, err := inventory.NewClient(invAddr)
+	if err != nil {
+		t.Fatalf("failed to create inventory client: %v", err)
+	}
+	defer invClient.Close()
+
+	accountMock.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Return(&accountpb.GetAccountResponse{Account: &accountpb.Account{Id: "acc1", Name: "Alice"}}, nil)
+
+	// The saga is started, then the log goes down.
+	sagaLog := NewMockRepository(ctrl)
+	gomock.InOrder(
+		sagaLog.EXPECT().PutSaga(gomock.Any(), gomock.Any()).Return(nil),
+		sagaLog.EXPECT().PutSaga(gomock.Any(), gomock.Any()).Return(errors.New("db down")).AnyTimes(),
+	)
+
+	srv := grpcServer{service: NewMockService(ctrl), accountClient: accountClient, inventoryClient: invClient, sagas: newSagaCoordinator(sagaLog, invClient)}
+	req := &pb.PostOrderRequest{AccountId: "acc1", Products: []*pb.PostOrderRequest_OrderProduct{{ProductId: "p1", Quantity: 2}}}
+
+	if _, err := srv.PostOrder(context.Background(), req); err == nil {
+		t.Fatal("expected error")
+	}
+	if n := len(invFake.Updates()); n != 0 {
+		t.Errorf("expected no stock to be taken without a logged step, got %d updates", n)
+	}
+}
+
+func TestUnitServer_CancelOrder_RestocksOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	invAddr, invFake, stopInv := startRecordingInventoryServer(t)
+	defer stopInv()
+	invClient, err := inventory.NewClient(invAddr)
+	if err != nil {
+		t.Fatalf("failed to create inventory client: %v", err)
+	}
+	defer invClient.Close()
+
+	mockService := NewMockService(ctrl)
+	gomock.InOrder(
+		mockService.EXPECT().CancelOrder(gomock.Any(), "o1").Return(&StatusChange{OrderID: "o1", From: StatusPaid, To: StatusCancelled}, nil),
+		mockService.EXPECT().ClaimRestock(gomock.Any(), "o1").Return([]OrderedProduct{{ID: "p1", Quantity: 2}, {ID: "p2", Quantity: 1}}, nil),
+		mockService.EXPECT().CancelOrder(gomock.Any(), "o1").Return(&StatusChange{OrderID: "o1", From: StatusCancelled, To: StatusCancelled}, nil),
+		mockService.EXPECT().ClaimRestock(gomock.Any(), "o1").Return([]OrderedProduct{}, nil),
+	)
+
+	srv := grpcServer{service: mockService, inventoryClient: invClient}
+
+	for i := 0; i < 2; i++ {
+		res, err := srv.CancelOrder(context.Background(), &pb.CancelOrderRequest{Id: "o1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != string(StatusCancelled) {
+			t.Errorf("expected cancelled, got %s", res.Status)
+		}
+	}
+
+	updates := invFake.Updates()
+	if len(updates) != 1 {
+		t.Fatalf("expected stock to be restored once, got %d updates", len(updates))
+	}
+	if updates[0].Pids[0] != "p1" || updates[0].Deltas[0] != 2 || updates[0].Deltas[1] != 1 {
+		t.Errorf("unexpected restock: %v", updates[0])
+	}
+}
+
+func TestUnitServer_GetOrdersForAccount_PrefersStoredLines(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	catalogAddr, catalogMock, stopCatalog := startMockCatalogServer(t, ctrl)
+	defer stopCatalog()
+	catalogClient, err := catalog.NewClient(catalogAddr)
+	if err != nil {
+		t.Fatalf("failed to create catalog client: %v", err)
+	}
+	defer catalogClient.Close()
+
+	mockService := NewMockService(ctrl)
+	mockService.EXPECT().GetOrderForAccount(gomock.Any(), "acc1").Return([]Order{{
+		ID:        "o1",
+		AccountID: "acc1",
+		Status:    StatusPaid,
+		Products: []OrderedProduct{
+			{ID: "p1", Name: "old name", Description: "old desc", Price: money.New(1000, "USD"), Quantity: 1, Snapshot: true},
+			{ID: "p2", Quantity: 2},
+		},
+	}}, nil)
+
+	// Only the line without stored details is looked up.
+	catalogMock.EXPECT().GetProducts(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, r *catalogpb.GetProductsRequest) (*catalogpb.GetProductsResponse, error) {
+			if len(r.Ids) != 1 || r.Ids[0] != "p2" {
+				t.Errorf("unexpected catalog lookup: %v", r.Ids)
+			}
+			return &catalogpb.GetProductsResponse{
+				Products: []*catalogpb.ProductInResponse{{
+					Product: &catalogpb.Product{Id: "p2", Name: "legacy", Description: "legacy desc", Price: &moneypb.Money{Amount: 500, Currency: "USD"}},
+				}},
+			}, nil
+		})
+
+	srv := grpcServer{service: mockService, catalogClient: catalogClient}
+	res, err := srv.GetOrdersForAccount(context.Background(), &pb.GetOrdersForAccountRequest{AccountId: "acc1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	products := res.Orders[0].Products
+	if products[0].Name != "old name" || products[0].Price.GetAmount() != 1000 {
+		t.Errorf("expected stored line details, got %v", products[0])
+	}
+	if products[1].Name != "legacy" || products[1].Price.GetAmount() != 500 {
+		t.Errorf("expected catalog details for legacy line, got %v", products[1])
+	}
+}
+
+func TestUnitServer_GetOrdersForAccount_CatalogUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	catalogAddr, catalogMock, stopCatalog := startMockCatalogServer(t, ctrl)
+	defer stopCatalog()
+	catalogClient, err := catalog.NewClient(catalogAddr)
+	if err != nil {
+		t.Fatalf("failed to create catalog client: %v", err)
+	}
+	defer catalogClient.Close()
+
+	mockService := NewMockService(ctrl)
+	mockService.EXPECT().GetOrderForAccount(gomock.Any(), "acc1").Return([]Order{{
+		ID:        "o1",
+		AccountID: "acc1",
+		Products: []OrderedProduct{
+			{ID: "p1", Name: "prod", Price: money.New(1000, "USD"), Quantity: 1, Snapshot: true},
+			{ID: "p2", Quantity: 2},
+		},
+	}}, nil)
+	// The catalog client retries the read before giving up.
+	catalogMock.EXPECT().GetProducts(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "down")).Times(resilience.DefaultPolicy.MaxAttempts)
+
+	srv := grpcServer{service: mockService, catalogClient: catalogClient}
+	res, err := srv.GetOrdersForAccount(context.Background(), &pb.GetOrdersForAccountRequest{AccountId: "acc1"})
+	if err != nil {
+		t.Fatalf("expected orders despite catalog failure, got %v", err)
+	}
+	if len(res.Orders[0].Products) != 2 || res.Orders[0].Products[0].Name != "prod" {
+		t.Errorf("unexpected products: %v", res.Orders[0].Products)
+	}
+}
+
+func TestUnitServer_PostOrder_ReplaysIdempotentRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountAddr, accountMock, stopAccount := startMockAccountServer(t, ctrl)
+	defer stopAccount()
+	accountClient, err := account.NewClient(accountAddr)
+	if err != nil {
+		t.Fatalf("failed to create account client: %v", err)
+	}
+	defer accountClient.Close()
+
+	invAddr, invFake, stopInv := startRecordingInventoryServer(t)
+	defer stopInv()
+	invClient, err := inventory.NewClient(invAddr)
+	if err != nil {
+		t.Fatalf("failed to create inventory client: %v", err)
+	}
+	defer invClient.Close()
+
+	accountMock.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Return(&accountpb.GetAccountResponse{Account: &accountpb.Account{Id: "acc1", Name: "Alice"}}, nil)
+
+	req := &pb.PostOrderRequest{AccountId: "acc1", Products: []*pb.PostOrderRequest_OrderProduct{{ProductId: "p1", Quantity: 2}}}
+
+	mockService := NewMockService(ctrl)
+	mockService.EXPECT().ClaimIdempotencyKey(gomock.Any(), "k1", postOrderFingerprint(req)).Return(nil, "o1", nil)
+	mockService.EXPECT().GetOrder(gomock.Any(), "o1").Return(&Order{
+		ID:         "o1",
+		AccountID:  "acc1",
+		TotalPrice: money.New(2000, "USD"),
+		Status:     StatusPending,
+		Products:   []OrderedProduct{{ID: "p1", Name: "prod", Price: money.New(1000, "USD"), Quantity: 2}},
+	}, nil)
+	mockService.EXPECT().PostOrder(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	srv := grpcServer{service: mockService, accountClient: accountClient, inventoryClient: invClient, sagas: newTestSagas(ctrl, invClient)}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotency.MetadataKey, "k1"))
+	resp, err := srv.PostOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.GetOrder().GetId() != "o1" || resp.GetOrder().TotalPrice.GetAmount() != 2000 {
+		t.Errorf("expected stored order o1, got %+v", resp.GetOrder())
+	}
+	if n := len(invFake.Updates()); n != 0 {
+		t.Errorf("expected no stock updates on replay, got %d", n)
+	}
+}
+
+func TestUnitServer_PostOrder_ReleasesIdempotencyKeyOnFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountAddr, accountMock, stopAccount := startMockAccountServer(t, ctrl)
+	defer stopAccount()
+	accountClient, err := account.NewClient(accountAddr)
+	if err != nil {
+		t.Fatalf("failed to create account client: %v", err)
+	}
+	defer accountClient.Close()
+
+	catalogAddr, catalogMock, stopCatalog := startMockCatalogServer(t, ctrl)
+	defer stopCatalog()
+	catalogClient, err := catalog.NewClient(catalogAddr)
+	if err != nil {
+		t.Fatalf("failed to create catalog client: %v", err)
+	}
+	defer catalogClient.Close()
+
+	invAddr, invFake, stopInv := startRecordingInventoryServer(t)
+	defer stopInv()
+	invClient, err := inventory.NewClient(invAddr)
+	if err != nil {
+		t.Fatalf("failed to create inventory client: %v", err)
+	}
+	defer invClient.Close()
+
+	accountMock.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Return(&accountpb.GetAccountResponse{Account: &accountpb.Account{Id: "acc1", Name: "Alice"}}, nil)
+	catalogMock.EXPECT().GetProducts(gomock.Any(), gomock.Any()).Return(&catalogpb.GetProductsResponse{
+		Products: []*catalogpb.ProductInResponse{{Product: &catalogpb.Product{Id: "p1", Name: "prod", Price: &moneypb.Money{Amount: 1000, Currency: "USD"}}}},
+	}, nil)
+
+	claim := &IdempotencyClaim{Key: "k1", Token: "t1"}
+	mockService := NewMockService(ctrl)
+	mockService.EXPECT().ClaimIdempotencyKey(gomock.Any(), "k1", gomock.Any()).Return(claim, "", nil)
+	mockService.EXPECT().PostOrder(gomock.Any(), "acc1", gomock.Any(), claim, gomock.Any()).Return(nil, errors.New("db down"))
+	mockService.EXPECT().ReleaseIdempotencyKey(gomock.Any(), *claim).Return(nil)
+
+	srv := grpcServer{service: mockService, accountClient: accountClient, catalogClient: catalogClient, inventoryClient: invClient, sagas: newTestSagas(ctrl, invClient)}
+	req := &pb.PostOrderRequest{AccountId: "acc1", IdempotencyKey: "k1", Products: []*pb.PostOrderRequest_OrderProduct{{ProductId: "p1", Quantity: 2}}}
+
+	if _, err := srv.PostOrder(context.Background(), req); err == nil {
+		t.Fatal("expected error")
+	}
+
+	updates := invFake.Updates()
+	if len(updates) != 2 {
+		t.Fatalf("expected decrement and compensation, got %d stock updates", len(updates))
+	}
+	if !strings.HasSuffix(updates[0].IdempotencyKey, ":update_stock") || !strings.HasSuffix(updates[1].IdempotencyKey, ":undo:0") {
+		t.Errorf("expected keyed stock updates, got %q and %q", updates[0].IdempotencyKey, updates[1].IdempotencyKey)
+	}
+}
+
+func TestUnitServer_ListOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	afterBytes, _ := after.MarshalBinary()
+
+	mockService := NewMockService(ctrl)
+	mockService.EXPECT().ListOrders(gomock.Any(), gomock.Any(), 10, "c1").DoAndReturn(
+		func(ctx context.Context, f OrderFilter, first int, cursor string) (*OrderPage, error) {
+			if f.AccountID != "acc1" || !f.CreatedAfter.Equal(after) || !f.CreatedBefore.IsZero() {
+				t.Errorf("unexpected filter %+v", f)
+			}
+			if len(f.Statuses) != 1 || f.Statuses[0] != StatusShipped || f.MinTotal == nil || *f.MinTotal != money.New(500, "EUR") {
+				t.Errorf("unexpected filter %+v", f)
+			}
+			return &OrderPage{
+				Orders: []Order{{
+					ID:       "o1",
+					Status:   StatusShipped,
+					Products: []OrderedProduct{{ID: "p1", Name: "prod", Price: money.New(700, "EUR"), Quantity: 1, Snapshot: true}},
+				}},
+				EndCursor:   Cursor("o1"),
+				HasNextPage: true,
+			}, nil
+		})
+
+	// Every line is stored with the order, so the catalog is not needed.
+	srv := grpcServer{service: mockService}
+	res, err := srv.ListOrders(context.Background(), &pb.ListOrdersRequest{
+		AccountId:    "acc1",
+		CreatedAfter: afterBytes,
+		Statuses:     []string{"shipped"},
+		MinTotal:     &moneypb.Money{Amount: 500, Currency: "EUR"},
+		First:        10,
+		After:        "c1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Orders) != 1 || res.Orders[0].Id != "o1" || res.EndCursor != Cursor("o1") || !res.HasNextPage {
+		t.Fatalf("unexpected response %+v", res)
+	}
+}
+
+func TestUnitServer_GetOrder_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockService(ctrl)
+	mockService.EXPECT().GetOrder(gomock.Any(), "missing").Return(nil, ErrOrderNotFound)
+
+	srv := grpcServer{service: mockService}
+	if _, err := srv.GetOrder(context.Background(), &pb.GetOrderRequest{Id: "missing"}); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("expected ErrOrderNotFound, got %v", err)
+	}
+}
+
+func TestUnitServer_OwnershipChecks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockService(ctrl)
+	mockService.EXPECT().GetOrder(gomock.Any(), "o1").Return(&Order{ID: "o1", AccountID: "acc1"}, nil).AnyTimes()
+
+	srv := grpcServer{service: mockService}
+	stranger := auth.NewContext(context.Background(), auth.Identity{AccountID: "acc2", Role: auth.RoleMerchant})
+
+	if _, err := srv.GetOrder(stranger, &pb.GetOrderRequest{Id: "o1"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("GetOrder: expected PermissionDenied, got %v", err)
+	}
+	if _, err := srv.CancelOrder(stranger, &pb.CancelOrderRequest{Id: "o1"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("CancelOrder: expected PermissionDenied, got %v", err)
+	}
+	if _, err := srv.PostOrder(stranger, &pb.PostOrderRequest{AccountId: "acc1"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("PostOrder: expected PermissionDenied, got %v", err)
+	}
+	if _, err := srv.ListOrders(stranger, &pb.ListOrdersRequest{AccountId: "acc1"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ListOrders: expected PermissionDenied, got %v", err)
+	}
+	if _, err := srv.GetOrdersForAccounts(stranger, &pb.GetOrdersForAccountsRequest{AccountIds: []string{"acc2", "acc1"}}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("GetOrdersForAccounts: expected PermissionDenied, got %v", err)
+	}
+
+	admin := auth.NewContext(context.Background(), auth.Identity{AccountID: "acc3", Role: auth.RoleAdmin})
+	if _, err := srv.GetOrder(admin, &pb.GetOrderRequest{Id: "o1"}); err != nil {
+		t.Errorf("GetOrder: expected admin to read any order, got %v", err)
+	}
+}
+
+func TestUnitServer_ListOrders_ScopedToCaller(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockService(ctrl)
+	mockService.EXPECT().ListOrders(gomock.Any(), OrderFilter{AccountID: "acc1"}, 0, "").Return(&OrderPage{}, nil)
+
+	srv := grpcServer{service: mockService}
+	ctx := auth.NewContext(context.Background(), auth.Identity{AccountID: "acc1", Role: auth.RoleCustomer})
+	if _, err := srv.ListOrders(ctx, &pb.ListOrdersRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnitServer_GetOrdersForAccounts_SingleCatalogLookup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	catalogAddr, catalogMock, stopCatalog := startMockCatalogServer(t, ctrl)
+	defer stopCatalog()
+	catalogClient, err := catalog.NewClient(catalogAddr)
+	if err != nil {
+		t.Fatalf("failed to create catalog client: %v", err)
+	}
+	defer catalogClient.Close()
+
+	mockService := NewMockService(ctrl)
+	mockService.EXPECT().GetOrdersForAccounts(gomock.Any(), []string{"acc1", "acc2", "acc3"}).Return(map[string][]Order{
+		"acc1": {{ID: "o1", AccountID: "acc1", Products: []OrderedProduct{{ID: "p1", Quantity: 1}}}},
+		"acc2": {{ID: "o2", AccountID: "acc2", Products: []OrderedProduct{{ID: "p2", Quantity: 1}}}},
+	}, nil)
+
+	catalogMock.EXPECT().GetProducts(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, r *catalogpb.GetProductsRequest) (*catalogpb.GetProductsResponse, error) {
+			if len(r.Ids) != 2 {
+				t.Errorf("expected both products in one lookup, got %v", r.Ids)
+			}
+			return &catalogpb.GetProductsResponse{
+				Products: []*catalogpb.ProductInResponse{
+					{Product: &catalogpb.Product{Id: "p1", Name: "one", Price: &moneypb.Money{Amount: 100, Currency: "USD"}}},
+					{Product: &catalogpb.Product{Id: "p2", Name: "two", Price: &moneypb.Money{Amount: 200, Currency: "USD"}}},
+				},
+			}, nil
+		}).Times(1)
+
+	srv := grpcServer{service: mockService, catalogClient: catalogClient}
+	res, err := srv.GetOrdersForAccounts(context.Background(), &pb.GetOrdersForAccountsRequest{AccountIds: []string{"acc1", "acc2", "acc3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Accounts) != 3 {
+		t.Fatalf("expected an entry per account, got %d", len(res.Accounts))
+	}
+	if a := res.Accounts[0]; a.AccountId != "acc1" || len(a.Orders) != 1 || a.Orders[0].Products[0].Name != "one" {
+		t.Errorf("unexpected acc1 entry %v", a)
+	}
+	if a := res.Accounts[1]; a.AccountId != "acc2" || len(a.Orders) != 1 || a.Orders[0].Products[0].Name != "two" {
+		t.Errorf("unexpected acc2 entry %v", a)
+	}
+	if a := res.Accounts[2]; a.AccountId != "acc3" || len(a.Orders) != 0 {
+		t.Errorf("expected no orders for acc3, got %v", a)
+	}
+}
@@ -2,16 +2,20 @@ package order
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/idempotency"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"github.com/segmentio/ksuid"
 )
 
 type Order struct {
 	ID         string
 	CreatedAt  time.Time
-	TotalPrice float64
+	TotalPrice money.Money
 	AccountID  string
+	Status     OrderStatus
 	Products   []OrderedProduct
 }
 
@@ -19,42 +23,280 @@ type OrderedProduct struct {
 	ID          string
 	Name        string
 	Description string
-	Price       float64
+	Price       money.Money
 	Quantity    uint32
+	// Snapshot reports whether Name, Description and Price were stored with
+	// the order. Lines of orders placed before that have to be looked up in
+	// the catalog instead.
+	Snapshot bool
 }
 
 type Service interface {
-	PostOrder(ctx context.Context, accountID string, products []OrderedProduct) (*Order, error)
+	// Ping reports whether the store behind the service can be reached.
+	Ping(ctx context.Context) error
+	PostOrder(ctx context.Context, accountID string, products []OrderedProduct, claim *IdempotencyClaim, sagaID string) (*Order, error)
+	GetOrder(ctx context.Context, id string) (*Order, error)
 	GetOrderForAccount(ctx context.Context, accountID string) ([]Order, error)
+	GetOrdersForAccounts(ctx context.Context, accountIDs []string) (map[string][]Order, error)
+	ListOrders(ctx context.Context, filter OrderFilter, first int, after string) (*OrderPage, error)
+	UpdateOrderStatus(ctx context.Context, id string, status OrderStatus) (*StatusChange, error)
+	CancelOrder(ctx context.Context, id string) (*StatusChange, error)
+	ClaimRestock(ctx context.Context, id string) ([]OrderedProduct, error)
+	ReleaseRestock(ctx context.Context, id string) error
+	ClaimIdempotencyKey(ctx context.Context, key, fingerprint string) (claim *IdempotencyClaim, orderID string, err error)
+	ReleaseIdempotencyKey(ctx context.Context, claim IdempotencyClaim) error
+	PurgeIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+// DefaultCancellableUntil is the furthest status from which orders can be cancelled.
+const DefaultCancellableUntil = StatusPaid
+
+type ServiceOption func(*orderService)
+
+// WithCancellableUntil sets the furthest status from which an order may still be cancelled.
+func WithCancellableUntil(status OrderStatus) ServiceOption {
+	return func(s *orderService) {
+		s.cancellableUntil = status
+	}
 }
 
 type orderService struct {
-	repository Repository
+	repository       Repository
+	cancellableUntil OrderStatus
 }
 
-func NewOrderService(r Repository) Service {
-	return &orderService{r}
+func NewOrderService(r Repository, opts ...ServiceOption) Service {
+	s := &orderService{repository: r, cancellableUntil: DefaultCancellableUntil}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *orderService) PostOrder(ctx context.Context, accountID string, products []OrderedProduct) (*Order, error) {
-	var totalPrice float64 = 0
+// PostOrder stores a new order for products. A non-nil claim, obtained from
+// ClaimIdempotencyKey, links the order to its idempotency key. A non-empty
+// sagaID names the saga placing the order, which the order takes the ID of
+// and completes.
+func (s *orderService) PostOrder(ctx context.Context, accountID string, products []OrderedProduct, claim *IdempotencyClaim, sagaID string) (*Order, error) {
+	totalPrice := money.New(0, money.DefaultCurrency)
+	if len(products) != 0 {
+		totalPrice.Currency = products[0].Price.Currency
+	}
 	for _, p := range products {
-		totalPrice += (p.Price * float64(p.Quantity))
+		line, err := p.Price.Mul(int64(p.Quantity))
+		if err != nil {
+			return nil, err
+		}
+		if totalPrice, err = totalPrice.Add(line); err != nil {
+			return nil, err
+		}
+	}
+	id := sagaID
+	if id == "" {
+		id = ksuid.New().String()
 	}
 	o := &Order{
-		ID:         ksuid.New().String(),
+		ID:         id,
 		CreatedAt:  time.Now().UTC(),
 		TotalPrice: totalPrice,
 		AccountID:  accountID,
+		Status:     StatusPending,
 		Products:   products,
 	}
-	if err := s.repository.PutOrder(ctx, *o); err != nil {
+	if err := s.repository.PutOrder(ctx, *o, claim); err != nil {
 		return nil, err
 	}
 
 	return o, nil
 }
 
+func (s *orderService) GetOrder(ctx context.Context, id string) (*Order, error) {
+	return s.repository.GetOrder(ctx, id)
+}
+
 func (s *orderService) GetOrderForAccount(ctx context.Context, accountID string) ([]Order, error) {
 	return s.repository.GetOrderForAccount(ctx, accountID)
 }
+
+// GetOrdersForAccounts returns the orders of every account in accountIDs,
+// keyed by account ID, in a single query.
+func (s *orderService) GetOrdersForAccounts(ctx context.Context, accountIDs []string) (map[string][]Order, error) {
+	orders, err := s.repository.GetOrdersForAccounts(ctx, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byAccount := map[string][]Order{}
+	for _, o := range orders {
+		byAccount[o.AccountID] = append(byAccount[o.AccountID], o)
+	}
+
+	return byAccount, nil
+}
+
+// ListOrders returns up to first orders matching filter, newest first,
+// continuing after the cursor after if set. first defaults to
+// DefaultPageSize and is capped at MaxPageSize.
+func (s *orderService) ListOrders(ctx context.Context, filter OrderFilter, first int, after string) (*OrderPage, error) {
+	for _, st := range filter.Statuses {
+		if !st.Valid() {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidStatus, st)
+		}
+	}
+	if filter.MinTotal != nil && !money.ValidCurrency(filter.MinTotal.Currency) {
+		return nil, money.ErrInvalidCurrency
+	}
+
+	if first <= 0 {
+		first = DefaultPageSize
+	}
+	first = min(first, MaxPageSize)
+
+	afterID := ""
+	if after != "" {
+		var err error
+		if afterID, err = decodeCursor(after); err != nil {
+			return nil, err
+		}
+	}
+
+	// One extra order tells whether there is another page.
+	orders, err := s.repository.ListOrders(ctx, filter, afterID, first+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &OrderPage{Orders: orders}
+	if len(orders) > first {
+		page.Orders = orders[:first]
+		page.HasNextPage = true
+	}
+	if n := len(page.Orders); n != 0 {
+		page.EndCursor = Cursor(page.Orders[n-1].ID)
+	}
+
+	return page, nil
+}
+
+// UpdateOrderStatus moves an order to status. Orders can't be cancelled
+// this way, only with CancelOrder, which also returns their stock.
+func (s *orderService) UpdateOrderStatus(ctx context.Context, id string, status OrderStatus) (*StatusChange, error) {
+	if !status.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+	}
+	if status == StatusCancelled {
+		return nil, fmt.Errorf("%w: orders are cancelled with CancelOrder", ErrInvalidTransition)
+	}
+
+	current, err := s.repository.GetOrderStatus(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !current.CanTransitionTo(status) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, current, status)
+	}
+
+	c := &StatusChange{
+		OrderID:   id,
+		From:      current,
+		To:        status,
+		ChangedAt: time.Now().UTC(),
+	}
+	if err := s.repository.UpdateOrderStatus(ctx, *c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// CancelOrder moves an order to cancelled. Cancelling an order that is
+// already cancelled succeeds without recording a new change, so callers can
+// safely retry.
+func (s *orderService) CancelOrder(ctx context.Context, id string) (*StatusChange, error) {
+	current, err := s.repository.GetOrderStatus(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current == StatusCancelled {
+		return &StatusChange{OrderID: id, From: current, To: current, ChangedAt: time.Now().UTC()}, nil
+	}
+	if !current.NotPast(s.cancellableUntil) {
+		return nil, fmt.Errorf("%w: order is %s", ErrNotCancellable, current)
+	}
+	if !current.CanTransitionTo(StatusCancelled) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, current, StatusCancelled)
+	}
+
+	c := &StatusChange{
+		OrderID:   id,
+		From:      current,
+		To:        StatusCancelled,
+		ChangedAt: time.Now().UTC(),
+	}
+	if err := s.repository.UpdateOrderStatus(ctx, *c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ClaimRestock returns the lines of a cancelled order whose stock has not
+// been returned yet and marks them as returned. It returns no lines if the
+// stock was already claimed.
+func (s *orderService) ClaimRestock(ctx context.Context, id string) ([]OrderedProduct, error) {
+	return s.repository.ClaimRestock(ctx, id)
+}
+
+// ReleaseRestock undoes ClaimRestock when returning the stock failed.
+func (s *orderService) ReleaseRestock(ctx context.Context, id string) error {
+	return s.repository.ReleaseRestock(ctx, id)
+}
+
+// ClaimIdempotencyKey reserves key for the request identified by fingerprint.
+// If an order was already placed with key, its ID is returned instead of a
+// claim. Reusing key for a different request fails with
+// ErrIdempotencyKeyReused, and a key held by a request still in progress
+// fails with ErrIdempotencyKeyInUse.
+func (s *orderService) ClaimIdempotencyKey(ctx context.Context, key, fingerprint string) (*IdempotencyClaim, string, error) {
+	if key == "" || len(key) > idempotency.MaxKeyLength {
+		return nil, "", ErrInvalidIdempotencyKey
+	}
+
+	now := time.Now().UTC()
+	rec := IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Token:       ksuid.New().String(),
+		LockedUntil: now.Add(idempotencyLease),
+		ExpiresAt:   now.Add(IdempotencyKeyTTL),
+	}
+	stored, err := s.repository.ClaimIdempotencyKey(ctx, rec)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case stored.Fingerprint != fingerprint:
+		return nil, "", ErrIdempotencyKeyReused
+	case stored.OrderID != "":
+		return nil, stored.OrderID, nil
+	case stored.Token != rec.Token:
+		return nil, "", ErrIdempotencyKeyInUse
+	}
+
+	return &IdempotencyClaim{Key: key, Token: rec.Token}, "", nil
+}
+
+// ReleaseIdempotencyKey frees a claim whose request failed before placing an order.
+func (s *orderService) ReleaseIdempotencyKey(ctx context.Context, claim IdempotencyClaim) error {
+	return s.repository.ReleaseIdempotencyKey(ctx, claim)
+}
+
+// PurgeIdempotencyKeys deletes the keys older than IdempotencyKeyTTL.
+func (s *orderService) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	return s.repository.PurgeIdempotencyKeys(ctx, time.Now().UTC())
+}
+
+func (s *orderService) Ping(ctx context.Context) error {
+	return s.repository.Ping(ctx)
+}
@@ -3,39 +3,41 @@ package order
 import (
 	"context"
 	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 )
 
 func TestService_PostOrder_Success(t *testing.T) {
 	products := []OrderedProduct{
-		{ID: "p1", Quantity: 2, Price: 1.82},
-		{ID: "p2", Quantity: 1, Price: 2.64},
+		{ID: "p1", Quantity: 2, Price: money.New(182, "USD")},
+		{ID: "p2", Quantity: 1, Price: money.New(264, "USD")},
 	}
 
-	svc := &orderService{testRepo}
+	svc := NewOrderService(testRepo)
 
-	o, err := svc.PostOrder(context.Background(), "alice1321", products)
+	o, err := svc.PostOrder(context.Background(), "alice1321", products, nil, "")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if o.TotalPrice != 2*products[0].Price+products[1].Price {
+	if o.TotalPrice != money.New(2*182+264, "USD") {
 		t.Error("unexpected total price")
 	}
 }
 
 func TestService_GetOrderByAccount(t *testing.T) {
 	products := []OrderedProduct{
-		{ID: "p1", Quantity: 2, Price: 1.82},
-		{ID: "p2", Quantity: 1, Price: 2.64},
+		{ID: "p1", Quantity: 2, Price: money.New(182, "USD")},
+		{ID: "p2", Quantity: 1, Price: money.New(264, "USD")},
 	}
 
-	svc := &orderService{testRepo}
+	svc := NewOrderService(testRepo)
 
-	_, err := svc.PostOrder(context.Background(), "bob4532", products)
+	_, err := svc.PostOrder(context.Background(), "bob4532", products, nil, "")
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, err = svc.PostOrder(context.Background(), "alice1321", products)
+	_, err = svc.PostOrder(context.Background(), "alice1321", products, nil, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -40,6 +40,67 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// CancelOrder mocks base method.
+func (m *MockService) CancelOrder(ctx context.Context, id string) (*StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", ctx, id)
+	ret0, _ := ret[0].(*StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockServiceMockRecorder) CancelOrder(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockService)(nil).CancelOrder), ctx, id)
+}
+
+// ClaimIdempotencyKey mocks base method.
+func (m *MockService) ClaimIdempotencyKey(ctx context.Context, key, fingerprint string) (*IdempotencyClaim, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimIdempotencyKey", ctx, key, fingerprint)
+	ret0, _ := ret[0].(*IdempotencyClaim)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimIdempotencyKey indicates an expected call of ClaimIdempotencyKey.
+func (mr *MockServiceMockRecorder) ClaimIdempotencyKey(ctx, key, fingerprint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimIdempotencyKey", reflect.TypeOf((*MockService)(nil).ClaimIdempotencyKey), ctx, key, fingerprint)
+}
+
+// ClaimRestock mocks base method.
+func (m *MockService) ClaimRestock(ctx context.Context, id string) ([]OrderedProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimRestock", ctx, id)
+	ret0, _ := ret[0].([]OrderedProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimRestock indicates an expected call of ClaimRestock.
+func (mr *MockServiceMockRecorder) ClaimRestock(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimRestock", reflect.TypeOf((*MockService)(nil).ClaimRestock), ctx, id)
+}
+
+// GetOrder mocks base method.
+func (m *MockService) GetOrder(ctx context.Context, id string) (*Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, id)
+	ret0, _ := ret[0].(*Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockServiceMockRecorder) GetOrder(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockService)(nil).GetOrder), ctx, id)
+}
+
 // GetOrderForAccount mocks base method.
 func (m *MockService) GetOrderForAccount(ctx context.Context, accountID string) ([]Order, error) {
 	m.ctrl.T.Helper()
@@ -55,17 +116,119 @@ func (mr *MockServiceMockRecorder) GetOrderForAccount(ctx, accountID any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderForAccount", reflect.TypeOf((*MockService)(nil).GetOrderForAccount), ctx, accountID)
 }
 
+// GetOrdersForAccounts mocks base method.
+func (m *MockService) GetOrdersForAccounts(ctx context.Context, accountIDs []string) (map[string][]Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersForAccounts", ctx, accountIDs)
+	ret0, _ := ret[0].(map[string][]Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersForAccounts indicates an expected call of GetOrdersForAccounts.
+func (mr *MockServiceMockRecorder) GetOrdersForAccounts(ctx, accountIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersForAccounts", reflect.TypeOf((*MockService)(nil).GetOrdersForAccounts), ctx, accountIDs)
+}
+
+// ListOrders mocks base method.
+func (m *MockService) ListOrders(ctx context.Context, filter OrderFilter, first int, after string) (*OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, filter, first, after)
+	ret0, _ := ret[0].(*OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockServiceMockRecorder) ListOrders(ctx, filter, first, after any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockService)(nil).ListOrders), ctx, filter, first, after)
+}
+
+// Ping mocks base method.
+func (m *MockService) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockServiceMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockService)(nil).Ping), ctx)
+}
+
 // PostOrder mocks base method.
-func (m *MockService) PostOrder(ctx context.Context, accountID string, products []OrderedProduct) (*Order, error) {
+func (m *MockService) PostOrder(ctx context.Context, accountID string, products []OrderedProduct, claim *IdempotencyClaim, sagaID string) (*Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PostOrder", ctx, accountID, products)
+	ret := m.ctrl.Call(m, "PostOrder", ctx, accountID, products, claim, sagaID)
 	ret0, _ := ret[0].(*Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // PostOrder indicates an expected call of PostOrder.
-func (mr *MockServiceMockRecorder) PostOrder(ctx, accountID, products any) *gomock.Call {
+func (mr *MockServiceMockRecorder) PostOrder(ctx, accountID, products, claim, sagaID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostOrder", reflect.TypeOf((*MockService)(nil).PostOrder), ctx, accountID, products, claim, sagaID)
+}
+
+// PurgeIdempotencyKeys mocks base method.
+func (m *MockService) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeIdempotencyKeys", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeIdempotencyKeys indicates an expected call of PurgeIdempotencyKeys.
+func (mr *MockServiceMockRecorder) PurgeIdempotencyKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeIdempotencyKeys", reflect.TypeOf((*MockService)(nil).PurgeIdempotencyKeys), ctx)
+}
+
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockService) ReleaseIdempotencyKey(ctx context.Context, claim IdempotencyClaim) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", ctx, claim)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockServiceMockRecorder) ReleaseIdempotencyKey(ctx, claim any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockService)(nil).ReleaseIdempotencyKey), ctx, claim)
+}
+
+// ReleaseRestock mocks base method.
+func (m *MockService) ReleaseRestock(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseRestock", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseRestock indicates an expected call of ReleaseRestock.
+func (mr *MockServiceMockRecorder) ReleaseRestock(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseRestock", reflect.TypeOf((*MockService)(nil).ReleaseRestock), ctx, id)
+}
+
+// UpdateOrderStatus mocks base method.
+func (m *MockService) UpdateOrderStatus(ctx context.Context, id string, status OrderStatus) (*StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStatus", ctx, id, status)
+	ret0, _ := ret[0].(*StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOrderStatus indicates an expected call of UpdateOrderStatus.
+func (mr *MockServiceMockRecorder) UpdateOrderStatus(ctx, id, status any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostOrder", reflect.TypeOf((*MockService)(nil).PostOrder), ctx, accountID, products)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockService)(nil).UpdateOrderStatus), ctx, id, status)
 }
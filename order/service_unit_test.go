@@ -2,9 +2,12 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
 	"go.uber.org/mock/gomock"
 )
 
@@ -16,16 +19,16 @@ func TestUnitService_PostOrder_Success(t *testing.T) {
 	svc := NewOrderService(mockRepo)
 
 	products := []OrderedProduct{
-		{ID: "p1", Name: "Product 1", Description: "Desc 1", Price: 10.50, Quantity: 2},
-		{ID: "p2", Name: "Product 2", Description: "Desc 2", Price: 5.25, Quantity: 3},
+		{ID: "p1", Name: "Product 1", Description: "Desc 1", Price: money.New(1050, "USD"), Quantity: 2},
+		{ID: "p2", Name: "Product 2", Description: "Desc 2", Price: money.New(525, "USD"), Quantity: 3},
 	}
 
 	mockRepo.EXPECT().
-		PutOrder(gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, order Order) error {
-			expectedTotal := (10.50 * 2) + (5.25 * 3)
+		PutOrder(gomock.Any(), gomock.Any(), nil).
+		DoAndReturn(func(ctx context.Context, order Order, claim *IdempotencyClaim) error {
+			expectedTotal := money.New(1050*2+525*3, "USD")
 			if order.TotalPrice != expectedTotal {
-				t.Errorf("expected total price %f, got %f", expectedTotal, order.TotalPrice)
+				t.Errorf("expected total price %v, got %v", expectedTotal, order.TotalPrice)
 			}
 			if order.AccountID != "a1" {
 				t.Errorf("expected account ID 'a1', got %s", order.AccountID)
@@ -39,7 +42,7 @@ func TestUnitService_PostOrder_Success(t *testing.T) {
 			return nil
 		})
 
-	result, err := svc.PostOrder(context.Background(), "a1", products)
+	result, err := svc.PostOrder(context.Background(), "a1", products, nil, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -48,9 +51,9 @@ func TestUnitService_PostOrder_Success(t *testing.T) {
 		t.Error("expected non-empty order ID")
 	}
 
-	expectedTotal := (10.50 * 2) + (5.25 * 3)
+	expectedTotal := money.New(1050*2+525*3, "USD")
 	if result.TotalPrice != expectedTotal {
-		t.Errorf("expected total price %f, got %f", expectedTotal, result.TotalPrice)
+		t.Errorf("expected total price %v, got %v", expectedTotal, result.TotalPrice)
 	}
 
 	if result.AccountID != "a1" {
@@ -70,21 +73,21 @@ func TestUnitService_PostOrder_EmptyProducts(t *testing.T) {
 	svc := NewOrderService(mockRepo)
 
 	mockRepo.EXPECT().
-		PutOrder(gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, order Order) error {
-			if order.TotalPrice != 0 {
-				t.Errorf("expected total price 0, got %f", order.TotalPrice)
+		PutOrder(gomock.Any(), gomock.Any(), nil).
+		DoAndReturn(func(ctx context.Context, order Order, claim *IdempotencyClaim) error {
+			if order.TotalPrice != money.New(0, money.DefaultCurrency) {
+				t.Errorf("expected total price 0, got %v", order.TotalPrice)
 			}
 			return nil
 		})
 
-	result, err := svc.PostOrder(context.Background(), "a1", []OrderedProduct{})
+	result, err := svc.PostOrder(context.Background(), "a1", []OrderedProduct{}, nil, "")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if result.TotalPrice != 0 {
-		t.Errorf("expected total price 0 for empty order, got %f", result.TotalPrice)
+	if result.TotalPrice != money.New(0, money.DefaultCurrency) {
+		t.Errorf("expected total price 0 for empty order, got %v", result.TotalPrice)
 	}
 }
 
@@ -96,15 +99,15 @@ func TestUnitService_PostOrder_RepositoryError(t *testing.T) {
 	svc := NewOrderService(mockRepo)
 
 	products := []OrderedProduct{
-		{ID: "p1", Name: "Product 1", Price: 10.00, Quantity: 1},
+		{ID: "p1", Name: "Product 1", Price: money.New(1000, "USD"), Quantity: 1},
 	}
 
 	expectedErr := fmt.Errorf("database error")
 	mockRepo.EXPECT().
-		PutOrder(gomock.Any(), gomock.Any()).
+		PutOrder(gomock.Any(), gomock.Any(), nil).
 		Return(expectedErr)
 
-	result, err := svc.PostOrder(context.Background(), "a1", products)
+	result, err := svc.PostOrder(context.Background(), "a1", products, nil, "")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -128,59 +131,77 @@ func TestUnitService_PostOrder_TotalPriceCalculation(t *testing.T) {
 	testCases := []struct {
 		name          string
 		products      []OrderedProduct
-		expectedTotal float64
+		expectedTotal money.Money
 	}{
 		{
 			name: "Single product",
 			products: []OrderedProduct{
-				{ID: "p1", Name: "Product 1", Price: 10.50, Quantity: 1},
+				{ID: "p1", Name: "Product 1", Price: money.New(1050, "USD"), Quantity: 1},
 			},
-			expectedTotal: 10.50,
+			expectedTotal: money.New(1050, "USD"),
 		},
 		{
 			name: "Multiple quantities",
 			products: []OrderedProduct{
-				{ID: "p1", Name: "Product 1", Price: 10.00, Quantity: 5},
+				{ID: "p1", Name: "Product 1", Price: money.New(1000, "USD"), Quantity: 5},
 			},
-			expectedTotal: 50.00,
+			expectedTotal: money.New(5000, "USD"),
 		},
 		{
 			name: "Multiple products",
 			products: []OrderedProduct{
-				{ID: "p1", Name: "Product 1", Price: 10.00, Quantity: 2},
-				{ID: "p2", Name: "Product 2", Price: 5.50, Quantity: 3},
-				{ID: "p3", Name: "Product 3", Price: 7.25, Quantity: 1},
+				{ID: "p1", Name: "Product 1", Price: money.New(1000, "USD"), Quantity: 2},
+				{ID: "p2", Name: "Product 2", Price: money.New(550, "USD"), Quantity: 3},
+				{ID: "p3", Name: "Product 3", Price: money.New(725, "USD"), Quantity: 1},
 			},
-			expectedTotal: (10.00 * 2) + (5.50 * 3) + (7.25 * 1),
+			expectedTotal: money.New(1000*2+550*3+725*1, "USD"),
 		},
 		{
 			name: "Decimal prices",
 			products: []OrderedProduct{
-				{ID: "p1", Name: "Product 1", Price: 9.99, Quantity: 3},
-				{ID: "p2", Name: "Product 2", Price: 0.99, Quantity: 5},
+				{ID: "p1", Name: "Product 1", Price: money.New(999, "USD"), Quantity: 3},
+				{ID: "p2", Name: "Product 2", Price: money.New(99, "USD"), Quantity: 5},
 			},
-			expectedTotal: (9.99 * 3) + (0.99 * 5),
+			expectedTotal: money.New(999*3+99*5, "USD"),
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mockRepo.EXPECT().
-				PutOrder(gomock.Any(), gomock.Any()).
+				PutOrder(gomock.Any(), gomock.Any(), nil).
 				Return(nil)
 
-			result, err := svc.PostOrder(context.Background(), "a1", tc.products)
+			result, err := svc.PostOrder(context.Background(), "a1", tc.products, nil, "")
 			if err != nil {
 				t.Fatal(err)
 			}
 
 			if result.TotalPrice != tc.expectedTotal {
-				t.Errorf("expected total price %f, got %f", tc.expectedTotal, result.TotalPrice)
+				t.Errorf("expected total price %v, got %v", tc.expectedTotal, result.TotalPrice)
 			}
 		})
 	}
 }
 
+func TestUnitService_PostOrder_MixedCurrencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewOrderService(mockRepo)
+
+	mockRepo.EXPECT().PutOrder(gomock.Any(), gomock.Any(), nil).Times(0)
+
+	_, err := svc.PostOrder(context.Background(), "a1", []OrderedProduct{
+		{ID: "p1", Price: money.New(1000, "USD"), Quantity: 1},
+		{ID: "p2", Price: money.New(1000, "EUR"), Quantity: 1},
+	}, nil, "")
+	if !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+}
+
 func TestUnitService_GetOrderForAccount_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -192,17 +213,17 @@ func TestUnitService_GetOrderForAccount_Success(t *testing.T) {
 		{
 			ID:         "o1",
 			AccountID:  "a1",
-			TotalPrice: 100.50,
+			TotalPrice: money.New(10050, "USD"),
 			Products: []OrderedProduct{
-				{ID: "p1", Name: "Product 1", Price: 50.25, Quantity: 2},
+				{ID: "p1", Name: "Product 1", Price: money.New(5025, "USD"), Quantity: 2},
 			},
 		},
 		{
 			ID:         "o2",
 			AccountID:  "a1",
-			TotalPrice: 75.00,
+			TotalPrice: money.New(7500, "USD"),
 			Products: []OrderedProduct{
-				{ID: "p2", Name: "Product 2", Price: 25.00, Quantity: 3},
+				{ID: "p2", Name: "Product 2", Price: money.New(2500, "USD"), Quantity: 3},
 			},
 		},
 	}
@@ -283,3 +304,302 @@ func TestUnitService_GetOrderForAccount_DifferentAccounts(t *testing.T) {
 		}
 	}
 }
+
+func TestUnitService_UpdateOrderStatus_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewOrderService(mockRepo)
+
+	mockRepo.EXPECT().GetOrderStatus(gomock.Any(), "o1").Return(StatusPaid, nil)
+	mockRepo.EXPECT().
+		UpdateOrderStatus(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, c StatusChange) error {
+			if c.OrderID != "o1" || c.From != StatusPaid || c.To != StatusShipped {
+				t.Errorf("unexpected status change %+v", c)
+			}
+			return nil
+		})
+
+	c, err := svc.UpdateOrderStatus(context.Background(), "o1", StatusShipped)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.From != StatusPaid || c.To != StatusShipped || c.ChangedAt.IsZero() {
+		t.Errorf("unexpected status change %+v", c)
+	}
+}
+
+func TestUnitService_UpdateOrderStatus_RejectsIllegalTransitions(t *testing.T) {
+	testCases := []struct {
+		from OrderStatus
+		to   OrderStatus
+	}{
+		{StatusPending, StatusShipped},
+		{StatusPending, StatusDelivered},
+		{StatusShipped, StatusPaid},
+		{StatusDelivered, StatusShipped},
+		{StatusCancelled, StatusPaid},
+		{StatusRefunded, StatusPending},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.from)+"->"+string(tc.to), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := NewMockRepository(ctrl)
+			svc := NewOrderService(mockRepo)
+
+			mockRepo.EXPECT().GetOrderStatus(gomock.Any(), "o1").Return(tc.from, nil)
+
+			_, err := svc.UpdateOrderStatus(context.Background(), "o1", tc.to)
+			if !errors.Is(err, ErrInvalidTransition) {
+				t.Fatalf("expected ErrInvalidTransition, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUnitService_UpdateOrderStatus_DoesNotCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewOrderService(mockRepo)
+
+	// Cancelling this way would skip returning the stock of the order.
+	mockRepo.EXPECT().UpdateOrderStatus(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := svc.UpdateOrderStatus(context.Background(), "o1", StatusCancelled)
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+}
+
+func TestUnitService_UpdateOrderStatus_UnknownStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := NewOrderService(NewMockRepository(ctrl))
+
+	_, err := svc.UpdateOrderStatus(context.Background(), "o1", OrderStatus("lost"))
+	if !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected ErrInvalidStatus, got %v", err)
+	}
+}
+
+func TestUnitService_CancelOrder(t *testing.T) {
+	testCases := []struct {
+		name    string
+		current OrderStatus
+		opts    []ServiceOption
+		wantErr error
+	}{
+		{name: "pending", current: StatusPending},
+		{name: "paid", current: StatusPaid},
+		{name: "shipped beyond default limit", current: StatusShipped, wantErr: ErrNotCancellable},
+		{name: "shipped with raised limit", current: StatusShipped, opts: []ServiceOption{WithCancellableUntil(StatusShipped)}},
+		{name: "pending only", current: StatusPaid, opts: []ServiceOption{WithCancellableUntil(StatusPending)}, wantErr: ErrNotCancellable},
+		{name: "refunded", current: StatusRefunded, wantErr: ErrNotCancellable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := NewMockRepository(ctrl)
+			svc := NewOrderService(mockRepo, tc.opts...)
+
+			mockRepo.EXPECT().GetOrderStatus(gomock.Any(), "o1").Return(tc.current, nil)
+			if tc.wantErr == nil {
+				mockRepo.EXPECT().UpdateOrderStatus(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			c, err := svc.CancelOrder(context.Background(), "o1")
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.From != tc.current || c.To != StatusCancelled {
+				t.Errorf("unexpected status change %+v", c)
+			}
+		})
+	}
+}
+
+func TestUnitService_CancelOrder_AlreadyCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewOrderService(mockRepo)
+
+	mockRepo.EXPECT().GetOrderStatus(gomock.Any(), "o1").Return(StatusCancelled, nil)
+	mockRepo.EXPECT().UpdateOrderStatus(gomock.Any(), gomock.Any()).Times(0)
+
+	c, err := svc.CancelOrder(context.Background(), "o1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.From != StatusCancelled || c.To != StatusCancelled {
+		t.Errorf("expected no-op status change, got %+v", c)
+	}
+}
+
+func TestUnitService_ClaimIdempotencyKey(t *testing.T) {
+	testCases := []struct {
+		name        string
+		key         string
+		stored      func(rec IdempotencyRecord) *IdempotencyRecord
+		wantClaim   bool
+		wantOrderID string
+		wantErr     error
+	}{
+		{
+			name:      "new key",
+			key:       "k1",
+			stored:    func(rec IdempotencyRecord) *IdempotencyRecord { return &rec },
+			wantClaim: true,
+		},
+		{
+			name: "order already placed",
+			key:  "k1",
+			stored: func(rec IdempotencyRecord) *IdempotencyRecord {
+				rec.Token, rec.OrderID = "other", "o1"
+				return &rec
+			},
+			wantOrderID: "o1",
+		},
+		{
+			name: "still in progress",
+			key:  "k1",
+			stored: func(rec IdempotencyRecord) *IdempotencyRecord {
+				rec.Token = "other"
+				return &rec
+			},
+			wantErr: ErrIdempotencyKeyInUse,
+		},
+		{
+			name: "different request",
+			key:  "k1",
+			stored: func(rec IdempotencyRecord) *IdempotencyRecord {
+				rec.Token, rec.Fingerprint, rec.OrderID = "other", "other", "o1"
+				return &rec
+			},
+			wantErr: ErrIdempotencyKeyReused,
+		},
+		{name: "key too long", key: strings.Repeat("k", 256), wantErr: ErrInvalidIdempotencyKey},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := NewMockRepository(ctrl)
+			svc := NewOrderService(mockRepo)
+
+			if tc.stored != nil {
+				mockRepo.EXPECT().ClaimIdempotencyKey(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, rec IdempotencyRecord) (*IdempotencyRecord, error) {
+						if rec.Key != tc.key || rec.Fingerprint != "fp" || rec.Token == "" {
+							t.Errorf("unexpected record %+v", rec)
+						}
+						return tc.stored(rec), nil
+					})
+			}
+
+			claim, orderID, err := svc.ClaimIdempotencyKey(context.Background(), tc.key, "fp")
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got %v", tc.wantErr, err)
+			}
+			if (claim != nil) != tc.wantClaim || orderID != tc.wantOrderID {
+				t.Errorf("expected claim %v and order %q, got %+v and %q", tc.wantClaim, tc.wantOrderID, claim, orderID)
+			}
+		})
+	}
+}
+
+func TestUnitService_ListOrders_Pages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewOrderService(mockRepo)
+
+	mockRepo.EXPECT().ListOrders(gomock.Any(), OrderFilter{}, "", 3).
+		Return([]Order{{ID: "o3"}, {ID: "o2"}, {ID: "o1"}}, nil)
+
+	page, err := svc.ListOrders(context.Background(), OrderFilter{}, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Orders) != 2 || !page.HasNextPage || page.EndCursor != Cursor("o2") {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+
+	mockRepo.EXPECT().ListOrders(gomock.Any(), OrderFilter{}, "o2", 3).
+		Return([]Order{{ID: "o1"}}, nil)
+
+	page, err = svc.ListOrders(context.Background(), OrderFilter{}, 2, page.EndCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Orders) != 1 || page.HasNextPage || page.EndCursor != Cursor("o1") {
+		t.Fatalf("unexpected last page %+v", page)
+	}
+}
+
+func TestUnitService_ListOrders_PageSize(t *testing.T) {
+	testCases := []struct {
+		first int
+		limit int
+	}{
+		{first: 0, limit: DefaultPageSize + 1},
+		{first: -5, limit: DefaultPageSize + 1},
+		{first: 10, limit: 11},
+		{first: 1000, limit: MaxPageSize + 1},
+	}
+
+	for _, tc := range testCases {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		svc := NewOrderService(mockRepo)
+
+		mockRepo.EXPECT().ListOrders(gomock.Any(), gomock.Any(), "", tc.limit).Return(nil, nil)
+
+		if _, err := svc.ListOrders(context.Background(), OrderFilter{}, tc.first, ""); err != nil {
+			t.Errorf("first=%d: %v", tc.first, err)
+		}
+		ctrl.Finish()
+	}
+}
+
+func TestUnitService_ListOrders_InvalidInput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewOrderService(mockRepo)
+	mockRepo.EXPECT().ListOrders(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	if _, err := svc.ListOrders(context.Background(), OrderFilter{}, 10, "!!"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+	if _, err := svc.ListOrders(context.Background(), OrderFilter{Statuses: []OrderStatus{"lost"}}, 10, ""); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("expected ErrInvalidStatus, got %v", err)
+	}
+	bad := money.New(100, "usd")
+	if _, err := svc.ListOrders(context.Background(), OrderFilter{MinTotal: &bad}, 10, ""); !errors.Is(err, money.ErrInvalidCurrency) {
+		t.Errorf("expected ErrInvalidCurrency, got %v", err)
+	}
+}
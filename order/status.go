@@ -0,0 +1,79 @@
+package order
+
+import (
+	"errors"
+	"time"
+)
+
+type OrderStatus string
+
+const (
+	StatusPending   OrderStatus = "pending"
+	StatusPaid      OrderStatus = "paid"
+	StatusShipped   OrderStatus = "shipped"
+	StatusDelivered OrderStatus = "delivered"
+	StatusCancelled OrderStatus = "cancelled"
+	StatusRefunded  OrderStatus = "refunded"
+)
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrInvalidStatus     = errors.New("invalid order status")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	// ErrStatusConflict means the order changed status between reading and updating it.
+	ErrStatusConflict = errors.New("order status changed concurrently")
+	ErrNotCancellable = errors.New("order can no longer be cancelled")
+)
+
+// transitions lists, for every status, the statuses an order may move to next.
+// Cancelled and refunded orders are final.
+var transitions = map[OrderStatus][]OrderStatus{
+	StatusPending:   {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusShipped, StatusCancelled, StatusRefunded},
+	StatusShipped:   {StatusDelivered, StatusCancelled},
+	StatusDelivered: {StatusRefunded},
+}
+
+// progress orders the statuses an order passes through on its way to the
+// customer. It is used to decide whether an order has gone too far to cancel.
+var progress = map[OrderStatus]int{
+	StatusPending:   0,
+	StatusPaid:      1,
+	StatusShipped:   2,
+	StatusDelivered: 3,
+}
+
+// StatusChange is a single entry of an order's status history.
+type StatusChange struct {
+	OrderID   string
+	From      OrderStatus
+	To        OrderStatus
+	ChangedAt time.Time
+}
+
+func (s OrderStatus) Valid() bool {
+	switch s {
+	case StatusPending, StatusPaid, StatusShipped, StatusDelivered, StatusCancelled, StatusRefunded:
+		return true
+	}
+	return false
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// NotPast reports whether an order in status s has not progressed beyond
+// limit. Final statuses are past every limit.
+func (s OrderStatus) NotPast(limit OrderStatus) bool {
+	p, ok := progress[s]
+	if !ok {
+		return false
+	}
+	return p <= progress[limit]
+}
@@ -0,0 +1,241 @@
+// Package resilience keeps the service clients working through brief
+// outages of the services they call: calls are bounded by a timeout,
+// idempotent ones are retried with backoff, and a circuit breaker fails
+// calls fast while a service keeps failing.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Policy is how a client times out, retries and stops calling a failing
+// service.
+type Policy struct {
+	// Timeout bounds each attempt of a call. Zero leaves only the deadline
+	// of the caller.
+	Timeout time.Duration
+	// MaxAttempts is how many times an idempotent call is tried in all.
+	// Other calls are only tried once.
+	MaxAttempts int
+	// InitialBackoff is the longest wait before the first retry. It is
+	// multiplied by Multiplier for each further retry, up to MaxBackoff,
+	// and the actual wait is picked at random below it.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// FailureThreshold is how many failed attempts in a row open the
+	// circuit. While open, calls fail right away with codes.Unavailable.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a call is let
+	// through to probe whether the service has recovered.
+	OpenTimeout time.Duration
+}
+
+// DefaultPolicy is the Policy of clients not given one.
+var DefaultPolicy = Policy{
+	Timeout:          5 * time.Second,
+	MaxAttempts:      3,
+	InitialBackoff:   100 * time.Millisecond,
+	MaxBackoff:       time.Second,
+	Multiplier:       2,
+	FailureThreshold: 5,
+	OpenTimeout:      10 * time.Second,
+}
+
+// ErrCircuitOpen is the cause of the calls failed by an open circuit.
+var ErrCircuitOpen = errors.New("circuit open: service is failing")
+
+type policyOption struct {
+	grpc.EmptyCallOption
+	policy Policy
+}
+
+// WithPolicy makes a call follow p.
+func WithPolicy(p Policy) grpc.CallOption {
+	return policyOption{policy: p}
+}
+
+// WithDefaultPolicy makes every call of a client follow p, unless given
+// another Policy with WithPolicy.
+func WithDefaultPolicy(p Policy) grpc.DialOption {
+	return grpc.WithDefaultCallOptions(WithPolicy(p))
+}
+
+type idempotentOption struct {
+	grpc.EmptyCallOption
+}
+
+// Idempotent marks a call as safe to repeat, such as a read. Only such
+// calls are retried.
+func Idempotent() grpc.CallOption {
+	return idempotentOption{}
+}
+
+// DialOption adds a circuit breaker, shared by all the calls of the
+// client, and applies the Policy of each call.
+func DialOption() grpc.DialOption {
+	return grpc.WithChainUnaryInterceptor(unaryClientInterceptor(&breaker{}))
+}
+
+// unaryClientInterceptor makes calls through b following their Policy.
+func unaryClientInterceptor(b *breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		policy, idempotent := DefaultPolicy, false
+		for _, opt := range opts {
+			switch o := opt.(type) {
+			case policyOption:
+				policy = o.policy
+			case idempotentOption:
+				idempotent = true
+			}
+		}
+		attempts := 1
+		if idempotent {
+			attempts = max(policy.MaxAttempts, 1)
+		}
+
+		var err error
+		for attempt := range attempts {
+			if attempt > 0 {
+				if !sleep(ctx, backoff(policy, attempt)) {
+					break
+				}
+			}
+			if !b.allow(policy) {
+				return status.Errorf(codes.Unavailable, "%s: %v", method, ErrCircuitOpen)
+			}
+			err = invoke(ctx, policy.Timeout, method, req, reply, cc, invoker, opts)
+			b.record(policy, method, failed(ctx, err))
+			if !retryable(ctx, err) {
+				return err
+			}
+		}
+		return err
+	}
+}
+
+func invoke(ctx context.Context, timeout time.Duration, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts []grpc.CallOption) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// failed reports whether err says the service is unhealthy, rather than
+// the call being wrong or cancelled by the caller.
+func failed(ctx context.Context, err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable:
+		return true
+	case codes.DeadlineExceeded:
+		// Only the attempt's own timeout, not the caller running out of time.
+		return ctx.Err() == nil
+	}
+	return false
+}
+
+// retryable reports whether an idempotent call failing with err can be
+// tried again.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// backoff returns how long to wait before retry number attempt, picked at
+// random below the exponential bound so that clients retry out of step.
+func backoff(p Policy, attempt int) time.Duration {
+	bound := float64(p.InitialBackoff)
+	for range attempt - 1 {
+		bound *= p.Multiplier
+	}
+	if p.MaxBackoff > 0 {
+		bound = min(bound, float64(p.MaxBackoff))
+	}
+	if bound < 1 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(bound)))
+}
+
+// sleep waits for d, returning false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// breaker counts the failed attempts in a row of a client. Once they
+// reach the threshold, the circuit opens for a while, then lets a single
+// probe through: its success closes the circuit, its failure opens it
+// again.
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (b *breaker) allow(p Policy) bool {
+	if p.FailureThreshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < p.FailureThreshold {
+		return true
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) record(p Policy, method string, failed bool) {
+	if p.FailureThreshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasOpen := b.failures >= p.FailureThreshold
+	b.probing = false
+	if !failed {
+		if wasOpen {
+			log.Printf("resilience: circuit closed after %s succeeded", method)
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= p.FailureThreshold {
+		if !wasOpen {
+			log.Printf("resilience: circuit opened after %d failures, last of %s", b.failures, method)
+		}
+		b.openUntil = time.Now().Add(p.OpenTimeout)
+	}
+}
@@ -0,0 +1,177 @@
+package resilience
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var testPolicy = Policy{
+	Timeout:          time.Second,
+	MaxAttempts:      3,
+	InitialBackoff:   time.Millisecond,
+	MaxBackoff:       time.Millisecond,
+	Multiplier:       2,
+	FailureThreshold: 3,
+	OpenTimeout:      50 * time.Millisecond,
+}
+
+// failingInvoker fails the first failures calls with err, then succeeds.
+type failingInvoker struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *failingInvoker) invoke(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func call(b *breaker, inv *failingInvoker, opts ...grpc.CallOption) error {
+	opts = append([]grpc.CallOption{WithPolicy(testPolicy)}, opts...)
+	return unaryClientInterceptor(b)(context.Background(), "/test/Method", nil, nil, nil, inv.invoke, opts...)
+}
+
+func TestInterceptor_RetriesIdempotentCalls(t *testing.T) {
+	inv := &failingInvoker{failures: 2, err: status.Error(codes.Unavailable, "down")}
+
+	if err := call(&breaker{}, inv, Idempotent()); err != nil {
+		t.Fatalf("expected the third attempt to succeed, got %v", err)
+	}
+	if inv.calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", inv.calls)
+	}
+}
+
+func TestInterceptor_GivesUpAfterMaxAttempts(t *testing.T) {
+	inv := &failingInvoker{failures: 10, err: status.Error(codes.Unavailable, "down")}
+
+	if err := call(&breaker{}, inv, Idempotent()); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected the last error, got %v", err)
+	}
+	if inv.calls != testPolicy.MaxAttempts {
+		t.Errorf("expected %d attempts, got %d", testPolicy.MaxAttempts, inv.calls)
+	}
+}
+
+func TestInterceptor_DoesNotRetryOtherCalls(t *testing.T) {
+	inv := &failingInvoker{failures: 1, err: status.Error(codes.Unavailable, "down")}
+	if err := call(&breaker{}, inv); status.Code(err) != codes.Unavailable || inv.calls != 1 {
+		t.Errorf("expected a single failed attempt, got %d: %v", inv.calls, err)
+	}
+
+	inv = &failingInvoker{failures: 1, err: status.Error(codes.NotFound, "missing")}
+	if err := call(&breaker{}, inv, Idempotent()); status.Code(err) != codes.NotFound || inv.calls != 1 {
+		t.Errorf("expected permanent errors not to be retried, got %d: %v", inv.calls, err)
+	}
+}
+
+func TestInterceptor_TimesOutAttempts(t *testing.T) {
+	var remaining time.Duration
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		if deadline, ok := ctx.Deadline(); ok {
+			remaining = time.Until(deadline)
+		}
+		return nil
+	}
+
+	if err := unaryClientInterceptor(&breaker{})(context.Background(), "/test/Method", nil, nil, nil, invoker, WithPolicy(testPolicy)); err != nil {
+		t.Fatal(err)
+	}
+	if remaining <= 0 || remaining > testPolicy.Timeout {
+		t.Errorf("expected a deadline within %s, got %s", testPolicy.Timeout, remaining)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	b := &breaker{}
+	down := &failingInvoker{failures: 100, err: status.Error(codes.Unavailable, "down")}
+
+	for range testPolicy.FailureThreshold {
+		call(b, down)
+	}
+	err := call(b, down)
+	if status.Code(err) != codes.Unavailable || !strings.Contains(err.Error(), ErrCircuitOpen.Error()) {
+		t.Fatalf("expected the open circuit to fail the call, got %v", err)
+	}
+	if down.calls != testPolicy.FailureThreshold {
+		t.Fatalf("expected an open circuit to fail fast, got %d calls", down.calls)
+	}
+
+	time.Sleep(testPolicy.OpenTimeout)
+	up := &failingInvoker{}
+	if err := call(b, up); err != nil || up.calls != 1 {
+		t.Fatalf("expected a probe to be let through, got %d calls: %v", up.calls, err)
+	}
+	if err := call(b, up); err != nil || up.calls != 2 {
+		t.Errorf("expected a successful probe to close the circuit, got %d calls: %v", up.calls, err)
+	}
+}
+
+func TestBreaker_IgnoresCallerErrors(t *testing.T) {
+	b := &breaker{}
+	inv := &failingInvoker{failures: 100, err: status.Error(codes.InvalidArgument, "bad")}
+
+	for range testPolicy.FailureThreshold + 1 {
+		call(b, inv)
+	}
+	if inv.calls != testPolicy.FailureThreshold+1 {
+		t.Errorf("expected errors of the caller to leave the circuit closed, got %d calls", inv.calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := Policy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2}
+	for attempt, bound := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 300 * time.Millisecond} {
+		for range 100 {
+			if d := backoff(p, attempt); d < 0 || d >= bound {
+				t.Fatalf("attempt %d: expected a wait below %s, got %s", attempt, bound, d)
+			}
+		}
+	}
+}
+
+func TestDialOption(t *testing.T) {
+	calls := 0
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		calls++
+		if calls == 1 {
+			return nil, status.Error(codes.Unavailable, "starting")
+		}
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(srv, grpchealth.NewServer())
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		DialOption(),
+		WithDefaultPolicy(testPolicy),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}, Idempotent())
+	if err != nil || calls != 2 {
+		t.Errorf("expected the call to be retried once, got %d calls: %v", calls, err)
+	}
+}
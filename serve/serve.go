@@ -0,0 +1,143 @@
+// Package serve runs the gRPC and HTTP servers of the binaries until their
+// context is done, then shuts them down without dropping calls in flight.
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// DefaultDrainTimeout is how long a server waits for calls in flight to
+// finish once asked to stop, before cutting them off.
+const DefaultDrainTimeout = 10 * time.Second
+
+// Config is how a server listens and shuts down.
+type Config struct {
+	Port int
+	// Listener, if set, is served instead of a TCP listener on Port.
+	Listener net.Listener
+	// ServerOptions are added to the options of the gRPC server.
+	ServerOptions []grpc.ServerOption
+	// DialOptions are added to the options of the clients the server uses
+	// to call other services.
+	DialOptions  []grpc.DialOption
+	DrainTimeout time.Duration
+}
+
+type Option func(*Config)
+
+func WithPort(port int) Option {
+	return func(c *Config) {
+		c.Port = port
+	}
+}
+
+func WithListener(lis net.Listener) Option {
+	return func(c *Config) {
+		c.Listener = lis
+	}
+}
+
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(c *Config) {
+		c.ServerOptions = append(c.ServerOptions, opts...)
+	}
+}
+
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *Config) {
+		c.DialOptions = append(c.DialOptions, opts...)
+	}
+}
+
+func WithDrainTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.DrainTimeout = d
+	}
+}
+
+// NewConfig applies opts to a Config listening on defaultPort.
+func NewConfig(defaultPort int, opts ...Option) *Config {
+	c := &Config{
+		Port:         defaultPort,
+		DrainTimeout: DefaultDrainTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Listen returns c.Listener, or a new TCP listener on c.Port.
+func (c *Config) Listen() (net.Listener, error) {
+	if c.Listener != nil {
+		return c.Listener, nil
+	}
+	return net.Listen("tcp", fmt.Sprintf(":%d", c.Port))
+}
+
+// GRPC serves srv on lis until ctx is done. It then stops accepting calls
+// and waits up to drainTimeout for the ones in flight before closing them.
+func GRPC(ctx context.Context, srv *grpc.Server, lis net.Listener, drainTimeout time.Duration) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(lis)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("shutting down, draining calls for up to %s", drainTimeout)
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(drainTimeout)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+	case <-timer.C:
+		log.Println("drain timeout exceeded, closing remaining calls")
+		srv.Stop()
+		<-stopped
+	}
+	return <-errc
+}
+
+// HTTP is GRPC for an HTTP server.
+func HTTP(ctx context.Context, srv *http.Server, lis net.Listener, drainTimeout time.Duration) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(lis)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("shutting down, draining requests for up to %s", drainTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("drain timeout exceeded, closing remaining requests")
+		srv.Close()
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
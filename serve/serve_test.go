@@ -0,0 +1,160 @@
+package serve
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startBlockingServer serves a health service whose calls wait for release
+// to be closed.
+func startBlockingServer(t *testing.T, ctx context.Context, drainTimeout time.Duration, release <-chan struct{}) (healthpb.HealthClient, <-chan struct{}, <-chan error) {
+	t.Helper()
+	started := make(chan struct{}, 1)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		started <- struct{}{}
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(srv, grpchealth.NewServer())
+
+	lis := bufconn.Listen(1024 * 1024)
+	done := make(chan error, 1)
+	go func() {
+		done <- GRPC(ctx, srv, lis, drainTimeout)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn), started, done
+}
+
+func TestGRPC_DrainsCallsInFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	client, started, done := startBlockingServer(t, ctx, time.Second, release)
+
+	callErr := make(chan error, 1)
+	go func() {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		callErr <- err
+	}()
+	<-started
+
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if err := <-callErr; err != nil {
+		t.Errorf("expected the call in flight to complete, got %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestGRPC_DrainTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	client, started, done := startBlockingServer(t, ctx, 50*time.Millisecond, release)
+
+	callErr := make(chan error, 1)
+	go func() {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		callErr <- err
+	}()
+	<-started
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop after the drain timeout")
+	}
+	if err := <-callErr; status.Code(err) != codes.Unavailable {
+		t.Errorf("expected the call in flight to be cut off, got %v", err)
+	}
+}
+
+func TestHTTP_DrainsRequestsInFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	})}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- HTTP(ctx, srv, lis, time.Second)
+	}()
+
+	resc := make(chan *http.Response, 1)
+	go func() {
+		res, err := http.Get("http://" + lis.Addr().String())
+		if err != nil {
+			t.Error(err)
+		}
+		resc <- res
+	}()
+	<-started
+
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if res := <-resc; res == nil || res.StatusCode != http.StatusNoContent {
+		t.Errorf("expected the request in flight to complete, got %v", res)
+	} else {
+		res.Body.Close()
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	c := NewConfig(8081)
+	if c.Port != 8081 || c.DrainTimeout != DefaultDrainTimeout {
+		t.Errorf("unexpected defaults %+v", c)
+	}
+
+	lis := bufconn.Listen(1)
+	c = NewConfig(8081, WithPort(9000), WithListener(lis), WithDrainTimeout(time.Second))
+	if c.Port != 9000 || c.DrainTimeout != time.Second {
+		t.Errorf("options not applied: %+v", c)
+	}
+	got, err := c.Listen()
+	if err != nil || got != net.Listener(lis) {
+		t.Errorf("expected the given listener, got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,63 @@
+package testkit
+
+import (
+	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/account"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+)
+
+// CreateAccount adds a customer account called name.
+func (c *Cluster) CreateAccount(t testing.TB, name string) *account.Account {
+	t.Helper()
+	if c.Account == nil {
+		t.Fatal("testkit: the account service isn't running")
+	}
+	a, err := c.Account.PostAccount(c.Admin(t), name)
+	if err != nil {
+		t.Fatalf("creating account %q: %v", name, err)
+	}
+	return a
+}
+
+// CreateProduct adds a product to the catalog with stock units in the
+// inventory.
+func (c *Cluster) CreateProduct(t testing.TB, name string, price money.Money, stock int32) *catalog.Product {
+	t.Helper()
+	if c.Catalog == nil {
+		t.Fatal("testkit: the catalog service isn't running")
+	}
+	p, err := c.Catalog.PostProduct(c.Admin(t), name, name+" for tests", price, nil)
+	if err != nil {
+		t.Fatalf("creating product %q: %v", name, err)
+	}
+	if stock != 0 {
+		c.AddStock(t, p.ID, stock)
+	}
+	return p
+}
+
+// AddStock changes the stock of productID by delta units.
+func (c *Cluster) AddStock(t testing.TB, productID string, delta int32) {
+	t.Helper()
+	if c.Inventory == nil {
+		t.Fatal("testkit: the inventory service isn't running")
+	}
+	if _, err := c.Inventory.UpdateStock(c.Admin(t), []string{productID}, []int32{delta}, ""); err != nil {
+		t.Fatalf("updating the stock of %s: %v", productID, err)
+	}
+}
+
+// Stock returns the units of productID in stock.
+func (c *Cluster) Stock(t testing.TB, productID string) int32 {
+	t.Helper()
+	if c.Inventory == nil {
+		t.Fatal("testkit: the inventory service isn't running")
+	}
+	stock, err := c.Inventory.CheckStock(c.Admin(t), []string{productID})
+	if err != nil {
+		t.Fatalf("checking the stock of %s: %v", productID, err)
+	}
+	return stock[0]
+}
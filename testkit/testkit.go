@@ -0,0 +1,275 @@
+// Package testkit starts services in-process for tests, through their own
+// ListenGRPC and clients, on in-memory repositories unless given others.
+// Every cluster has its own listeners and stores, so tests using one can
+// run in parallel.
+//
+// A cross-service test takes a few lines:
+//
+//	c := testkit.Start(t, testkit.Services(testkit.Order))
+//	a := c.CreateAccount(t, "viraj")
+//	p := c.CreateProduct(t, "Mug", money.New(500, "USD"), 1)
+//	_, err := c.Order.PostOrder(c.As(t, a.ID, auth.RoleCustomer), a.ID,
+//		[]order.OrderedProduct{{ID: p.ID, Quantity: 2}}, "")
+package testkit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/account"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/inventory"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/order"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/serve"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Service names a service a cluster can run.
+type Service string
+
+const (
+	Account   Service = "account"
+	Catalog   Service = "catalog"
+	Inventory Service = "inventory"
+	Order     Service = "order"
+)
+
+// dependencies are the services each one calls, which are started along
+// with it.
+var dependencies = map[Service][]Service{
+	Catalog: {Inventory},
+	Order:   {Account, Catalog, Inventory},
+}
+
+// secret signs the access tokens of every cluster.
+var secret = bytes.Repeat([]byte("testkit!"), 4)
+
+type config struct {
+	services     map[Service]bool
+	tcp          bool
+	account      account.Repository
+	catalog      catalog.Repository
+	inventory    inventory.Repository
+	order        order.Repository
+	drainTimeout time.Duration
+}
+
+type Option func(*config)
+
+// Services starts only s and the services they depend on, instead of
+// every service.
+func Services(s ...Service) Option {
+	return func(c *config) {
+		c.services = map[Service]bool{}
+		var add func(s Service)
+		add = func(s Service) {
+			c.services[s] = true
+			for _, dep := range dependencies[s] {
+				add(dep)
+			}
+		}
+		for _, s := range s {
+			add(s)
+		}
+	}
+}
+
+// TCP serves on random localhost ports instead of in-memory listeners, for
+// tests that dial the services themselves.
+func TCP() Option {
+	return func(c *config) {
+		c.tcp = true
+	}
+}
+
+// The With...Repository options replace the in-memory repository of a
+// service, e.g. with one backed by a real database. The cluster closes it
+// when the test ends.
+
+func WithAccountRepository(r account.Repository) Option {
+	return func(c *config) {
+		c.account = r
+	}
+}
+
+func WithCatalogRepository(r catalog.Repository) Option {
+	return func(c *config) {
+		c.catalog = r
+	}
+}
+
+func WithInventoryRepository(r inventory.Repository) Option {
+	return func(c *config) {
+		c.inventory = r
+	}
+}
+
+func WithOrderRepository(r order.Repository) Option {
+	return func(c *config) {
+		c.order = r
+	}
+}
+
+// Cluster is a set of running services and clients of each. The clients of
+// services not started are nil.
+type Cluster struct {
+	Tokens    *auth.Signer
+	Account   *account.Client
+	Catalog   *catalog.Client
+	Inventory *inventory.Client
+	Order     *order.Client
+
+	addrs map[Service]string
+	dial  []grpc.DialOption
+}
+
+// Start runs the services until the test ends. Every service is started
+// unless opts include Services.
+func Start(t testing.TB, opts ...Option) *Cluster {
+	t.Helper()
+	cfg := &config{drainTimeout: time.Second}
+	Services(Account, Catalog, Inventory, Order)(cfg)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	tokens, err := auth.NewSigner(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Cluster{Tokens: tokens, addrs: map[Service]string{}}
+
+	listeners := map[Service]net.Listener{}
+	buffers := map[string]*bufconn.Listener{}
+	for s := range cfg.services {
+		if cfg.tcp {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			listeners[s], c.addrs[s] = lis, lis.Addr().String()
+			continue
+		}
+		lis := bufconn.Listen(1024 * 1024)
+		listeners[s], buffers[string(s)], c.addrs[s] = lis, lis, "passthrough:///"+string(s)
+	}
+	if !cfg.tcp {
+		c.dial = []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			lis, ok := buffers[addr]
+			if !ok {
+				return nil, fmt.Errorf("testkit: %s is not running", addr)
+			}
+			return lis.DialContext(ctx)
+		})}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	run := func(s Service, listen func(opts ...serve.Option) error, close func()) {
+		wg.Go(func() {
+			defer close()
+			err := listen(serve.WithListener(listeners[s]), serve.WithDialOptions(c.dial...), serve.WithDrainTimeout(cfg.drainTimeout))
+			if err != nil {
+				t.Errorf("testkit: %s: %v", s, err)
+			}
+		})
+	}
+
+	if cfg.services[Account] {
+		r := cfg.account
+		if r == nil {
+			r = account.NewMemoryRepository()
+		}
+		s := account.NewService(r, account.WithSigner(tokens))
+		run(Account, func(opts ...serve.Option) error {
+			return account.ListenGRPC(ctx, s, tokens, opts...)
+		}, r.Close)
+		c.Account = dial(t, account.NewClient, c.Addr(Account), c.DialOptions())
+	}
+	if cfg.services[Catalog] {
+		r := cfg.catalog
+		if r == nil {
+			r = catalog.NewMemoryRepository()
+		}
+		s := catalog.NewSerivce(r)
+		run(Catalog, func(opts ...serve.Option) error {
+			return catalog.ListenGRPC(ctx, s, tokens, c.Addr(Inventory), opts...)
+		}, r.Close)
+		c.Catalog = dial(t, catalog.NewClient, c.Addr(Catalog), c.DialOptions())
+	}
+	if cfg.services[Inventory] {
+		r := cfg.inventory
+		if r == nil {
+			r = inventory.NewMemoryRepository()
+		}
+		s := inventory.NewService(r)
+		run(Inventory, func(opts ...serve.Option) error {
+			return inventory.ListenGRPC(ctx, s, tokens, opts...)
+		}, r.Close)
+		c.Inventory = dial(t, inventory.NewClient, c.Addr(Inventory), c.DialOptions())
+	}
+	if cfg.services[Order] {
+		r := cfg.order
+		if r == nil {
+			r = order.NewMemoryRepository()
+		}
+		s := order.NewOrderService(r)
+		run(Order, func(opts ...serve.Option) error {
+			return order.ListenGRPC(ctx, s, tokens, r, c.Addr(Account), c.Addr(Catalog), c.Addr(Inventory), opts...)
+		}, r.Close)
+		c.Order = dial(t, order.NewClient, c.Addr(Order), c.DialOptions())
+	}
+	return c
+}
+
+// dial creates a client with newClient, closed when the test ends.
+func dial[C interface{ Close() }](t testing.TB, newClient func(string, ...grpc.DialOption) (C, error), target string, opts []grpc.DialOption) C {
+	t.Helper()
+	client, err := newClient(target, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// Addr returns the address s is served on, for dialling it with
+// DialOptions. It is empty if s isn't running.
+func (c *Cluster) Addr(s Service) string {
+	return c.addrs[s]
+}
+
+// DialOptions are needed to reach the services of c, on top of the default
+// options of the clients. Calls carry the token stored in their context
+// by As or Admin.
+func (c *Cluster) DialOptions() []grpc.DialOption {
+	return append([]grpc.DialOption{grpc.WithPerRPCCredentials(auth.ForwardCredentials())}, c.dial...)
+}
+
+// As returns a context for calls made by accountID with role, ending with
+// the test.
+func (c *Cluster) As(t testing.TB, accountID string, role auth.Role) context.Context {
+	t.Helper()
+	tokens, err := c.Tokens.Issue(accountID, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth.NewTokenContext(t.Context(), tokens.AccessToken)
+}
+
+// Admin returns a context for calls allowed to do anything.
+func (c *Cluster) Admin(t testing.TB) context.Context {
+	t.Helper()
+	return c.As(t, "testkit", auth.RoleAdmin)
+}
@@ -0,0 +1,95 @@
+package testkit_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/RathodViraj/go-microservice-graphql-grpc/auth"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/catalog"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/errcode"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/money"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/order"
+	"github.com/RathodViraj/go-microservice-graphql-grpc/testkit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOrderFailsWhenStockRunsOut(t *testing.T) {
+	t.Parallel()
+	c := testkit.Start(t, testkit.Services(testkit.Order))
+	a := c.CreateAccount(t, "viraj")
+	p := c.CreateProduct(t, "Mug", money.New(500, "USD"), 1)
+	ctx := c.As(t, a.ID, auth.RoleCustomer)
+
+	_, err := c.Order.PostOrder(ctx, a.ID, []order.OrderedProduct{{ID: p.ID, Quantity: 2}}, "")
+	if ids, ok := errcode.OutOfStockProducts(err); !ok || !slices.Equal(ids, []string{p.ID}) {
+		t.Fatalf("expected %s to be out of stock, got %v", p.ID, err)
+	}
+
+	if _, err := c.Order.PostOrder(ctx, a.ID, []order.OrderedProduct{{ID: p.ID, Quantity: 1}}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if stock := c.Stock(t, p.ID); stock != 0 {
+		t.Errorf("expected the order to take the last unit, got %d left", stock)
+	}
+}
+
+func TestDeletedProductsCannotBeOrdered(t *testing.T) {
+	t.Parallel()
+	c := testkit.Start(t, testkit.Services(testkit.Order))
+	a := c.CreateAccount(t, "viraj")
+	p := c.CreateProduct(t, "Mug", money.New(500, "USD"), 5)
+
+	name := "Big mug"
+	updated, err := c.Catalog.UpdateProduct(c.Admin(t), p.ID, p.Version, catalog.ProductUpdate{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Catalog.DeleteProduct(c.Admin(t), p.ID, p.Version); status.Code(err) != codes.Aborted {
+		t.Fatalf("expected deleting a stale version to be aborted, got %v", err)
+	}
+	if _, err := c.Catalog.DeleteProduct(c.Admin(t), p.ID, updated.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Order.PostOrder(c.As(t, a.ID, auth.RoleCustomer), a.ID, []order.OrderedProduct{{ID: p.ID, Quantity: 1}}, "")
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected the deleted product not to be found, got %v", err)
+	}
+	if stock := c.Stock(t, p.ID); stock != 5 {
+		t.Errorf("expected the stock to be given back, got %d", stock)
+	}
+}
+
+func TestStart_ServesASubsetOverTCP(t *testing.T) {
+	t.Parallel()
+	c := testkit.Start(t, testkit.Services(testkit.Inventory), testkit.TCP())
+	if c.Account != nil || c.Catalog != nil || c.Order != nil {
+		t.Fatal("expected only the inventory service to run")
+	}
+	if c.Addr(testkit.Inventory) == "" || c.Addr(testkit.Account) != "" {
+		t.Fatalf("unexpected addresses: inventory %q, account %q", c.Addr(testkit.Inventory), c.Addr(testkit.Account))
+	}
+
+	c.AddStock(t, "p1", 3)
+	if stock := c.Stock(t, "p1"); stock != 3 {
+		t.Errorf("expected 3 units, got %d", stock)
+	}
+}
+
+func TestStart_StartsDependencies(t *testing.T) {
+	t.Parallel()
+	c := testkit.Start(t, testkit.Services(testkit.Catalog))
+	if c.Inventory == nil || c.Order != nil {
+		t.Fatal("expected the catalog to run with the inventory only")
+	}
+
+	p := c.CreateProduct(t, "Lamp", money.New(2500, "USD"), 4)
+	got, err := c.Catalog.GetProduct(c.Admin(t), p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Quantity != 4 {
+		t.Errorf("expected the catalog to report the stock of 4, got %d", got.Quantity)
+	}
+}
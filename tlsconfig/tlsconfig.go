@@ -0,0 +1,246 @@
+// Package tlsconfig secures the connections between the gateway and the
+// services with TLS or, given a CA bundle, mutual TLS. Certificates are
+// reloaded from disk when they change, without restarting.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultReloadInterval is how often Run looks for changed files.
+const DefaultReloadInterval = 30 * time.Second
+
+var ErrNoCertificates = errors.New("no certificates found in CA bundle")
+
+// Files are the PEM files a Reloader loads. CertFile and KeyFile are the
+// certificate presented to the other side, CAFile the bundle its
+// certificate is verified against. When CAFile is set, servers require
+// clients to present a certificate.
+type Files struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// Enabled reports whether any file is set, i.e. whether TLS is wanted.
+func (f Files) Enabled() bool {
+	return f.CertFile != "" || f.KeyFile != "" || f.CAFile != ""
+}
+
+// Reloader holds the certificates loaded from Files and replaces them when
+// the files change.
+type Reloader struct {
+	files Files
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+	modTimes []time.Time
+}
+
+// NewReloader loads files, failing if any of them can't be used.
+func NewReloader(files Files) (*Reloader, error) {
+	if (files.CertFile == "") != (files.KeyFile == "") {
+		return nil, errors.New("TLS certificate and key must be set together")
+	}
+	r := &Reloader{files: files}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the files again. On failure the previous certificates are
+// kept.
+func (r *Reloader) Reload() error {
+	var cert *tls.Certificate
+	if r.files.CertFile != "" {
+		c, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+		if err != nil {
+			return fmt.Errorf("loading TLS certificate: %w", err)
+		}
+		cert = &c
+	}
+
+	var pool *x509.CertPool
+	if r.files.CAFile != "" {
+		pem, err := os.ReadFile(r.files.CAFile)
+		if err != nil {
+			return fmt.Errorf("loading CA bundle: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("loading CA bundle %s: %w", r.files.CAFile, ErrNoCertificates)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.pool, r.modTimes = cert, pool, r.stat()
+	return nil
+}
+
+func (r *Reloader) stat() []time.Time {
+	var times []time.Time
+	for _, name := range []string{r.files.CertFile, r.files.KeyFile, r.files.CAFile} {
+		var t time.Time
+		if fi, err := os.Stat(name); err == nil {
+			t = fi.ModTime()
+		}
+		times = append(times, t)
+	}
+	return times
+}
+
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, r.pool
+}
+
+// Run reloads the files every interval if they changed, until ctx is done.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.RLock()
+		changed := !slices.Equal(r.modTimes, r.stat())
+		r.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		if err := r.Reload(); err != nil {
+			log.Println("tls: keeping the previous certificates:", err)
+			continue
+		}
+		log.Println("tls: certificates reloaded")
+	}
+}
+
+// ServerConfig presents the current certificate and, with a CA bundle,
+// requires and verifies a client certificate.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			if cert == nil {
+				return nil, errors.New("no server certificate configured")
+			}
+			c := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				NextProtos:   []string{"h2"},
+			}
+			if pool != nil {
+				c.ClientCAs = pool
+				c.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return c, nil
+		},
+	}
+}
+
+// ClientConfig verifies servers against the current CA bundle, or the
+// system roots without one, and presents the current certificate if any.
+func (r *Reloader) ClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		},
+		// RootCAs can't be swapped once the config is in use, so the
+		// standard verification is replaced by VerifyConnection, which
+		// uses the CA bundle loaded last.
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("server presented no certificate")
+			}
+			_, pool := r.current()
+			opts := x509.VerifyOptions{
+				DNSName:       cs.ServerName,
+				Roots:         pool,
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, c := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(c)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		},
+	}
+}
+
+// ServerOption serves over TLS with r.
+func (r *Reloader) ServerOption() grpc.ServerOption {
+	return grpc.Creds(credentials.NewTLS(r.ServerConfig()))
+}
+
+// DialOption connects over TLS with r.
+func (r *Reloader) DialOption() grpc.DialOption {
+	return grpc.WithTransportCredentials(credentials.NewTLS(r.ClientConfig()))
+}
+
+// PeerNames returns the names the verified client certificate of the
+// call in ctx was issued to: its common name and DNS names.
+func PeerNames(ctx context.Context) ([]string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	leaf := info.State.VerifiedChains[0][0]
+	names := append([]string{leaf.Subject.CommonName}, leaf.DNSNames...)
+	return names, true
+}
+
+// UnaryPeerInterceptor only lets the peers named in rules, keyed by full
+// method name, call those methods. Other methods are open to every peer.
+func UnaryPeerInterceptor(rules map[string][]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		allowed, restricted := rules[info.FullMethod]
+		if !restricted {
+			return handler(ctx, req)
+		}
+		names, ok := PeerNames(ctx)
+		if !ok {
+			return nil, status.Errorf(codes.PermissionDenied, "%s requires a client certificate", info.FullMethod)
+		}
+		for _, name := range names {
+			if slices.Contains(allowed, name) {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Errorf(codes.PermissionDenied, "%s can't be called by %s", info.FullMethod, names[0])
+	}
+}
@@ -0,0 +1,263 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const checkMethod = "/grpc.health.v1.Health/Check"
+
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newAuthority(t *testing.T) *authority {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &authority{cert, key}
+}
+
+// issue writes a certificate for name, valid for servers and clients, and
+// the CA bundle into dir, returning their paths.
+func (a *authority) issue(t *testing.T, dir, name string, serial int64) Files {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"bufnet"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := Files{
+		CertFile: filepath.Join(dir, "tls.crt"),
+		KeyFile:  filepath.Join(dir, "tls.key"),
+		CAFile:   filepath.Join(dir, "ca.crt"),
+	}
+	writePEM(t, files.CertFile, "CERTIFICATE", der)
+	writePEM(t, files.KeyFile, "EC PRIVATE KEY", keyDER)
+	writePEM(t, files.CAFile, "CERTIFICATE", a.cert.Raw)
+	return files
+}
+
+func writePEM(t *testing.T, name, kind string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newReloader(t *testing.T, files Files) *Reloader {
+	t.Helper()
+	r, err := NewReloader(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// startServer serves a health service over TLS with certs, letting only
+// the peers in checkers call Check.
+func startServer(t *testing.T, certs *Reloader, checkers ...string) *bufconn.Listener {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(
+		certs.ServerOption(),
+		grpc.UnaryInterceptor(UnaryPeerInterceptor(map[string][]string{checkMethod: checkers})),
+	)
+	healthpb.RegisterHealthServer(srv, grpchealth.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis
+}
+
+// check calls Check on lis, dialed with opt, returning the certificate
+// the server presented.
+func check(t *testing.T, lis *bufconn.Listener, opt grpc.DialOption) (*x509.Certificate, error) {
+	t.Helper()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		opt,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var p peer.Peer
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Peer(&p))
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) != 0 {
+		return info.State.PeerCertificates[0], err
+	}
+	return nil, err
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newAuthority(t)
+	server := newReloader(t, ca.issue(t, t.TempDir(), "inventory", 2))
+	lis := startServer(t, server, "graphql", "order")
+
+	if _, err := check(t, lis, newReloader(t, ca.issue(t, t.TempDir(), "order", 3)).DialOption()); err != nil {
+		t.Errorf("expected an allowed peer to be served, got %v", err)
+	}
+
+	_, err := check(t, lis, newReloader(t, ca.issue(t, t.TempDir(), "catalog", 4)).DialOption())
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for another peer, got %v", err)
+	}
+
+	caOnly := newReloader(t, Files{CAFile: ca.issue(t, t.TempDir(), "anonymous", 5).CAFile})
+	if _, err := check(t, lis, caOnly.DialOption()); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected a client without a certificate to be rejected, got %v", err)
+	}
+
+	other := newAuthority(t)
+	if _, err := check(t, lis, newReloader(t, other.issue(t, t.TempDir(), "order", 6)).DialOption()); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected certificates from another CA to be rejected, got %v", err)
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	ca := newAuthority(t)
+	dir := t.TempDir()
+	server := newReloader(t, ca.issue(t, dir, "inventory", 10))
+	lis := startServer(t, server, "order")
+	client := newReloader(t, ca.issue(t, t.TempDir(), "order", 11)).DialOption()
+
+	ca.issue(t, dir, "inventory", 12)
+	if err := server.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := check(t, lis, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.SerialNumber.Int64() != 12 {
+		t.Errorf("expected the reloaded certificate, got serial %v", cert.SerialNumber)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "tls.crt"), []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Reload(); err == nil {
+		t.Error("expected an error reloading an invalid certificate")
+	}
+	if cert, err := check(t, lis, client); err != nil || cert.SerialNumber.Int64() != 12 {
+		t.Errorf("expected the previous certificate to be kept, got %v, %v", cert, err)
+	}
+}
+
+func TestReloader_Run(t *testing.T) {
+	ca := newAuthority(t)
+	dir := t.TempDir()
+	server := newReloader(t, ca.issue(t, dir, "inventory", 20))
+	lis := startServer(t, server, "order")
+	client := newReloader(t, ca.issue(t, t.TempDir(), "order", 21)).DialOption()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx, 10*time.Millisecond)
+
+	ca.issue(t, dir, "inventory", 22)
+	// Make sure the change is seen on file systems with coarse mtimes.
+	later := time.Now().Add(time.Minute)
+	for _, name := range []string{"tls.crt", "tls.key"} {
+		if err := os.Chtimes(filepath.Join(dir, name), later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		cert, err := check(t, lis, client)
+		if err == nil && cert.SerialNumber.Int64() == 22 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the changed certificate to be served, got %v, %v", cert, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNewReloader_Invalid(t *testing.T) {
+	files := newAuthority(t).issue(t, t.TempDir(), "inventory", 30)
+
+	if _, err := NewReloader(Files{CertFile: files.CertFile}); err == nil {
+		t.Error("expected an error for a certificate without a key")
+	}
+	if _, err := NewReloader(Files{CAFile: files.KeyFile}); err == nil {
+		t.Error("expected an error for a CA bundle without certificates")
+	}
+}
+
+func TestUnaryPeerInterceptor_Plaintext(t *testing.T) {
+	interceptor := UnaryPeerInterceptor(map[string][]string{checkMethod: {"order"}})
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: checkMethod}, handler)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied without a client certificate, got %v", err)
+	}
+	res, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Watch"}, handler)
+	if res != "ok" || err != nil {
+		t.Errorf("expected other methods to be served, got %v, %v", res, err)
+	}
+}
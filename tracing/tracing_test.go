@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	for _, exporter := range []string{"", ExporterNone, ExporterStdout} {
+		shutdown, err := Setup(context.Background(), "test", exporter)
+		if err != nil {
+			t.Fatalf("%q: %v", exporter, err)
+		}
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("%q: shutdown failed: %v", exporter, err)
+		}
+	}
+
+	if _, err := Setup(context.Background(), "test", "zipkin"); !errors.Is(err, ErrUnknownExporter) {
+		t.Errorf("expected ErrUnknownExporter, got %v", err)
+	}
+}
+
+func TestNewInMemory(t *testing.T) {
+	exp := NewInMemory()
+
+	_, span := otel.Tracer("test").Start(context.Background(), "work")
+	span.End()
+
+	spans := exp.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "work" {
+		t.Errorf("expected the span to be recorded, got %v", spans)
+	}
+}